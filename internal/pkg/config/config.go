@@ -5,27 +5,36 @@ import (
 	"fmt"
 	"github.com/hthinh24/go-store/internal/pkg/config/app"
 	"github.com/hthinh24/go-store/internal/pkg/config/db"
+	"github.com/hthinh24/go-store/internal/pkg/config/health"
 	"github.com/hthinh24/go-store/internal/pkg/config/http"
 	"github.com/hthinh24/go-store/internal/pkg/config/jwt"
 	customLog "github.com/hthinh24/go-store/internal/pkg/config/log"
+	"github.com/hthinh24/go-store/internal/pkg/config/mail"
 	"github.com/hthinh24/go-store/internal/pkg/config/redis"
+	"github.com/hthinh24/go-store/internal/pkg/config/serviceauth"
+	"github.com/hthinh24/go-store/internal/pkg/config/tracing"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Environment string        `mapstructure:"environment" env:"ENV"`
-	ServiceName string        `mapstructure:"service_name" env:"SERVICE_NAME"`
-	PG          db.PG         `mapstructure:"pg"`
-	App         app.App       `mapstructure:"app"`
-	HTTP        http.HTTP     `mapstructure:"http"`
-	JWT         jwt.JWT       `mapstructure:"jwt"`
-	Redis       redis.Redis   `mapstructure:"redis"`
-	Log         customLog.Log `mapstructure:"log"`
-	Services    Services      `mapstructure:"services"`
+	Environment string                  `mapstructure:"environment" env:"ENV"`
+	ServiceName string                  `mapstructure:"service_name" env:"SERVICE_NAME"`
+	PG          db.PG                   `mapstructure:"pg"`
+	App         app.App                 `mapstructure:"app"`
+	HTTP        http.HTTP               `mapstructure:"http"`
+	JWT         jwt.JWT                 `mapstructure:"jwt"`
+	Redis       redis.Redis             `mapstructure:"redis"`
+	Log         customLog.Log           `mapstructure:"log"`
+	Tracing     tracing.Tracing         `mapstructure:"tracing"`
+	Health      health.Health           `mapstructure:"health"`
+	Mail        mail.Mail               `mapstructure:"mail"`
+	Services    Services                `mapstructure:"services"`
+	ServiceAuth serviceauth.ServiceAuth `mapstructure:"service_auth"`
 }
 
 // LoadConfig loads configuration from file and environment variables
@@ -44,6 +53,7 @@ func LoadConfig(configPath string) (*Config, error) {
 
 	// Enable environment variable support
 	viper.AutomaticEnv()
+	bindServiceAuthEnvVars()
 
 	// Read config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -61,6 +71,10 @@ func LoadConfig(configPath string) (*Config, error) {
 	// Set defaults
 	config.setDefaults()
 
+	if err := config.checkServiceAuthKeys(); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
@@ -91,6 +105,10 @@ func LoadConfigFromEnv(envFile string) (*Config, error) {
 	// Set defaults
 	config.setDefaults()
 
+	if err := config.checkServiceAuthKeys(); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
@@ -124,6 +142,10 @@ func bindEnvVars() {
 	// JWT
 	viper.BindEnv("jwt.secret", "JWT_SECRET")
 	viper.BindEnv("jwt.expiration", "JWT_EXPIRATION")
+	viper.BindEnv("jwt.refresh_expiration", "JWT_REFRESH_EXPIRATION")
+	viper.BindEnv("jwt.signing_key.id", "JWT_SIGNING_KEY_ID")
+	viper.BindEnv("jwt.signing_key.path", "JWT_SIGNING_KEY_PATH")
+	viper.BindEnv("jwt.rotation_grace_period", "JWT_ROTATION_GRACE_PERIOD")
 
 	// Redis
 	viper.BindEnv("redis.host", "REDIS_HOST")
@@ -140,18 +162,60 @@ func bindEnvVars() {
 	// Log
 	viper.BindEnv("log.level", "LOG_LEVEL")
 
+	// Tracing
+	viper.BindEnv("tracing.enabled", "TRACING_ENABLED")
+	viper.BindEnv("tracing.exporter", "TRACING_EXPORTER")
+	viper.BindEnv("tracing.file_path", "TRACING_FILE_PATH")
+	viper.BindEnv("tracing.sample_ratio", "TRACING_SAMPLE_RATIO")
+
+	// Health
+	viper.BindEnv("health.timeout", "HEALTH_TIMEOUT")
+	viper.BindEnv("health.dependencies", "HEALTH_DEPENDENCIES")
+
+	// Mail
+	viper.BindEnv("mail.driver", "MAIL_DRIVER")
+	viper.BindEnv("mail.from", "MAIL_FROM")
+	viper.BindEnv("mail.file_path", "MAIL_FILE_PATH")
+	viper.BindEnv("mail.smtp.host", "SMTP_HOST")
+	viper.BindEnv("mail.smtp.port", "SMTP_PORT")
+	viper.BindEnv("mail.smtp.username", "SMTP_USERNAME")
+	viper.BindEnv("mail.smtp.password", "SMTP_PASSWORD")
+
 	// Services
 	viper.BindEnv("services.user_service_url", "USER_SERVICE_URL")
 	viper.BindEnv("services.product_service_url", "PRODUCT_SERVICE_URL")
 	viper.BindEnv("services.cart_service_url", "CART_SERVICE_URL")
 	viper.BindEnv("services.order_service_url", "ORDER_SERVICE_URL")
 	viper.BindEnv("services.gateway_service_url", "GATEWAY_SERVICE_URL")
+	viper.BindEnv("services.payment_service_url", "PAYMENT_SERVICE_URL")
+	viper.BindEnv("services.shipping_service_url", "SHIPPING_SERVICE_URL")
+
+	// Service auth
+	viper.BindEnv("service_auth.max_clock_skew", "SERVICE_AUTH_MAX_CLOCK_SKEW")
+	bindServiceAuthEnvVars()
 
 	// Environment
 	viper.BindEnv("environment", "ENV")
 	viper.BindEnv("service_name", "SERVICE_NAME")
 }
 
+// bindServiceAuthEnvVars binds the service auth keys, e.g. SERVICE_AUTH_KEY_IDENTITY, they are
+// secrets so production setups pass them in the environment rather than the config file
+func bindServiceAuthEnvVars() {
+	for _, service := range []string{"identity", "product", "cart", "order", "payment", "shipping", "notification"} {
+		viper.BindEnv("service_auth.keys."+service, "SERVICE_AUTH_KEY_"+strings.ToUpper(service))
+	}
+}
+
+// checkServiceAuthKeys refuses the service auth keys shipped in the config files outside
+// development, anyone can read them and sign requests to internal endpoints
+func (c *Config) checkServiceAuthKeys() error {
+	if !c.IsDevelopment() && c.ServiceAuth.HasDevelopmentKeys() {
+		return fmt.Errorf("service_auth keys for development only are set in environment %s, pass SERVICE_AUTH_KEY_<SERVICE> instead", c.Environment)
+	}
+	return nil
+}
+
 func (c *Config) setDefaults() {
 	if c.Environment == "" {
 		c.Environment = "development"
@@ -165,6 +229,10 @@ func (c *Config) setDefaults() {
 	c.HTTP.SetDefaults()
 	c.PG.SetDefaults()
 	c.Redis.SetDefaults()
+	c.Tracing.SetDefaults()
+	c.Health.SetDefaults()
+	c.Mail.SetDefaults()
+	c.ServiceAuth.SetDefaults()
 }
 
 // Helper methods
@@ -0,0 +1,24 @@
+package health
+
+import "time"
+
+// Health holds readiness probe configuration
+type Health struct {
+	// Timeout bounds every readiness check, in seconds
+	Timeout int `mapstructure:"timeout" env:"HEALTH_TIMEOUT"`
+	// Dependencies are downstream services, by name, that have to be alive for this
+	// service to be ready, e.g. "product". Only list services the service cannot work without.
+	Dependencies []string `mapstructure:"dependencies" env:"HEALTH_DEPENDENCIES"`
+}
+
+// SetDefaults sets default values for health configuration
+func (h *Health) SetDefaults() {
+	if h.Timeout == 0 {
+		h.Timeout = 2
+	}
+}
+
+// GetTimeout returns the check timeout as time.Duration
+func (h *Health) GetTimeout() time.Duration {
+	return time.Duration(h.Timeout) * time.Second
+}
@@ -6,8 +6,30 @@ import "time"
 type JWT struct {
 	Secret     string `mapstructure:"secret" env:"JWT_SECRET"`
 	Expiration string `mapstructure:"expiration" env:"JWT_EXPIRATION"`
-	Issuer     string `mapstructure:"issuer" env:"JWT_ISSUER"`
-	Audience   string `mapstructure:"audience" env:"JWT_AUDIENCE"`
+	// RefreshExpiration is the lifetime of a refresh token, every rotation starts a new one
+	RefreshExpiration string `mapstructure:"refresh_expiration" env:"JWT_REFRESH_EXPIRATION"`
+	Issuer            string `mapstructure:"issuer" env:"JWT_ISSUER"`
+	Audience          string `mapstructure:"audience" env:"JWT_AUDIENCE"`
+
+	// SigningKey signs new tokens, PreviousKeys only verify tokens signed before a rotation.
+	// A previous key is published until RotationGracePeriod after its RetiredAt time, which
+	// has to be longer than the token expiration.
+	SigningKey          Key    `mapstructure:"signing_key"`
+	PreviousKeys        []Key  `mapstructure:"previous_keys"`
+	RotationGracePeriod string `mapstructure:"rotation_grace_period" env:"JWT_ROTATION_GRACE_PERIOD"`
+}
+
+// Key is a PEM encoded RSA (RS256) or Ed25519 (EdDSA) key on disk, identified by the kid header
+type Key struct {
+	ID   string `mapstructure:"id"`
+	Path string `mapstructure:"path"`
+	// RetiredAt is the RFC 3339 time the key stopped signing, previous keys only
+	RetiredAt string `mapstructure:"retired_at"`
+}
+
+// GetRetiredAt returns the retirement time of a previous key
+func (k *Key) GetRetiredAt() (time.Time, error) {
+	return time.Parse(time.RFC3339, k.RetiredAt)
 }
 
 // GetExpirationDuration returns the JWT expiration as time.Duration
@@ -18,7 +40,23 @@ func (j *JWT) GetExpirationDuration() (time.Duration, error) {
 	return time.ParseDuration(j.Expiration)
 }
 
+// GetRefreshExpirationDuration returns the refresh token expiration as time.Duration
+func (j *JWT) GetRefreshExpirationDuration() (time.Duration, error) {
+	if j.RefreshExpiration == "" {
+		return 7 * 24 * time.Hour, nil // default 7 days
+	}
+	return time.ParseDuration(j.RefreshExpiration)
+}
+
+// GetRotationGracePeriod returns how long a retired key stays valid as time.Duration
+func (j *JWT) GetRotationGracePeriod() (time.Duration, error) {
+	if j.RotationGracePeriod == "" {
+		return time.Hour, nil // default 1 hour
+	}
+	return time.ParseDuration(j.RotationGracePeriod)
+}
+
 // IsValid checks if required JWT fields are set
 func (j *JWT) IsValid() bool {
-	return j.Secret != ""
+	return j.Secret != "" || (j.SigningKey.ID != "" && j.SigningKey.Path != "")
 }
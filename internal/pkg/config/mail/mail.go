@@ -0,0 +1,52 @@
+package mail
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+	DriverSMTP = "smtp"
+)
+
+// Mail holds outgoing email configuration
+type Mail struct {
+	// Driver is "log" (messages are logged), "file" (appended to FilePath) or "smtp".
+	// The log and file drivers are for local development only.
+	Driver   string `mapstructure:"driver" env:"MAIL_DRIVER"`
+	From     string `mapstructure:"from" env:"MAIL_FROM"`
+	FilePath string `mapstructure:"file_path" env:"MAIL_FILE_PATH"`
+	SMTP     SMTP   `mapstructure:"smtp"`
+}
+
+// SMTP holds the mail server of the smtp driver, it has to support STARTTLS unless it is local
+type SMTP struct {
+	Host     string `mapstructure:"host" env:"SMTP_HOST"`
+	Port     string `mapstructure:"port" env:"SMTP_PORT"`
+	Username string `mapstructure:"username" env:"SMTP_USERNAME"`
+	Password string `mapstructure:"password" env:"SMTP_PASSWORD"`
+}
+
+// IsValid checks if required mail fields are set
+func (m *Mail) IsValid() bool {
+	switch m.Driver {
+	case DriverLog:
+		return true
+	case DriverFile:
+		return m.FilePath != ""
+	case DriverSMTP:
+		return m.From != "" && m.SMTP.Host != "" && m.SMTP.Port != ""
+	default:
+		return false
+	}
+}
+
+// SetDefaults sets default values for mail configuration
+func (m *Mail) SetDefaults() {
+	if m.Driver == "" {
+		m.Driver = DriverLog
+	}
+	if m.From == "" {
+		m.From = "no-reply@go-store.local"
+	}
+	if m.SMTP.Port == "" {
+		m.SMTP.Port = "587"
+	}
+}
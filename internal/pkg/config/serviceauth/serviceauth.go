@@ -0,0 +1,63 @@
+package serviceauth
+
+import (
+	"strings"
+	"time"
+)
+
+// minKeyLength is the shortest accepted HMAC key, in bytes
+const minKeyLength = 32
+
+// developmentKeyPrefix starts the keys shipped in the config files, they are public and only
+// accepted in development
+const developmentKeyPrefix = "development-only-"
+
+// ServiceAuth holds the keys requests between services are signed with
+type ServiceAuth struct {
+	// Keys are the HMAC keys by service name, e.g. "identity". A service signs its requests
+	// with its own key and accepts requests of the services whose keys it holds.
+	Keys map[string]string `mapstructure:"keys"`
+	// MaxClockSkew is how far the signing time of a request may be from now, in seconds
+	MaxClockSkew int `mapstructure:"max_clock_skew" env:"SERVICE_AUTH_MAX_CLOCK_SKEW"`
+}
+
+// SetDefaults sets default values for service auth configuration
+func (s *ServiceAuth) SetDefaults() {
+	if s.MaxClockSkew == 0 {
+		s.MaxClockSkew = 300 // 5 minutes
+	}
+}
+
+// IsValid checks that every key is long enough to be secure, empty keys are not set
+func (s *ServiceAuth) IsValid() bool {
+	for _, key := range s.Keys {
+		if key != "" && len(key) < minKeyLength {
+			return false
+		}
+	}
+	return true
+}
+
+// HasDevelopmentKeys reports whether any key is one of the keys shipped for development
+func (s *ServiceAuth) HasDevelopmentKeys() bool {
+	for _, key := range s.Keys {
+		if strings.HasPrefix(key, developmentKeyPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetKey returns the key of a service, false when it has none
+func (s *ServiceAuth) GetKey(service string) ([]byte, bool) {
+	key, ok := s.Keys[service]
+	if !ok || key == "" {
+		return nil, false
+	}
+	return []byte(key), true
+}
+
+// GetMaxClockSkew returns the max clock skew as time.Duration
+func (s *ServiceAuth) GetMaxClockSkew() time.Duration {
+	return time.Duration(s.MaxClockSkew) * time.Second
+}
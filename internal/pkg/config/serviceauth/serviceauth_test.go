@@ -0,0 +1,54 @@
+package serviceauth_test
+
+import (
+	"testing"
+
+	"github.com/hthinh24/go-store/internal/pkg/config/serviceauth"
+)
+
+func TestServiceAuthKeys(t *testing.T) {
+	tests := []struct {
+		name            string
+		keys            map[string]string
+		wantValid       bool
+		wantDevelopment bool
+	}{
+		{
+			name:      "no keys",
+			wantValid: true,
+		},
+		{
+			name:      "random keys",
+			keys:      map[string]string{"identity": "0123456789abcdef0123456789abcdef"},
+			wantValid: true,
+		},
+		{
+			name:      "unset key",
+			keys:      map[string]string{"identity": ""},
+			wantValid: true,
+		},
+		{
+			name: "short key",
+			keys: map[string]string{"identity": "too-short"},
+		},
+		{
+			name:            "shipped key",
+			keys:            map[string]string{"identity": "0123456789abcdef0123456789abcdef", "cart": "development-only-cart-service-auth-key"},
+			wantValid:       true,
+			wantDevelopment: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := serviceauth.ServiceAuth{Keys: tt.keys}
+
+			if got := cfg.IsValid(); got != tt.wantValid {
+				t.Errorf("IsValid() = %v, want %v", got, tt.wantValid)
+			}
+			if got := cfg.HasDevelopmentKeys(); got != tt.wantDevelopment {
+				t.Errorf("HasDevelopmentKeys() = %v, want %v", got, tt.wantDevelopment)
+			}
+		})
+	}
+}
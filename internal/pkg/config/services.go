@@ -9,6 +9,11 @@ type Services struct {
 	GatewayServiceURL      string `mapstructure:"gateway_service_url" env:"GATEWAY_SERVICE_URL"`
 	NotificationServiceURL string `mapstructure:"notification_service_url" env:"NOTIFICATION_SERVICE_URL"`
 	PaymentServiceURL      string `mapstructure:"payment_service_url" env:"PAYMENT_SERVICE_URL"`
+	ShippingServiceURL     string `mapstructure:"shipping_service_url" env:"SHIPPING_SERVICE_URL"`
+
+	// Instances lists every endpoint of a service by name, e.g. "product",
+	// for services running more than one replica
+	Instances map[string][]string `mapstructure:"instances"`
 }
 
 // GetServiceURL returns the URL for a specific service
@@ -28,11 +33,25 @@ func (s *Services) GetServiceURL(serviceName string) string {
 		return s.NotificationServiceURL
 	case "payment":
 		return s.PaymentServiceURL
+	case "shipping":
+		return s.ShippingServiceURL
 	default:
 		return ""
 	}
 }
 
+// GetServiceURLs returns every endpoint of a service, falling back to its single URL
+func (s *Services) GetServiceURLs(serviceName string) []string {
+	if urls := s.Instances[serviceName]; len(urls) > 0 {
+		return urls
+	}
+
+	if url := s.GetServiceURL(serviceName); url != "" {
+		return []string{url}
+	}
+	return nil
+}
+
 // SetDefaults sets default service URLs based on standard ports
 func (s *Services) SetDefaults() {
 	if s.IdentityServiceURL == "" {
@@ -0,0 +1,40 @@
+package tracing
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Tracing holds OpenTelemetry tracing configuration
+type Tracing struct {
+	Enabled bool `mapstructure:"enabled" env:"TRACING_ENABLED"`
+	// Exporter is "stdout", "file" (JSON spans appended to FilePath) or "none"
+	Exporter string `mapstructure:"exporter" env:"TRACING_EXPORTER"`
+	FilePath string `mapstructure:"file_path" env:"TRACING_FILE_PATH"`
+	// SampleRatio is the share of new traces recorded, between 0 and 1.
+	// Traces started upstream keep the sampling decision of their parent.
+	SampleRatio float64 `mapstructure:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// IsValid checks if required tracing fields are set
+func (t *Tracing) IsValid() bool {
+	switch t.Exporter {
+	case ExporterNone, ExporterStdout:
+		return true
+	case ExporterFile:
+		return t.FilePath != ""
+	default:
+		return false
+	}
+}
+
+// SetDefaults sets default values for tracing configuration
+func (t *Tracing) SetDefaults() {
+	if t.Exporter == "" {
+		t.Exporter = ExporterStdout
+	}
+	if t.SampleRatio == 0 {
+		t.SampleRatio = 1
+	}
+}
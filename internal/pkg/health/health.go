@@ -0,0 +1,179 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/config"
+	healthConfig "github.com/hthinh24/go-store/internal/pkg/config/health"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+)
+
+const (
+	LivePath  = "/health/live"
+	ReadyPath = "/health/ready"
+
+	// legacyPath is kept for callers of the old endpoint, it answers like the liveness probe
+	legacyPath = "/health"
+
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckFunc reports whether a dependency is usable, it must honor the context deadline
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a single readiness check
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of a readiness probe, the service is up only if every check is up
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Health serves the liveness and readiness probes of a service
+type Health struct {
+	timeout      time.Duration
+	dependencies []string
+	checks       []check
+}
+
+func New(cfg healthConfig.Health) *Health {
+	return &Health{
+		timeout:      cfg.GetTimeout(),
+		dependencies: cfg.Dependencies,
+	}
+}
+
+// AddCheck adds a readiness check, checks are not safe to add once the probes are served
+func (h *Health) AddCheck(name string, fn CheckFunc) {
+	h.checks = append(h.checks, check{name: name, fn: fn})
+}
+
+// AddServiceChecks adds a ServiceCheck for every configured dependency
+func (h *Health) AddServiceChecks(services config.Services) error {
+	client := &http.Client{Timeout: h.timeout}
+	for _, name := range h.dependencies {
+		baseURL := services.GetServiceURL(name)
+		if baseURL == "" {
+			return fmt.Errorf("unknown health dependency: %s", name)
+		}
+		h.AddCheck(name, ServiceCheck(client, baseURL))
+	}
+
+	return nil
+}
+
+// Register adds the probe routes to the router
+func (h *Health) Register(router gin.IRoutes) {
+	router.GET(legacyPath, h.live)
+	router.GET(LivePath, h.live)
+	router.GET(ReadyPath, h.ready)
+}
+
+// Ready runs every check concurrently, each bounded by the configured timeout
+func (h *Health) Ready(ctx context.Context) Report {
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]CheckResult, len(h.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := h.run(ctx, c.fn)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (h *Health) run(ctx context.Context, fn CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// live only tells the process is serving requests, it never checks dependencies so an
+// unavailable database does not get the service restarted
+func (h *Health) live(c *gin.Context) {
+	c.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Service is alive", gin.H{"status": StatusUp}))
+}
+
+func (h *Health) ready(c *gin.Context) {
+	report := h.Ready(c.Request.Context())
+	if report.Status != StatusUp {
+		c.JSON(http.StatusServiceUnavailable, rest.NewAPIResponse(http.StatusServiceUnavailable, "Service is not ready", report))
+		return
+	}
+
+	c.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Service is ready", report))
+}
+
+// DBCheck pings a database, e.g. the connection pool of a GORM DB
+func DBCheck(db *sql.DB) CheckFunc {
+	return db.PingContext
+}
+
+// ServiceCheck calls the liveness probe of a downstream service, checking its readiness
+// instead would take every caller down with it when its own dependency fails
+func ServiceCheck(client *http.Client, baseURL string) CheckFunc {
+	url := strings.TrimSuffix(baseURL, "/") + LivePath
+
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+package jwks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+)
+
+// minRefreshInterval bounds how often unknown kids can trigger a fetch, so tokens with
+// made up kids cannot flood the issuer
+const minRefreshInterval = 10 * time.Second
+
+var ErrNoKeys = errors.New("no signing keys fetched yet")
+
+// Client fetches the key set of an issuer and caches it for refreshInterval. An unknown kid
+// refetches the set early, which picks up rotated keys before the cache expires.
+type Client struct {
+	logger          logger.Logger
+	url             string
+	refreshInterval time.Duration
+	httpClient      *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]Key
+	fetchedAt time.Time
+
+	fetchMu     sync.Mutex
+	lastAttempt time.Time
+}
+
+// NewClient creates a key set client, a nil transport uses http.DefaultTransport
+func NewClient(logger logger.Logger, url string, refreshInterval time.Duration, transport http.RoundTripper) *Client {
+	return &Client{
+		logger:          logger,
+		url:             url,
+		refreshInterval: refreshInterval,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   5 * time.Second,
+		},
+	}
+}
+
+// Keyfunc resolves token keys from the fetched key set
+func (c *Client) Keyfunc() jwt.Keyfunc {
+	return Keyfunc(c.lookup)
+}
+
+// Ready fetches the key set unless it was fetched before, for readiness probes
+func (c *Client) Ready(ctx context.Context) error {
+	c.mu.RLock()
+	fetched := len(c.keys) > 0
+	c.mu.RUnlock()
+	if fetched {
+		return nil
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.keys) == 0 {
+		return ErrNoKeys
+	}
+	return nil
+}
+
+func (c *Client) lookup(kid string) (Key, bool) {
+	key, ok, stale := c.cached(kid)
+	if ok && !stale {
+		return key, true
+	}
+
+	// Keep verifying with the cached keys when the issuer cannot be reached
+	if err := c.refresh(context.Background()); err != nil {
+		c.logger.Warn("Failed to refresh signing keys, url: ", c.url, ", error: ", err)
+	}
+
+	key, ok, _ = c.cached(kid)
+	return key, ok
+}
+
+func (c *Client) cached(kid string) (Key, bool, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	key, ok := c.keys[kid]
+	return key, ok, time.Since(c.fetchedAt) > c.refreshInterval
+}
+
+func (c *Client) refresh(ctx context.Context) error {
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+
+	// Concurrent lookups share the fetch of the first one
+	if time.Since(c.lastAttempt) < minRefreshInterval {
+		return nil
+	}
+	c.lastAttempt = time.Now()
+
+	keys, err := c.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	c.logger.Info("Signing keys refreshed, url: ", c.url, ", keys: ", len(keys))
+	return nil
+}
+
+func (c *Client) fetch(ctx context.Context) (map[string]Key, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var set Set
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]Key, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.Key()
+		if err != nil {
+			// Skip keys this verifier cannot use, e.g. of a newer algorithm
+			c.logger.Warn("Skipping signing key, kid: ", jwk.Kid, ", error: ", err)
+			continue
+		}
+		keys[key.ID] = key
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,179 @@
+package jwks
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// Path is the well known route a key set is served on
+	Path = "/.well-known/jwks.json"
+
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnknownKey         = errors.New("unknown signing key")
+	ErrUnsupportedKey     = errors.New("unsupported key type")
+	ErrAlgorithmMismatch  = errors.New("token algorithm does not match the signing key")
+	ErrMissingKeyIDHeader = errors.New("token has no kid header")
+)
+
+// Key is a public key tokens are verified with
+type Key struct {
+	ID        string
+	Algorithm string
+	PublicKey crypto.PublicKey
+}
+
+// NewKey returns the verification key of a public key, the algorithm follows the key type:
+// RS256 for RSA and EdDSA for Ed25519 keys
+func NewKey(id string, publicKey crypto.PublicKey) (Key, error) {
+	algorithm, err := Algorithm(publicKey)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{ID: id, Algorithm: algorithm, PublicKey: publicKey}, nil
+}
+
+// Algorithm returns the JWS algorithm used with a public key
+func Algorithm(publicKey crypto.PublicKey) (string, error) {
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		return AlgorithmRS256, nil
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA, nil
+	default:
+		return "", ErrUnsupportedKey
+	}
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// Set is a JSON Web Key Set
+type Set struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ToJWK encodes the key for publishing
+func (k Key) ToJWK() (JWK, error) {
+	switch publicKey := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: k.ID,
+			Use: "sig",
+			Alg: k.Algorithm,
+			N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: k.ID,
+			Use: "sig",
+			Alg: k.Algorithm,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(publicKey),
+		}, nil
+	default:
+		return JWK{}, ErrUnsupportedKey
+	}
+}
+
+// Key decodes a published key
+func (j JWK) Key() (Key, error) {
+	var publicKey crypto.PublicKey
+	switch {
+	case j.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid modulus of key %s: %w", j.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid exponent of key %s: %w", j.Kid, err)
+		}
+		publicKey = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case j.Kty == "OKP" && j.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return Key{}, fmt.Errorf("invalid public key %s", j.Kid)
+		}
+		publicKey = ed25519.PublicKey(x)
+	default:
+		return Key{}, ErrUnsupportedKey
+	}
+
+	key, err := NewKey(j.Kid, publicKey)
+	if err != nil {
+		return Key{}, err
+	}
+
+	// Never trust a published algorithm the key type cannot be used with
+	if j.Alg != "" && j.Alg != key.Algorithm {
+		return Key{}, fmt.Errorf("key %s: %w", j.Kid, ErrAlgorithmMismatch)
+	}
+	return key, nil
+}
+
+// NewSet encodes keys for publishing
+func NewSet(keys []Key) (Set, error) {
+	set := Set{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk, err := key.ToJWK()
+		if err != nil {
+			return Set{}, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set, nil
+}
+
+// Keyfunc resolves the verification key of a token by its kid header, the token algorithm
+// has to match the key so a token can never pick how it is verified
+func Keyfunc(lookup func(kid string) (Key, bool)) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, ErrMissingKeyIDHeader
+		}
+
+		key, ok := lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+		}
+
+		if token.Method.Alg() != key.Algorithm {
+			return nil, ErrAlgorithmMismatch
+		}
+		return key.PublicKey, nil
+	}
+}
+
+// ValidMethods are the signing algorithms verifiers accept
+func ValidMethods() []string {
+	return []string{AlgorithmRS256, AlgorithmEdDSA}
+}
@@ -1,6 +1,9 @@
 package logger
 
 import (
+	"context"
+
+	"github.com/hthinh24/go-store/internal/pkg/middleware/requestid"
 	"go.uber.org/zap"
 )
 
@@ -9,6 +12,8 @@ type Logger interface {
 	Error(args ...interface{})
 	Debug(args ...interface{})
 	Warn(args ...interface{})
+	// WithContext returns a logger stamping the request ID of ctx on every line
+	WithContext(ctx context.Context) Logger
 }
 
 type appLogger struct {
@@ -37,6 +42,18 @@ func (a *appLogger) Debug(args ...interface{}) {
 func (a *appLogger) Warn(args ...interface{}) {
 	a.logger.Warn(args...)
 }
+func (a *appLogger) WithContext(ctx context.Context) Logger {
+	requestID := requestid.FromContext(ctx)
+	if requestID == "" {
+		return a
+	}
+
+	return &appLogger{
+		level:  a.level,
+		logger: a.logger.With("request_id", requestID),
+	}
+}
+
 func WithComponent(level string, component string) Logger {
 	if component == "" {
 		return NewAppLogger(level)
@@ -0,0 +1,125 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	mailConfig "github.com/hthinh24/go-store/internal/pkg/config/mail"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+)
+
+var ErrInvalidHeader = errors.New("mail header contains a line break")
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails, services depend on it so the transport can be swapped
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// New returns the sender of the configured driver
+func New(cfg mailConfig.Mail, logger logger.Logger) (Sender, error) {
+	if !cfg.IsValid() {
+		return nil, fmt.Errorf("invalid mail driver: %s", cfg.Driver)
+	}
+
+	switch cfg.Driver {
+	case mailConfig.DriverFile:
+		return &fileSender{from: cfg.From, path: cfg.FilePath}, nil
+	case mailConfig.DriverSMTP:
+		return &smtpSender{from: cfg.From, cfg: cfg.SMTP}, nil
+	default:
+		return &logSender{logger: logger}, nil
+	}
+}
+
+// logSender logs every message instead of sending it
+type logSender struct {
+	logger logger.Logger
+}
+
+func (l *logSender) Send(ctx context.Context, message Message) error {
+	l.logger.WithContext(ctx).Info("Mail to: ", message.To, ", subject: ", message.Subject, "\n", message.Body)
+	return nil
+}
+
+// fileSender appends every message to a file, one after another in the RFC 5322 format
+type fileSender struct {
+	from string
+	path string
+	mu   sync.Mutex
+}
+
+func (f *fileSender) Send(_ context.Context, message Message) error {
+	data, err := format(f.from, message)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening mail file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// smtpSender sends messages through a mail server, net/smtp upgrades the connection with
+// STARTTLS and only authenticates over TLS or to localhost
+type smtpSender struct {
+	from string
+	cfg  mailConfig.SMTP
+}
+
+func (s *smtpSender) Send(_ context.Context, message Message) error {
+	data, err := format(s.from, message)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(s.cfg.Host, s.cfg.Port), auth, s.from, []string{message.To}, data)
+}
+
+func format(from string, message Message) ([]byte, error) {
+	// A line break in a header would let the value add headers or recipients of its own
+	for _, header := range []string{from, message.To, message.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", message.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// Path is the route metrics are exposed on
+	Path = "/metrics"
+
+	// routeKey overrides the route label of a request, see SetRoute
+	routeKey = "metrics_route"
+
+	unmatchedRoute = "unmatched"
+)
+
+// Metrics holds the Prometheus registry and the shared collectors of a service
+type Metrics struct {
+	registry   *prometheus.Registry
+	registerer prometheus.Registerer
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	clientDuration  *prometheus.HistogramVec
+	cacheRequests   *prometheus.CounterVec
+}
+
+// New creates the metrics of a service, every metric carries a "service" label
+func New(serviceName string) *Metrics {
+	registry := prometheus.NewRegistry()
+	registerer := prometheus.WrapRegistererWith(prometheus.Labels{"service": serviceName}, registry)
+
+	m := &Metrics{
+		registry:   registry,
+		registerer: registerer,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_server_requests_total",
+			Help: "Number of HTTP requests handled, by route and status code.",
+		}, []string{"method", "route", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_server_request_duration_seconds",
+			Help:    "Latency of HTTP requests handled, by route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		clientDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_client_request_duration_seconds",
+			Help:    "Latency of outbound HTTP requests, by target client and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"client", "method", "code"}),
+		cacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_requests_total",
+			Help: "Number of cache lookups, by cache and result (hit, miss or error).",
+		}, []string{"cache", "result"}),
+	}
+
+	registerer.MustRegister(
+		m.requests, m.requestDuration, m.clientDuration, m.cacheRequests,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Registerer returns the registerer for service specific collectors, labeled like the shared ones
+func (m *Metrics) Registerer() prometheus.Registerer {
+	return m.registerer
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Register adds the metrics route to the router
+func (m *Metrics) Register(router *gin.Engine) {
+	router.GET(Path, gin.WrapH(m.Handler()))
+}
+
+// Middleware records the count and latency of every request by route pattern
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path == Path {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		route := c.GetString(routeKey)
+		if route == "" {
+			route = c.FullPath()
+		}
+		if route == "" {
+			// Never label with raw paths, unknown paths would create unbounded series
+			route = unmatchedRoute
+		}
+
+		method := c.Request.Method
+		m.requests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.requestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// SetRoute sets the route label of a request handled without a gin route, e.g. by a NoRoute handler
+func SetRoute(c *gin.Context, route string) {
+	c.Set(routeKey, route)
+}
+
+// RegisterDB exposes the connection pool stats of a database
+func (m *Metrics) RegisterDB(name string, db *sql.DB) {
+	m.registerer.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// NewTransport records the latency of requests sent through base, a nil base uses http.DefaultTransport
+func (m *Metrics) NewTransport(client string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	observer := m.clientDuration.MustCurryWith(prometheus.Labels{"client": client})
+	return promhttp.InstrumentRoundTripperDuration(observer, base)
+}
+
+// CacheHit counts a cache lookup that found the entry
+func (m *Metrics) CacheHit(cache string) {
+	m.cacheRequests.WithLabelValues(cache, "hit").Inc()
+}
+
+// CacheMiss counts a cache lookup that did not find the entry
+func (m *Metrics) CacheMiss(cache string) {
+	m.cacheRequests.WithLabelValues(cache, "miss").Inc()
+}
+
+// CacheError counts a cache lookup that failed
+func (m *Metrics) CacheError(cache string) {
+	m.cacheRequests.WithLabelValues(cache, "error").Inc()
+}
@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+)
+
+// Policy decides whether the authenticated user may act on a request. It reads the
+// "user_id", "roles" and "permissions" keys the auth middlewares set on the context.
+type Policy struct {
+	name  string
+	allow func(c *gin.Context) bool
+}
+
+// New returns a policy with a custom rule, name is used in logs
+func New(name string, allow func(c *gin.Context) bool) Policy {
+	return Policy{name: name, allow: allow}
+}
+
+func (p Policy) String() string {
+	return p.name
+}
+
+// Allows tells whether the policy grants the request
+func (p Policy) Allows(c *gin.Context) bool {
+	return p.allow(c)
+}
+
+// Self grants requests whose path parameter is the ID of the authenticated user
+func Self(param string) Policy {
+	return New("self("+param+")", func(c *gin.Context) bool {
+		id, err := strconv.ParseInt(c.Param(param), 10, 64)
+		if err != nil {
+			return false
+		}
+
+		userID, ok := c.Get("user_id")
+		return ok && userID == id
+	})
+}
+
+// Permission grants users holding the permission
+func Permission(permission string) Policy {
+	return New("permission("+permission+")", func(c *gin.Context) bool {
+		return contains(c.GetStringSlice("permissions"), permission)
+	})
+}
+
+// Role grants users holding the role
+func Role(role string) Policy {
+	return New("role("+role+")", func(c *gin.Context) bool {
+		return contains(c.GetStringSlice("roles"), role)
+	})
+}
+
+// Any grants a request when one of the policies does
+func Any(policies ...Policy) Policy {
+	return New(join(policies, " or "), func(c *gin.Context) bool {
+		for _, policy := range policies {
+			if policy.Allows(c) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// All grants a request when every policy does
+func All(policies ...Policy) Policy {
+	return New(join(policies, " and "), func(c *gin.Context) bool {
+		for _, policy := range policies {
+			if !policy.Allows(c) {
+				return false
+			}
+		}
+		return len(policies) > 0
+	})
+}
+
+// SelfOrPermission is the common rule of user owned resources, e.g. a profile can be
+// changed by its user or by staff managing users
+func SelfOrPermission(param string, permission string) Policy {
+	return Any(Self(param), Permission(permission))
+}
+
+type PolicyMiddleware struct {
+	logger logger.Logger
+}
+
+func NewPolicyMiddleware(logger logger.Logger) *PolicyMiddleware {
+	return &PolicyMiddleware{
+		logger: logger,
+	}
+}
+
+// Require enforces the policy of a route
+// NOTE: This middleware should be used AFTER an AuthRequired middleware
+func (m *PolicyMiddleware) Require(policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("user_id"); !exists {
+			c.JSON(http.StatusUnauthorized, rest.ErrorResponse{
+				ApiError: rest.UnauthorizedError,
+				Message:  "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+
+		if !policy.Allows(c) {
+			m.logger.WithContext(c.Request.Context()).Warn("Access denied by policy: ", policy, ", user ID: ", c.GetInt64("user_id"), ", path: ", c.Request.URL.Path)
+			c.JSON(http.StatusForbidden, rest.ErrorResponse{
+				ApiError: rest.ForbiddenError,
+				Message:  "Access to this resource is not allowed",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func join(policies []Policy, separator string) string {
+	names := make([]string, len(policies))
+	for i, policy := range policies {
+		names[i] = policy.name
+	}
+	return "(" + strings.Join(names, separator) + ")"
+}
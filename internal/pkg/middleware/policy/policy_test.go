@@ -0,0 +1,90 @@
+package policy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/middleware/policy"
+)
+
+// principal is what an auth middleware would have set on the context, nil means anonymous
+type principal struct {
+	userID      int64
+	roles       []string
+	permissions []string
+}
+
+var (
+	self  = &principal{userID: 7, roles: []string{"user"}, permissions: []string{"user.read", "user.update"}}
+	admin = &principal{userID: 1, roles: []string{"admin"}, permissions: []string{"system.admin", "user.manage"}}
+	other = &principal{userID: 8, roles: []string{"user"}, permissions: []string{"user.read", "user.update"}}
+)
+
+// serve runs a request for path through the policy on the route /users/:id
+func serve(t *testing.T, p policy.Policy, who *principal, path string) int {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if who != nil {
+			c.Set("user_id", who.userID)
+			c.Set("roles", who.roles)
+			c.Set("permissions", who.permissions)
+		}
+	})
+
+	middleware := policy.NewPolicyMiddleware(logger.NewAppLogger("development"))
+	router.GET("/users/:id", middleware.Require(p), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder.Code
+}
+
+func TestRequire(t *testing.T) {
+	selfOrManage := policy.SelfOrPermission("id", "user.manage")
+
+	tests := []struct {
+		name   string
+		policy policy.Policy
+		who    *principal
+		path   string
+		want   int
+	}{
+		{"self acts on own user", selfOrManage, self, "/users/7", http.StatusOK},
+		{"admin acts on another user", selfOrManage, admin, "/users/7", http.StatusOK},
+		{"admin acts on own user", selfOrManage, admin, "/users/1", http.StatusOK},
+		{"other user is denied", selfOrManage, other, "/users/7", http.StatusForbidden},
+		{"malformed id is denied", selfOrManage, self, "/users/abc", http.StatusForbidden},
+		{"anonymous is unauthenticated", selfOrManage, nil, "/users/7", http.StatusUnauthorized},
+		{"self only denies admin", policy.Self("id"), admin, "/users/7", http.StatusForbidden},
+		{"self only allows self", policy.Self("id"), self, "/users/7", http.StatusOK},
+		{"role grants admin", policy.Role("admin"), admin, "/users/7", http.StatusOK},
+		{"role denies user", policy.Role("admin"), other, "/users/7", http.StatusForbidden},
+		{"all needs every policy", policy.All(policy.Self("id"), policy.Permission("user.manage")), self, "/users/7", http.StatusForbidden},
+		{"all grants when every policy does", policy.All(policy.Self("id"), policy.Role("user")), self, "/users/7", http.StatusOK},
+		{"empty any denies", policy.Any(), self, "/users/7", http.StatusForbidden},
+		{"empty all denies", policy.All(), self, "/users/7", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serve(t, tt.policy, tt.who, tt.path); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyString(t *testing.T) {
+	got := policy.SelfOrPermission("id", "user.manage").String()
+	if want := "(self(id) or permission(user.manage))"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header carries the request ID between the gateway, services and their clients
+const Header = "X-Request-ID"
+
+// maxLength bounds request IDs accepted from callers
+const maxLength = 128
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request ID
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// FromContext returns the request ID of ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	return requestID
+}
+
+// Inject sets the request ID of the request context on an outbound request
+func Inject(req *http.Request) {
+	if requestID := FromContext(req.Context()); requestID != "" {
+		req.Header.Set(Header, requestID)
+	}
+}
+
+// New generates a random request ID
+func New() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestID accepts the X-Request-ID of the caller or generates one, stores it in the
+// request context and echoes it in the response. The request header is updated too,
+// so it is forwarded when the request is proxied.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(Header)
+		if !isValid(requestID) {
+			requestID = New()
+		}
+
+		c.Request.Header.Set(Header, requestID)
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), requestID))
+		c.Header(Header, requestID)
+
+		c.Next()
+	}
+}
+
+// isValid only accepts short IDs of visible ASCII characters, they end up in logs and headers
+func isValid(requestID string) bool {
+	if requestID == "" || len(requestID) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < '!' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package serviceauth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	serviceAuthConfig "github.com/hthinh24/go-store/internal/pkg/config/serviceauth"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+)
+
+// Headers of a signed request. The signature is an HMAC-SHA256, with the key of the calling
+// service, of the service name, timestamp, method, request URI and body hash.
+const (
+	HeaderService   = "X-Service-Name"
+	HeaderTimestamp = "X-Service-Timestamp"
+	HeaderSignature = "X-Service-Signature"
+)
+
+var ErrInvalidConfig = errors.New("invalid service auth config")
+
+// Sign returns the hex encoded signature of a request, timestamp is in Unix seconds
+func Sign(key []byte, service string, timestamp int64, method string, requestURI string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join([]string{
+		service,
+		strconv.FormatInt(timestamp, 10),
+		method,
+		requestURI,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signingTransport signs every request with the key of its service
+type signingTransport struct {
+	service string
+	key     []byte
+	base    http.RoundTripper
+}
+
+// NewTransport wraps an HTTP transport so the requests of service are signed with its key, a
+// nil base uses http.DefaultTransport
+func NewTransport(service string, cfg serviceAuthConfig.ServiceAuth, base http.RoundTripper) (http.RoundTripper, error) {
+	if !cfg.IsValid() {
+		return nil, ErrInvalidConfig
+	}
+
+	key, ok := cfg.GetKey(service)
+	if !ok {
+		return nil, fmt.Errorf("%w: no key for service %s", ErrInvalidConfig, service)
+	}
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &signingTransport{service: service, key: key, base: base}, nil
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A RoundTripper must not modify the request it was given
+	signed := req.Clone(req.Context())
+	if body != nil {
+		signed.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	timestamp := time.Now().Unix()
+	signed.Header.Set(HeaderService, t.service)
+	signed.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	signed.Header.Set(HeaderSignature, Sign(t.key, t.service, timestamp, req.Method, req.URL.RequestURI(), body))
+
+	return t.base.RoundTrip(signed)
+}
+
+// readBody returns a copy of the request body, read through GetBody when possible so the
+// body of the request stays unread
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// ServiceAuthMiddleware authenticates requests signed by other services. A signature can be
+// replayed until its timestamp is older than the max clock skew, so internal routes must be
+// reads or idempotent.
+type ServiceAuthMiddleware struct {
+	logger  logger.Logger
+	cfg     serviceAuthConfig.ServiceAuth
+	maxSkew time.Duration
+}
+
+func NewServiceAuthMiddleware(logger logger.Logger, cfg serviceAuthConfig.ServiceAuth) (*ServiceAuthMiddleware, error) {
+	if !cfg.IsValid() {
+		return nil, ErrInvalidConfig
+	}
+
+	return &ServiceAuthMiddleware{
+		logger:  logger,
+		cfg:     cfg,
+		maxSkew: cfg.GetMaxClockSkew(),
+	}, nil
+}
+
+// Require accepts requests signed by one of the services and sets the caller as "service"
+// in the context, services without a configured key are always rejected
+func (m *ServiceAuthMiddleware) Require(services ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service := c.GetHeader(HeaderService)
+		if err := m.verify(c, services); err != nil {
+			m.logger.WithContext(c.Request.Context()).Warn("Service authentication failed, service: ", service,
+				", path: ", c.Request.URL.Path, ", error: ", err)
+			c.JSON(http.StatusUnauthorized, rest.ErrorResponse{
+				ApiError: rest.UnauthorizedError,
+				Message:  "Service not authenticated",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("service", service)
+		c.Next()
+	}
+}
+
+func (m *ServiceAuthMiddleware) verify(c *gin.Context, services []string) error {
+	service := c.GetHeader(HeaderService)
+	if !contains(services, service) {
+		return fmt.Errorf("service %q is not allowed", service)
+	}
+
+	key, ok := m.cfg.GetKey(service)
+	if !ok {
+		return fmt.Errorf("no key for service %q", service)
+	}
+
+	timestamp, err := strconv.ParseInt(c.GetHeader(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return errors.New("malformed timestamp")
+	}
+	if skew := time.Since(time.Unix(timestamp, 0)).Abs(); skew > m.maxSkew {
+		return fmt.Errorf("timestamp is %s off", skew.Round(time.Second))
+	}
+
+	signature, err := hex.DecodeString(c.GetHeader(HeaderSignature))
+	if err != nil {
+		return errors.New("malformed signature")
+	}
+
+	body, err := readBody(c.Request)
+	if err != nil {
+		return err
+	}
+
+	expected, _ := hex.DecodeString(Sign(key, service, timestamp, c.Request.Method, c.Request.URL.RequestURI(), body))
+	if !hmac.Equal(signature, expected) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
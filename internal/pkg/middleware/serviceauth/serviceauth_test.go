@@ -0,0 +1,152 @@
+package serviceauth_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	serviceAuthConfig "github.com/hthinh24/go-store/internal/pkg/config/serviceauth"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/middleware/serviceauth"
+)
+
+var cfg = serviceAuthConfig.ServiceAuth{
+	Keys: map[string]string{
+		"identity": "identity-test-key-0123456789abcdef",
+		"cart":     "cart-test-key-0123456789abcdef0123",
+	},
+	MaxClockSkew: 300,
+}
+
+const body = `{"user_id":42}`
+
+// newRouter serves POST /register for the identity service only, echoing the body it reads
+func newRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	middleware, err := serviceauth.NewServiceAuthMiddleware(logger.NewAppLogger("development"), cfg)
+	if err != nil {
+		t.Fatalf("NewServiceAuthMiddleware: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/register", middleware.Require("identity"), func(c *gin.Context) {
+		received, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusCreated, "application/json", received)
+	})
+	return router
+}
+
+// signedRequest builds a request signed by service at the time, then applies tamper
+func signedRequest(service string, at time.Time, tamper func(r *http.Request)) *http.Request {
+	key, _ := cfg.GetKey(service)
+	timestamp := at.Unix()
+
+	r := httptest.NewRequest(http.MethodPost, "/register?source=signup", strings.NewReader(body))
+	r.Header.Set(serviceauth.HeaderService, service)
+	r.Header.Set(serviceauth.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	r.Header.Set(serviceauth.HeaderSignature,
+		serviceauth.Sign(key, service, timestamp, http.MethodPost, "/register?source=signup", []byte(body)))
+	if tamper != nil {
+		tamper(r)
+	}
+	return r
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *http.Request
+		want    int
+	}{
+		{"valid signature", signedRequest("identity", time.Now(), nil), http.StatusCreated},
+		{"slightly early clock", signedRequest("identity", time.Now().Add(time.Minute), nil), http.StatusCreated},
+		{"tampered body", signedRequest("identity", time.Now(), func(r *http.Request) {
+			r.Body = io.NopCloser(strings.NewReader(`{"user_id":43}`))
+		}), http.StatusUnauthorized},
+		{"tampered URI", signedRequest("identity", time.Now(), func(r *http.Request) {
+			r.URL.RawQuery = "source=admin"
+			r.RequestURI = "/register?source=admin"
+		}), http.StatusUnauthorized},
+		{"stale timestamp", signedRequest("identity", time.Now().Add(-10*time.Minute), nil), http.StatusUnauthorized},
+		{"future timestamp", signedRequest("identity", time.Now().Add(10*time.Minute), nil), http.StatusUnauthorized},
+		{"service not allowed", signedRequest("cart", time.Now(), nil), http.StatusUnauthorized},
+		{"unknown service", signedRequest("identity", time.Now(), func(r *http.Request) {
+			r.Header.Set(serviceauth.HeaderService, "payment")
+		}), http.StatusUnauthorized},
+		{"signed with another key", signedRequest("cart", time.Now(), func(r *http.Request) {
+			r.Header.Set(serviceauth.HeaderService, "identity")
+		}), http.StatusUnauthorized},
+		{"malformed signature", signedRequest("identity", time.Now(), func(r *http.Request) {
+			r.Header.Set(serviceauth.HeaderSignature, "not-hex")
+		}), http.StatusUnauthorized},
+		{"unsigned", httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body)), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			newRouter(t).ServeHTTP(recorder, tt.request)
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireKeepsBodyReadable(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newRouter(t).ServeHTTP(recorder, signedRequest("identity", time.Now(), nil))
+
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusCreated)
+	}
+	if got := recorder.Body.String(); got != body {
+		t.Errorf("handler read body %q, want %q", got, body)
+	}
+}
+
+func TestTransportSignsRequests(t *testing.T) {
+	server := httptest.NewServer(newRouter(t))
+	t.Cleanup(server.Close)
+
+	transport, err := serviceauth.NewTransport("identity", cfg, nil)
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	client := &http.Client{Transport: transport}
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/register?source=signup", bytes.NewReader([]byte(body)))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+
+	received, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusCreated || string(received) != body {
+		t.Errorf("response = %d %q, want %d %q", resp.StatusCode, received, http.StatusCreated, body)
+	}
+	if req.Header.Get(serviceauth.HeaderSignature) != "" {
+		t.Error("the transport modified the request it was given")
+	}
+}
+
+func TestNewTransportRequiresKey(t *testing.T) {
+	if _, err := serviceauth.NewTransport("payment", cfg, nil); err == nil {
+		t.Error("NewTransport without a key of the service succeeded")
+	}
+
+	short := serviceAuthConfig.ServiceAuth{Keys: map[string]string{"identity": "short"}}
+	if _, err := serviceauth.NewServiceAuthMiddleware(logger.NewAppLogger("development"), short); err == nil {
+		t.Error("NewServiceAuthMiddleware with a short key succeeded")
+	}
+}
@@ -17,10 +17,16 @@ var (
 	InternalServerErrorError = ApiError{InternalServerErrorCode, InternalServerErrorStatus}
 	NotFoundError            = ApiError{NotFoundCode, NotFoundStatus}
 	ConflictError            = ApiError{ConflictCode, ConflictStatus}
+	MethodNotAllowedError    = ApiError{MethodNotAllowedCode, MethodNotAllowedStatus}
+	TooManyRequestsError     = ApiError{TooManyRequestsCode, TooManyRequestsStatus}
 
 	UnauthorizedError = ApiError{UnauthorizedCode, UnauthorizedStatus}
 	ForbiddenError    = ApiError{ForbiddenCode, ForbiddenStatus}
 
+	BadGatewayError         = ApiError{BadGatewayCode, BadGatewayStatus}
+	ServiceUnavailableError = ApiError{ServiceUnavailableCode, ServiceUnavailableStatus}
+	GatewayTimeoutError     = ApiError{GatewayTimeoutCode, GatewayTimeoutStatus}
+
 	ValidationError = ApiError{ValidationErrorCode, ValidationErrorStatus}
 )
 
@@ -29,10 +35,16 @@ const (
 	InternalServerErrorCode = 500
 	NotFoundCode            = 404
 	ConflictCode            = 409
+	MethodNotAllowedCode    = 405
+	TooManyRequestsCode     = 429
 
 	UnauthorizedCode = 401
 	ForbiddenCode    = 403
 
+	BadGatewayCode         = 502
+	ServiceUnavailableCode = 503
+	GatewayTimeoutCode     = 504
+
 	ValidationErrorCode = 600
 )
 
@@ -41,9 +53,15 @@ const (
 	InternalServerErrorStatus = "Internal Server Error"
 	NotFoundStatus            = "Not Found"
 	ConflictStatus            = "Conflict"
+	MethodNotAllowedStatus    = "Method Not Allowed"
+	TooManyRequestsStatus     = "Too Many Requests"
 
 	UnauthorizedStatus = "Unauthorized"
 	ForbiddenStatus    = "Forbidden"
 
+	BadGatewayStatus         = "Bad Gateway"
+	ServiceUnavailableStatus = "Service Unavailable"
+	GatewayTimeoutStatus     = "Gateway Timeout"
+
 	ValidationErrorStatus = "Validation Failed"
 )
@@ -5,5 +5,16 @@ type PageResponse struct {
 	PageNumber int         `json:"page_number"`
 	TotalCount int         `json:"total_count"`
 	TotalPages int         `json:"total_pages"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 	Data       interface{} `json:"data"`
 }
+
+func NewPageResponse(paging *Paging, totalCount int, data interface{}) *PageResponse {
+	return &PageResponse{
+		PageSize:   paging.PageSize,
+		PageNumber: paging.PageNumber,
+		TotalCount: totalCount,
+		TotalPages: (totalCount + paging.PageSize - 1) / paging.PageSize,
+		Data:       data,
+	}
+}
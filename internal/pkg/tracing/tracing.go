@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	tracingConfig "github.com/hthinh24/go-store/internal/pkg/config/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ShutdownFunc flushes pending spans and releases the exporter
+type ShutdownFunc func(ctx context.Context) error
+
+// Init installs the global tracer provider and the W3C trace context propagator.
+// When tracing is disabled only the propagator is installed, so trace context received
+// from callers is still forwarded.
+func Init(serviceName string, cfg tracingConfig.Tracing) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled || cfg.Exporter == tracingConfig.ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	if !cfg.IsValid() {
+		return nil, fmt.Errorf("invalid tracing exporter: %s", cfg.Exporter)
+	}
+
+	writer, closeWriter, err := exporterWriter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(writer))
+	if err != nil {
+		closeWriter()
+		return nil, fmt.Errorf("error creating trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		defer closeWriter()
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+func exporterWriter(cfg tracingConfig.Tracing) (io.Writer, func() error, error) {
+	if cfg.Exporter != tracingConfig.ExporterFile {
+		return os.Stdout, func() error { return nil }, nil
+	}
+
+	file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening trace file: %w", err)
+	}
+	return file, file.Close, nil
+}
+
+// Middleware starts a server span for every Gin request, continuing the caller's trace
+func Middleware(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName)
+}
+
+// NewTransport wraps an HTTP transport with client spans and trace context injection,
+// a nil base uses http.DefaultTransport
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
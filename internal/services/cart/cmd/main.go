@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
+	"github.com/hthinh24/go-store/internal/pkg/health"
+	"github.com/hthinh24/go-store/internal/pkg/metrics"
 	"github.com/hthinh24/go-store/internal/pkg/middleware/auth"
+	"github.com/hthinh24/go-store/internal/pkg/middleware/requestid"
+	"github.com/hthinh24/go-store/internal/pkg/middleware/serviceauth"
+	"github.com/hthinh24/go-store/internal/pkg/tracing"
 	"github.com/hthinh24/go-store/services/cart/internal/config"
 	"github.com/hthinh24/go-store/services/cart/internal/controller/http"
 	"github.com/hthinh24/go-store/services/cart/internal/controller/http/client"
@@ -13,6 +19,7 @@ import (
 	customLog "github.com/hthinh24/go-store/internal/pkg/logger"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormTracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
 func main() {
@@ -28,14 +35,43 @@ func main() {
 	appLogger.Info("Starting Cart Service...")
 	appLogger.Info("Environment: %s", cfg.GetEnvironment())
 
-	db, err := initDatabase(cfg)
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(cfg.ServiceName, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Initialize metrics
+	appMetrics := metrics.New(cfg.ServiceName)
+
+	db, err := initDatabase(cfg, appMetrics)
 	if err != nil {
 		appLogger.Error("Failed to connect to database: %v", err)
 		log.Fatal(err)
 	}
 	appLogger.Info("Database connected successfully")
 
-	productClient := client.NewProductClient(cfg.GetProductServiceURL())
+	// Initialize health checks
+	appHealth, err := initHealth(cfg, db)
+	if err != nil {
+		appLogger.Error("Failed to initialize health checks: %v", err)
+		log.Fatal(err)
+	}
+
+	// Requests to other services are signed, the product service only serves SKUs to services
+	productTransport, err := serviceauth.NewTransport(cfg.ServiceName, cfg.ServiceAuth, appMetrics.NewTransport("product", tracing.NewTransport(nil)))
+	if err != nil {
+		appLogger.Error("Failed to initialize service auth: %v", err)
+		log.Fatal(err)
+	}
+	productClient := client.NewProductClient(cfg.GetProductServiceURL(), productTransport)
+
+	serviceAuthMiddleware, err := serviceauth.NewServiceAuthMiddleware(customLog.WithComponent(cfg.GetLogLevel(), "SERVICE-AUTH-MIDDLEWARE"), cfg.ServiceAuth)
+	if err != nil {
+		appLogger.Error("Failed to initialize service auth: %v", err)
+		log.Fatal(err)
+	}
 
 	cartRepository := repository.NewCartRepository(customLog.WithComponent(cfg.GetLogLevel(), "CART-REPOSITORY"), db)
 	cartService := service.NewCartService(customLog.WithComponent(cfg.GetLogLevel(), "CART-SERVICE"),
@@ -43,7 +79,7 @@ func main() {
 		productClient)
 	cartController := http.NewCartController(customLog.WithComponent(cfg.GetLogLevel(), "CART-CONTROLLER"), cartService)
 
-	router := setupRouter(cartController, cfg)
+	router := setupRouter(cartController, serviceAuthMiddleware, cfg, appMetrics, appHealth)
 
 	serverAddr := cfg.GetServerAddress()
 	appLogger.Info("Cart service starting on %s", serverAddr)
@@ -53,30 +89,63 @@ func main() {
 	}
 }
 
-func initDatabase(cfg *config.AppConfig) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(cfg.GetDatabaseURL()), &gorm.Config{})
+func initDatabase(cfg *config.AppConfig, appMetrics *metrics.Metrics) (*gorm.DB, error) {
+	// Unique violations are translated to gorm.ErrDuplicatedKey, the repositories report them
+	// as already existing carts and items
+	db, err := gorm.Open(postgres.Open(cfg.GetDatabaseURL()), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
+
+	// Trace every query, spans are children of the request span when the context is passed
+	if err := db.Use(gormTracing.NewPlugin(gormTracing.WithoutMetrics())); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	appMetrics.RegisterDB(cfg.PG.Database, sqlDB)
 	return db, nil
 }
 
-func setupRouter(cartController *http.CartController, cfg *config.AppConfig) *gin.Engine {
+func initHealth(cfg *config.AppConfig, db *gorm.DB) (*health.Health, error) {
+	appHealth := health.New(cfg.Health)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	appHealth.AddCheck("postgres", health.DBCheck(sqlDB))
+
+	if err := appHealth.AddServiceChecks(cfg.Services); err != nil {
+		return nil, err
+	}
+
+	return appHealth, nil
+}
+
+func setupRouter(cartController *http.CartController, serviceAuthMiddleware *serviceauth.ServiceAuthMiddleware, cfg *config.AppConfig, appMetrics *metrics.Metrics, appHealth *health.Health) *gin.Engine {
 	router := gin.Default()
+	router.Use(requestid.RequestID())
+	router.Use(tracing.Middleware(cfg.ServiceName))
+	router.Use(appMetrics.Middleware())
+
+	appMetrics.Register(router)
 
 	authMiddleware := auth.NewSharedAuthMiddleware(customLog.WithComponent(cfg.GetLogLevel(), "AUTH-MIDDLEWARE"))
 
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "healthy"})
-	})
+	// Liveness and readiness probes
+	appHealth.Register(router)
 
 	api := router.Group("/api")
 	{
 		v1 := api.Group("/v1")
 		cart := v1.Group("/cart")
 
-		// Public endpoints
-		cart.POST("/register", cartController.CreateCart())
+		// Internal endpoints, the identity service creates the cart of every new user
+		cart.POST("/register", serviceAuthMiddleware.Require("identity"), cartController.CreateCart())
 
 		// Apply authentication middleware to cart routes
 		cart.Use(authMiddleware.AuthRequired())
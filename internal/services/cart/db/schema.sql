@@ -45,7 +45,8 @@ ALTER TABLE cart_item
 -- ====================================================================
 
 -- Cart table indexes
-CREATE INDEX idx_cart_user_id ON cart (user_id);
+-- A user has one cart, a repeated registration cannot create a second one
+CREATE UNIQUE INDEX idx_cart_user_id ON cart (user_id);
 CREATE INDEX idx_cart_status ON cart (status);
 
 -- Cart newItem table indexes
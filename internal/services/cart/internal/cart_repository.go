@@ -1,15 +1,19 @@
 package internal
 
-import "github.com/hthinh24/go-store/services/cart/internal/entity"
+import (
+	"context"
+
+	"github.com/hthinh24/go-store/services/cart/internal/entity"
+)
 
 type CartRepository interface {
-	CreateCart(cart *entity.Cart) error
-	FindCartByUserID(userID int64) (*entity.Cart, error)
-	FindCartItemsByCartID(cartID int64) (*[]entity.CartItem, error)
+	CreateCart(ctx context.Context, cart *entity.Cart) error
+	FindCartByUserID(ctx context.Context, userID int64) (*entity.Cart, error)
+	FindCartItemsByCartID(ctx context.Context, cartID int64) (*[]entity.CartItem, error)
 
-	FindCartItemByID(id int64) (*entity.CartItem, error)
+	FindCartItemByID(ctx context.Context, id int64) (*entity.CartItem, error)
 
-	AddItemToCart(item *entity.CartItem) error
-	UpdateItemQuantity(itemID int64, quantity int) error
-	RemoveItemFromCart(itemID int64) error
+	AddItemToCart(ctx context.Context, item *entity.CartItem) error
+	UpdateItemQuantity(ctx context.Context, itemID int64, quantity int) error
+	RemoveItemFromCart(ctx context.Context, itemID int64) error
 }
@@ -1,15 +1,17 @@
 package internal
 
 import (
+	"context"
+
 	"github.com/hthinh24/go-store/services/cart/internal/dto/request"
 	"github.com/hthinh24/go-store/services/cart/internal/dto/response"
 )
 
 type CartService interface {
-	CreateCart(data *request.CreateCartRequest) (*response.CartResponse, error)
-	GetCartItemsByCartID(cartID int64) (*[]response.CartItemResponse, error)
-	GetCartByUserID(userID int64) (*response.CartResponse, error)
-	AddItemToCart(userID int64, item *request.AddItemRequest) error
-	UpdateItemQuantity(userID int64, itemID int64, quantity int) error
-	RemoveItemFromCart(userID int64, itemID int64) error
+	CreateCart(ctx context.Context, data *request.CreateCartRequest) (*response.CartResponse, error)
+	GetCartItemsByCartID(ctx context.Context, cartID int64) (*[]response.CartItemResponse, error)
+	GetCartByUserID(ctx context.Context, userID int64) (*response.CartResponse, error)
+	AddItemToCart(ctx context.Context, userID int64, item *request.AddItemRequest) error
+	UpdateItemQuantity(ctx context.Context, userID int64, itemID int64, quantity int) error
+	RemoveItemFromCart(ctx context.Context, userID int64, itemID int64) error
 }
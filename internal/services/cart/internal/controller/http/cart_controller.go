@@ -28,12 +28,12 @@ func (c *CartController) CreateCart() func(c *gin.Context) {
 	return func(ctx *gin.Context) {
 		var createCartRequest request.CreateCartRequest
 		if err := ctx.ShouldBindJSON(&createCartRequest); err != nil {
-			c.logger.Error("Invalid create cart request", "error", err)
+			c.logger.WithContext(ctx.Request.Context()).Error("Invalid create cart request", "error", err)
 			ctx.JSON(http.StatusBadRequest, rest.NewErrorResponse(rest.BadRequestError, "Invalid input"))
 			return
 		}
 
-		cart, err := c.cartService.CreateCart(&createCartRequest)
+		cart, err := c.cartService.CreateCart(ctx.Request.Context(), &createCartRequest)
 		if err != nil {
 			c.handleCartError(ctx, err)
 			return
@@ -51,7 +51,7 @@ func (c *CartController) GetCartItemsByUserID() func(c *gin.Context) {
 			return
 		}
 
-		cartItems, err := c.cartService.GetCartByUserID(userID)
+		cartItems, err := c.cartService.GetCartByUserID(ctx.Request.Context(), userID)
 		if err != nil {
 			c.handleCartError(ctx, err)
 			return
@@ -75,7 +75,7 @@ func (c *CartController) AddItemToCart() func(c *gin.Context) {
 			return
 		}
 
-		if err := c.cartService.AddItemToCart(userID, &item); err != nil {
+		if err := c.cartService.AddItemToCart(ctx.Request.Context(), userID, &item); err != nil {
 			c.handleCartError(ctx, err)
 			return
 		}
@@ -98,7 +98,7 @@ func (c *CartController) UpdateItemQuantity() func(c *gin.Context) {
 			return
 		}
 
-		if err := c.cartService.UpdateItemQuantity(userID, item.ItemID, item.Quantity); err != nil {
+		if err := c.cartService.UpdateItemQuantity(ctx.Request.Context(), userID, item.ItemID, item.Quantity); err != nil {
 			c.handleCartError(ctx, err)
 			return
 		}
@@ -122,7 +122,7 @@ func (c *CartController) RemoveItemFromCart() func(c *gin.Context) {
 			return
 		}
 
-		if err := c.cartService.RemoveItemFromCart(userID, int64(itemIDInt)); err != nil {
+		if err := c.cartService.RemoveItemFromCart(ctx.Request.Context(), userID, int64(itemIDInt)); err != nil {
 			c.handleCartError(ctx, err)
 			return
 		}
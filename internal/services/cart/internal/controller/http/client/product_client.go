@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"strconv"
 	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/middleware/requestid"
 )
 
 type ProductClient interface {
@@ -18,11 +20,12 @@ type productClient struct {
 	httpClient *http.Client
 }
 
-func NewProductClient(baseURL string) ProductClient {
+func NewProductClient(baseURL string, transport http.RoundTripper) ProductClient {
 	return &productClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Transport: transport,
+			Timeout:   10 * time.Second,
 		},
 	}
 }
@@ -50,6 +53,7 @@ func (c *productClient) GetProductSKUByID(ctx context.Context, productSKUID int6
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
+	requestid.Inject(httpReq)
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
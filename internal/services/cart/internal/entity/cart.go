@@ -6,7 +6,7 @@ import (
 
 type Cart struct {
 	entity.BaseEntity
-	UserID int64  `json:"user_id" gorm:"column:user_id;not null"`
+	UserID int64  `json:"user_id" gorm:"column:user_id;unique;not null"`
 	Status string `json:"status" gorm:"column:status;not null;default:ACTIVE"`
 }
 
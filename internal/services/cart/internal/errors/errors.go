@@ -4,6 +4,7 @@ import "errors"
 
 var (
 	ErrCartNotFound          = errors.New("cart not found")
+	ErrCartAlreadyExists     = errors.New("cart already exists")
 	ErrCartItemNotFound      = errors.New("cart item not found")
 	ErrUnauthorized          = errors.New("unauthorized access to cart")
 	ErrProductSKUNotFound    = errors.New("product sku not found")
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"errors"
 	"github.com/hthinh24/go-store/internal/pkg/logger"
 	"github.com/hthinh24/go-store/services/cart/internal/entity"
@@ -20,90 +21,94 @@ func NewCartRepository(logger logger.Logger, db *gorm.DB) *CartRepository {
 	}
 }
 
-func (c *CartRepository) CreateCart(cart *entity.Cart) error {
-	c.logger.Info("Creating cart", "userID", cart.UserID)
+func (c *CartRepository) CreateCart(ctx context.Context, cart *entity.Cart) error {
+	c.logger.WithContext(ctx).Info("Creating cart", "userID", cart.UserID)
 
-	if err := c.db.Create(cart).Error; err != nil {
-		c.logger.Error("Failed to create cart", "userID", cart.UserID, "error", err)
+	if err := c.db.WithContext(ctx).Create(cart).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.logger.WithContext(ctx).Info("Cart already exists", "userID", cart.UserID)
+			return customErr.ErrCartAlreadyExists
+		}
+		c.logger.WithContext(ctx).Error("Failed to create cart", "userID", cart.UserID, "error", err)
 		return err
 	}
 
-	c.logger.Info("Successfully created cart", "cartID", cart.ID, "userID", cart.UserID)
+	c.logger.WithContext(ctx).Info("Successfully created cart", "cartID", cart.ID, "userID", cart.UserID)
 	return nil
 }
 
-func (c *CartRepository) FindCartByUserID(userID int64) (*entity.Cart, error) {
-	c.logger.Info("Getting cart by user ID", "userID", userID)
+func (c *CartRepository) FindCartByUserID(ctx context.Context, userID int64) (*entity.Cart, error) {
+	c.logger.WithContext(ctx).Info("Getting cart by user ID", "userID", userID)
 
 	var cart entity.Cart
-	if err := c.db.Where("user_id = ?", userID).First(&cart).Error; err != nil {
+	if err := c.db.WithContext(ctx).Where("user_id = ?", userID).First(&cart).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.logger.Info("No cart found for user ID", "userID", userID)
+			c.logger.WithContext(ctx).Info("No cart found for user ID", "userID", userID)
 			return nil, customErr.ErrCartNotFound
 		}
-		c.logger.Error("Failed to get cart by user ID", "userID", userID, "error", err)
+		c.logger.WithContext(ctx).Error("Failed to get cart by user ID", "userID", userID, "error", err)
 		return nil, err
 	}
 
 	return &cart, nil
 }
 
-func (c *CartRepository) FindCartItemsByCartID(cartID int64) (*[]entity.CartItem, error) {
-	c.logger.Info("Finding cart items by cart ID, ", "cartID: ", cartID)
+func (c *CartRepository) FindCartItemsByCartID(ctx context.Context, cartID int64) (*[]entity.CartItem, error) {
+	c.logger.WithContext(ctx).Info("Finding cart items by cart ID, ", "cartID: ", cartID)
 
 	var items []entity.CartItem
-	if err := c.db.Where("cart_id = ?", cartID).Find(&items).Error; err != nil {
-		c.logger.Error("Failed to find cart items", "userID", cartID, "error", err)
+	if err := c.db.WithContext(ctx).Where("cart_id = ?", cartID).Find(&items).Error; err != nil {
+		c.logger.WithContext(ctx).Error("Failed to find cart items", "userID", cartID, "error", err)
 		return nil, err
 	}
 	if len(items) == 0 {
-		c.logger.Info("No items found for cart ID", "userID", cartID)
+		c.logger.WithContext(ctx).Info("No items found for cart ID", "userID", cartID)
 		return nil, nil
 	}
 
 	return &items, nil
 }
 
-func (c *CartRepository) FindCartItemByID(id int64) (*entity.CartItem, error) {
-	c.logger.Info("Finding cart item by ID", "itemID", id)
+func (c *CartRepository) FindCartItemByID(ctx context.Context, id int64) (*entity.CartItem, error) {
+	c.logger.WithContext(ctx).Info("Finding cart item by ID", "itemID", id)
 
 	var item entity.CartItem
-	if err := c.db.Where("id = ?", id).First(&item).Error; err != nil {
+	if err := c.db.WithContext(ctx).Where("id = ?", id).First(&item).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.logger.Info("No cart item found for ID", "itemID", id)
+			c.logger.WithContext(ctx).Info("No cart item found for ID", "itemID", id)
 			return nil, customErr.ErrCartItemNotFound
 		}
-		c.logger.Error("Failed to find cart item by ID", "itemID", id, "error", err)
+		c.logger.WithContext(ctx).Error("Failed to find cart item by ID", "itemID", id, "error", err)
 		return nil, err
 	}
 
 	return &item, nil
 }
 
-func (c *CartRepository) AddItemToCart(item *entity.CartItem) error {
-	c.logger.Info("Adding item to cart", "item", item)
+func (c *CartRepository) AddItemToCart(ctx context.Context, item *entity.CartItem) error {
+	c.logger.WithContext(ctx).Info("Adding item to cart", "item", item)
 
-	err := c.db.Create(item).Error
+	err := c.db.WithContext(ctx).Create(item).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
-			c.logger.Error("Duplicate cart item", "item", item, "error", err)
+			c.logger.WithContext(ctx).Error("Duplicate cart item", "item", item, "error", err)
 			return customErr.ErrCartItemAlreadyExists
 		}
-		c.logger.Error("Failed to add item to cart", "item", item, "error", err)
+		c.logger.WithContext(ctx).Error("Failed to add item to cart", "item", item, "error", err)
 		return err
 	}
 
 	return nil
 }
 
-func (c *CartRepository) UpdateItemQuantity(itemID int64, quantity int) error {
-	c.logger.Info("Updating item quantity", "itemID", itemID, "quantity", quantity)
+func (c *CartRepository) UpdateItemQuantity(ctx context.Context, itemID int64, quantity int) error {
+	c.logger.WithContext(ctx).Info("Updating item quantity", "itemID", itemID, "quantity", quantity)
 
-	result := c.db.Table(entity.CartItem{}.TableName()).
+	result := c.db.WithContext(ctx).Table(entity.CartItem{}.TableName()).
 		Where("id = ?", itemID).
 		Update("quantity", quantity)
 	if result.Error != nil {
-		c.logger.Error("Failed to update item quantity", "itemID", itemID, "error", result.Error)
+		c.logger.WithContext(ctx).Error("Failed to update item quantity", "itemID", itemID, "error", result.Error)
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
@@ -112,14 +117,14 @@ func (c *CartRepository) UpdateItemQuantity(itemID int64, quantity int) error {
 	return nil
 }
 
-func (c *CartRepository) RemoveItemFromCart(itemID int64) error {
-	c.logger.Info("Removing item from cart", "itemID", itemID)
+func (c *CartRepository) RemoveItemFromCart(ctx context.Context, itemID int64) error {
+	c.logger.WithContext(ctx).Info("Removing item from cart", "itemID", itemID)
 
-	result := c.db.Table(entity.CartItem{}.TableName()).
+	result := c.db.WithContext(ctx).Table(entity.CartItem{}.TableName()).
 		Where("id = ?", itemID).
 		Delete(&entity.CartItem{})
 	if result.Error != nil {
-		c.logger.Error("Failed to remove item from cart", "itemID", itemID, "error", result.Error)
+		c.logger.WithContext(ctx).Error("Failed to remove item from cart", "itemID", itemID, "error", result.Error)
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
@@ -29,13 +29,15 @@ func NewCartService(logger logger.Logger, cartRepository internal.CartRepository
 	}
 }
 
-func (c *cartService) CreateCart(data *request.CreateCartRequest) (*response.CartResponse, error) {
-	c.logger.Info("Creating cart for user ID: ", data.UserID)
+func (c *cartService) CreateCart(ctx context.Context, data *request.CreateCartRequest) (*response.CartResponse, error) {
+	logger := c.logger.WithContext(ctx)
+
+	logger.Info("Creating cart for user ID: ", data.UserID)
 
 	// Check if cart already exists for this user
-	existingCart, err := c.cartRepository.FindCartByUserID(data.UserID)
+	existingCart, err := c.cartRepository.FindCartByUserID(ctx, data.UserID)
 	if err == nil && existingCart != nil {
-		c.logger.Info("Cart already exists for user: ", data.UserID, ", cartID: ", existingCart.ID)
+		logger.Info("Cart already exists for user: ", data.UserID, ", cartID: ", existingCart.ID)
 		// Return existing cart with empty items
 		return c.createCartResponse(existingCart, &[]response.CartItemResponse{}), nil
 	}
@@ -44,25 +46,36 @@ func (c *cartService) CreateCart(data *request.CreateCartRequest) (*response.Car
 	cart := c.createCartEntity(data.UserID)
 
 	// Pass entity to repository for persistence
-	if err := c.cartRepository.CreateCart(cart); err != nil {
-		c.logger.Error("Failed to create cart for user: ", data.UserID, ", error: ", err)
+	if err := c.cartRepository.CreateCart(ctx, cart); err != nil {
+		// A concurrent registration of the same user created the cart after the check
+		if err == errors.ErrCartAlreadyExists {
+			existingCart, err := c.cartRepository.FindCartByUserID(ctx, data.UserID)
+			if err != nil {
+				return nil, err
+			}
+			logger.Info("Cart already exists for user: ", data.UserID, ", cartID: ", existingCart.ID)
+			return c.createCartResponse(existingCart, &[]response.CartItemResponse{}), nil
+		}
+		logger.Error("Failed to create cart for user: ", data.UserID, ", error: ", err)
 		return nil, err
 	}
 
-	c.logger.Info("Successfully created cart for user: ", data.UserID, ", cartID: ", cart.ID)
+	logger.Info("Successfully created cart for user: ", data.UserID, ", cartID: ", cart.ID)
 	return c.createCartResponse(cart, &[]response.CartItemResponse{}), nil
 }
 
-func (c *cartService) GetCartItemsByCartID(cartID int64) (*[]response.CartItemResponse, error) {
-	items, err := c.cartRepository.FindCartItemsByCartID(cartID)
+func (c *cartService) GetCartItemsByCartID(ctx context.Context, cartID int64) (*[]response.CartItemResponse, error) {
+	logger := c.logger.WithContext(ctx)
+
+	items, err := c.cartRepository.FindCartItemsByCartID(ctx, cartID)
 	if err != nil {
-		c.logger.Error("Failed to find cart items by cart ID: ", cartID, ", error: ", err)
+		logger.Error("Failed to find cart items by cart ID: ", cartID, ", error: ", err)
 		return nil, err
 	}
 
 	// If no items found, return empty cart items response
 	if items == nil || len(*items) == 0 {
-		c.logger.Info("No items found in cart: ", cartID)
+		logger.Info("No items found in cart: ", cartID)
 		return &[]response.CartItemResponse{}, nil
 	}
 
@@ -75,14 +88,16 @@ func (c *cartService) GetCartItemsByCartID(cartID int64) (*[]response.CartItemRe
 	return &cartItemResponses, nil
 }
 
-func (c *cartService) GetCartByUserID(userID int64) (*response.CartResponse, error) {
-	cart, err := c.cartRepository.FindCartByUserID(userID)
+func (c *cartService) GetCartByUserID(ctx context.Context, userID int64) (*response.CartResponse, error) {
+	logger := c.logger.WithContext(ctx)
+
+	cart, err := c.cartRepository.FindCartByUserID(ctx, userID)
 	if err != nil {
-		c.logger.Error("Failed to get cart by user ID: ", userID, ", error: ", err)
+		logger.Error("Failed to get cart by user ID: ", userID, ", error: ", err)
 		return nil, err
 	}
 
-	cartItemsResponse, err := c.GetCartItemsByCartID(cart.ID)
+	cartItemsResponse, err := c.GetCartItemsByCartID(ctx, cart.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -90,84 +105,90 @@ func (c *cartService) GetCartByUserID(userID int64) (*response.CartResponse, err
 	return c.createCartResponse(cart, cartItemsResponse), nil
 }
 
-func (c *cartService) AddItemToCart(userID int64, newItem *request.AddItemRequest) error {
-	c.logger.Info("Adding item to cart for user ID: ", userID)
+func (c *cartService) AddItemToCart(ctx context.Context, userID int64, newItem *request.AddItemRequest) error {
+	logger := c.logger.WithContext(ctx)
+
+	logger.Info("Adding item to cart for user ID: ", userID)
 
 	// 1. Find the cart by user ID
-	cart, err := c.cartRepository.FindCartByUserID(userID)
+	cart, err := c.cartRepository.FindCartByUserID(ctx, userID)
 	if err != nil {
-		c.logger.Error("Failed to find cart by user ID: ", userID, ", error: ", err)
+		logger.Error("Failed to find cart by user ID: ", userID, ", error: ", err)
 		return err
 	}
 
 	// 2. Get latest price & status of the product SKU using client
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	clientCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	productSKUResponse, err := c.productClient.GetProductSKUByID(ctx, newItem.ProductSKUID)
+	productSKUResponse, err := c.productClient.GetProductSKUByID(clientCtx, newItem.ProductSKUID)
 	if err != nil {
-		c.logger.Error("Failed to get product SKU details: ", newItem.ProductSKUID, ", error: ", err)
+		logger.Error("Failed to get product SKU details: ", newItem.ProductSKUID, ", error: ", err)
 		return errors.ErrProductSKUNotFound
 	}
 
-	c.logger.Info("Product SKU status: ", productSKUResponse.SKU)
+	logger.Info("Product SKU status: ", productSKUResponse.SKU)
 	if productSKUResponse.Status != constants.ProductStatusActive {
-		c.logger.Error("Product SKU is not active: ", newItem.ProductSKUID, ", status: ", productSKUResponse.Status)
+		logger.Error("Product SKU is not active: ", newItem.ProductSKUID, ", status: ", productSKUResponse.Status)
 		return errors.ErrProductSKUNotActive
 	}
 
 	// 3. Store the newItem in the cart with latest price
 	cartItemEntity := c.createCartItemEntity(cart.ID, productSKUResponse)
-	if err := c.cartRepository.AddItemToCart(cartItemEntity); err != nil {
-		c.logger.Error("Failed to add item to cart for user: ", userID, ", error: ", err)
+	if err := c.cartRepository.AddItemToCart(ctx, cartItemEntity); err != nil {
+		logger.Error("Failed to add item to cart for user: ", userID, ", error: ", err)
 		return err
 	}
 
 	return nil
 }
 
-func (c *cartService) UpdateItemQuantity(userID int64, itemID int64, quantity int) error {
-	c.logger.Info("Updating item quantity in cart for user: ", userID, ", itemID: ", itemID, ", quantity: ", quantity)
+func (c *cartService) UpdateItemQuantity(ctx context.Context, userID int64, itemID int64, quantity int) error {
+	logger := c.logger.WithContext(ctx)
+
+	logger.Info("Updating item quantity in cart for user: ", userID, ", itemID: ", itemID, ", quantity: ", quantity)
 
 	// 1. Find the cart by user ID
-	if _, err := c.cartRepository.FindCartByUserID(userID); err != nil {
-		c.logger.Error("Failed to find cart by user ID: ", userID, ", error: ", err)
+	if _, err := c.cartRepository.FindCartByUserID(ctx, userID); err != nil {
+		logger.Error("Failed to find cart by user ID: ", userID, ", error: ", err)
 		return err
 	}
 
 	// 2. Find the cart item by item ID
-	if _, err := c.cartRepository.FindCartItemByID(itemID); err != nil {
-		c.logger.Error("Failed to find cart item by ID: ", itemID, ", error: ", err)
+	if _, err := c.cartRepository.FindCartItemByID(ctx, itemID); err != nil {
+		logger.Error("Failed to find cart item by ID: ", itemID, ", error: ", err)
 		return err
 	}
 
 	// 3. Update the item quantity in the cart
-	if err := c.cartRepository.UpdateItemQuantity(itemID, quantity); err != nil {
-		c.logger.Error("Failed to update cart item quantity: ", itemID, ", error: ", err)
+	if err := c.cartRepository.UpdateItemQuantity(ctx, itemID, quantity); err != nil {
+		logger.Error("Failed to update cart item quantity: ", itemID, ", error: ", err)
 		return err
 	}
 
 	return nil
 }
 
-func (c *cartService) RemoveItemFromCart(userID int64, itemID int64) error {
-	c.logger.Info("Removing item from cart for user: ", userID, ", itemID: ", itemID)
+func (c *cartService) RemoveItemFromCart(ctx context.Context, userID int64, itemID int64) error {
+	logger := c.logger.WithContext(ctx)
+
+	logger.Info("Removing item from cart for user: ", userID, ", itemID: ", itemID)
 
 	// 1. Find the cart by user ID
-	if _, err := c.cartRepository.FindCartByUserID(userID); err != nil {
-		c.logger.Error("Failed to find cart by user ID: ", userID, ", error: ", err)
+	if _, err := c.cartRepository.FindCartByUserID(ctx, userID); err != nil {
+		logger.Error("Failed to find cart by user ID: ", userID, ", error: ", err)
 		return err
 	}
 
 	// 2. Find the cart item by item ID
-	if _, err := c.cartRepository.FindCartItemByID(itemID); err != nil {
-		c.logger.Error("Failed to find cart item by ID: ", itemID, ", error: ", err)
+	if _, err := c.cartRepository.FindCartItemByID(ctx, itemID); err != nil {
+		logger.Error("Failed to find cart item by ID: ", itemID, ", error: ", err)
 		return err
 	}
 
 	// 3. Remove the item from the cart
-	if err := c.cartRepository.RemoveItemFromCart(itemID); err != nil {
-		c.logger.Error("Failed to remove item from cart: ", itemID, ", error: ", err)
+	if err := c.cartRepository.RemoveItemFromCart(ctx, itemID); err != nil {
+		logger.Error("Failed to remove item from cart: ", itemID, ", error: ", err)
 		return err
 	}
 
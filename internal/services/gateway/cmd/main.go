@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
 	"github.com/hthinh24/go-store/services/gateway/internal/config"
+	"github.com/hthinh24/go-store/services/gateway/internal/ratelimit"
 	"github.com/hthinh24/go-store/services/gateway/internal/router"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/health"
 	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/metrics"
+	"github.com/hthinh24/go-store/internal/pkg/tracing"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -21,11 +29,37 @@ func main() {
 	// Initialize logger
 	appLogger := logger.WithComponent(cfg.Log.Level, "GATEWAY")
 
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(cfg.ServiceName, cfg.Tracing)
+	if err != nil {
+		log.Fatal("Failed to initialize tracing:", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Initialize health checks
+	appHealth := health.New(cfg.Health)
+
+	// Initialize rate limiter
+	limiter, err := initRateLimiter(cfg, appHealth)
+	if err != nil {
+		log.Fatal("Failed to initialize rate limiter:", err)
+	}
+
+	// Initialize metrics
+	appMetrics := metrics.New(cfg.ServiceName)
+
 	// Create gateway
-	gateway := router.NewGateway(cfg, appLogger)
+	gateway, err := router.NewGateway(cfg, appLogger, limiter, appMetrics, appHealth)
+	if err != nil {
+		log.Fatal("Failed to create gateway:", err)
+	}
+	defer gateway.Close()
 
 	// Setup gin router
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.RateLimit.TrustedProxies); err != nil {
+		log.Fatal("Invalid trusted proxies:", err)
+	}
 
 	// Setup routes
 	gateway.SetupRoutes(r)
@@ -36,3 +70,38 @@ func main() {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+func initRateLimiter(cfg *config.GatewayConfig, appHealth *health.Health) (ratelimit.Limiter, error) {
+	if cfg.RateLimit.Backend != config.RateLimitBackendRedis {
+		return ratelimit.NewMemoryLimiter(), nil
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         cfg.Redis.GetAddress(),
+		Password:     cfg.Redis.Password,
+		DB:           cfg.Redis.DB,
+		PoolSize:     cfg.Redis.PoolSize,
+		MinIdleConns: cfg.Redis.MinIdleConns,
+		DialTimeout:  time.Duration(cfg.Redis.DialTimeout) * time.Second,
+		ReadTimeout:  time.Duration(cfg.Redis.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(cfg.Redis.WriteTimeout) * time.Second,
+	})
+
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	// The limiter fails open, an unreachable redis still makes the gateway not ready
+	appHealth.AddCheck("redis", func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+
+	return ratelimit.NewRedisLimiter(client, cfg.RateLimit.KeyPrefix), nil
+}
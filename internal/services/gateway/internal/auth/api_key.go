@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+)
+
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyVerifier verifies API keys with the identity service and caches the result briefly,
+// keys are opaque so they cannot be verified locally. Rejected keys are cached too, so
+// guessing keys does not cost the identity service a lookup per attempt.
+type APIKeyVerifier struct {
+	logger      logger.Logger
+	remote      *IdentityClient
+	cache       *claimsCache
+	ttl         time.Duration
+	rejected    *claimsCache
+	rejectedTTL time.Duration
+}
+
+func NewAPIKeyVerifier(logger logger.Logger, remote *IdentityClient, cfg config.Auth) *APIKeyVerifier {
+	return &APIKeyVerifier{
+		logger:      logger,
+		remote:      remote,
+		cache:       newClaimsCache(cfg.CacheSize, cfg.GetAPIKeyCacheTTL()),
+		ttl:         cfg.GetAPIKeyCacheTTL(),
+		rejected:    newClaimsCache(cfg.CacheSize, cfg.GetAPIKeyNegativeCacheTTL()),
+		rejectedTTL: cfg.GetAPIKeyNegativeCacheTTL(),
+	}
+}
+
+// Verify returns the user info of an active key, its permissions are the scopes of the key
+func (v *APIKeyVerifier) Verify(ctx context.Context, key string) (*VerifyResponse, error) {
+	entryKey := cacheKey(key)
+	if cached, ok := v.cache.Get(entryKey); ok {
+		return cached, nil
+	}
+	if _, ok := v.rejected.Get(entryKey); ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	verifyResponse, err := v.remote.VerifyAPIKey(ctx, key)
+	if err != nil {
+		v.logger.WithContext(ctx).Warn("API key verification failed, error: ", err)
+		// Only remember answers, an unavailable identity service must not reject valid keys
+		if errors.Is(err, ErrCredentialRejected) {
+			v.rejected.Set(entryKey, nil, time.Now().Add(v.rejectedTTL))
+		}
+		return nil, ErrInvalidAPIKey
+	}
+	verifyResponse.AuthType = AuthTypeAPIKey
+
+	v.cache.Set(entryKey, verifyResponse, time.Now().Add(v.ttl))
+	return verifyResponse, nil
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+)
+
+// newIdentityStub answers API key verifications with the status, counting the calls
+func newIdentityStub(t *testing.T, status *atomic.Int32, calls *atomic.Int32) *IdentityClient {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+		if status.Load() == http.StatusOK {
+			_, _ = w.Write([]byte(`{"user_id":"1","roles":[],"permissions":["product:read"]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return NewIdentityClient(server.URL, nil)
+}
+
+func newTestAPIKeyVerifier(remote *IdentityClient) *APIKeyVerifier {
+	cfg := config.Auth{}
+	cfg.SetDefaults()
+	return NewAPIKeyVerifier(logger.NewAppLogger("development"), remote, cfg)
+}
+
+func TestAPIKeyVerifierCachesRejectedKeys(t *testing.T) {
+	var status, calls atomic.Int32
+	status.Store(http.StatusUnauthorized)
+	verifier := newTestAPIKeyVerifier(newIdentityStub(t, &status, &calls))
+
+	for range 3 {
+		if _, err := verifier.Verify(context.Background(), "gsk_guess"); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Fatalf("Verify error = %v, want %v", err, ErrInvalidAPIKey)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("identity calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestAPIKeyVerifierDoesNotCacheFailures(t *testing.T) {
+	var status, calls atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	verifier := newTestAPIKeyVerifier(newIdentityStub(t, &status, &calls))
+
+	if _, err := verifier.Verify(context.Background(), "gsk_valid"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("Verify error = %v, want %v", err, ErrInvalidAPIKey)
+	}
+
+	// The identity service is back, the key works without waiting for a cache entry to expire
+	status.Store(http.StatusOK)
+	verified, err := verifier.Verify(context.Background(), "gsk_valid")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if verified.UserID != "1" || verified.AuthType != AuthTypeAPIKey {
+		t.Errorf("verified = %+v", verified)
+	}
+
+	if _, err := verifier.Verify(context.Background(), "gsk_valid"); err != nil {
+		t.Fatalf("cached Verify: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("identity calls = %d, want 2", calls.Load())
+	}
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// claimsCache is a bounded LRU cache of verified tokens, entries expire with their token
+type claimsCache struct {
+	mu       sync.Mutex
+	capacity int
+	maxTTL   time.Duration
+	entries  map[string]*list.Element
+	order    *list.List // front is the most recently used entry
+}
+
+type cacheEntry struct {
+	key       string
+	value     *VerifyResponse
+	expiresAt time.Time
+}
+
+func newClaimsCache(capacity int, maxTTL time.Duration) *claimsCache {
+	return &claimsCache{
+		capacity: capacity,
+		maxTTL:   maxTTL,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *claimsCache) Get(key string) (*VerifyResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set caches a value until expiresAt, capped by the cache max TTL
+func (c *claimsCache) Set(key string, value *VerifyResponse, expiresAt time.Time) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	if maxExpiresAt := time.Now().Add(c.maxTTL); c.maxTTL > 0 && expiresAt.After(maxExpiresAt) {
+		expiresAt = maxExpiresAt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.entries[key]; exists {
+		entry := element.Value.(*cacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, expiresAt: expiresAt})
+
+	// Evict the least recently used entries once over capacity
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *claimsCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+}
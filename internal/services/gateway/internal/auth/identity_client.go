@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/middleware/requestid"
+)
+
+// Credential types forwarded to upstream services, so they can refuse API keys on routes that
+// manage the account and its credentials
+const (
+	AuthTypeToken  = "token"
+	AuthTypeAPIKey = "api_key"
+)
+
+// ErrCredentialRejected is returned when the identity service answered that the credential is
+// not valid, as opposed to failing to answer
+var ErrCredentialRejected = errors.New("credential rejected by identity service")
+
+// VerifyResponse is the user info forwarded to upstream services
+type VerifyResponse struct {
+	UserID      string   `json:"user_id"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	// AuthType is set by the gateway from the credential it verified, never by the identity service
+	AuthType string `json:"-"`
+}
+
+// IdentityClient calls the identity service to verify credentials remotely, access tokens for
+// revocation checks and API keys, which can only be verified there
+type IdentityClient struct {
+	verifyURL  string
+	httpClient *http.Client
+}
+
+func NewIdentityClient(verifyURL string, transport http.RoundTripper) *IdentityClient {
+	return &IdentityClient{
+		verifyURL: verifyURL,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   5 * time.Second,
+		},
+	}
+}
+
+func (c *IdentityClient) Verify(ctx context.Context, token string) (*VerifyResponse, error) {
+	return c.verify(ctx, "Bearer "+token)
+}
+
+func (c *IdentityClient) VerifyAPIKey(ctx context.Context, key string) (*VerifyResponse, error) {
+	return c.verify(ctx, "ApiKey "+key)
+}
+
+func (c *IdentityClient) verify(ctx context.Context, authorization string) (*VerifyResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.verifyURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", authorization)
+	requestid.Inject(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%w, status: %d", ErrCredentialRejected, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth verification failed with status: %d", resp.StatusCode)
+	}
+
+	var verifyResponse VerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&verifyResponse); err != nil {
+		return nil, err
+	}
+
+	return &verifyResponse, nil
+}
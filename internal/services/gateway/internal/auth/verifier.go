@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hthinh24/go-store/internal/pkg/jwks"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrRevokedToken = errors.New("token rejected by identity service")
+)
+
+// Claims mirrors the access token claims issued by the identity service
+type Claims struct {
+	UserID      int64    `json:"user_id"`
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	jwt.RegisteredClaims
+}
+
+// Verifier validates access tokens locally and caches the result until the token expires
+type Verifier struct {
+	logger logger.Logger
+	keys   *jwks.Client
+	cache  *claimsCache
+	remote *IdentityClient // optional, only used for revocation checks
+}
+
+// NewVerifier creates a token verifier, remote may be nil when revocation checks are disabled
+func NewVerifier(logger logger.Logger, keys *jwks.Client, cfg config.Auth, remote *IdentityClient) *Verifier {
+	return &Verifier{
+		logger: logger,
+		keys:   keys,
+		cache:  newClaimsCache(cfg.CacheSize, cfg.GetCacheMaxTTL()),
+		remote: remote,
+	}
+}
+
+// Verify returns the user info of a valid token
+func (v *Verifier) Verify(ctx context.Context, token string) (*VerifyResponse, error) {
+	key := cacheKey(token)
+	if cached, ok := v.cache.Get(key); ok {
+		return cached, nil
+	}
+
+	claims, err := v.parseToken(token)
+	if err != nil {
+		v.logger.WithContext(ctx).Warn("Local token verification failed, error: ", err)
+		return nil, ErrInvalidToken
+	}
+
+	// Local verification cannot see revocations, ask the identity service on cache misses
+	if v.remote != nil {
+		if _, err := v.remote.Verify(ctx, token); err != nil {
+			v.logger.WithContext(ctx).Warn("Remote token verification failed, user_id: ", claims.UserID, ", error: ", err)
+			return nil, ErrRevokedToken
+		}
+	}
+
+	verifyResponse := &VerifyResponse{
+		UserID:      strconv.FormatInt(claims.UserID, 10),
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		AuthType:    AuthTypeToken,
+	}
+
+	v.cache.Set(key, verifyResponse, claims.ExpiresAt.Time)
+	return verifyResponse, nil
+}
+
+func (v *Verifier) parseToken(tokenString string) (*Claims, error) {
+	// Only accept asymmetric algorithms, the key of the kid header decides which one
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, v.keys.Keyfunc(), jwt.WithValidMethods(jwks.ValidMethods()))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrTokenMalformed
+	}
+
+	// Tokens without expiry could be cached and replayed forever
+	if claims.ExpiresAt == nil {
+		return nil, jwt.ErrTokenExpired
+	}
+
+	return claims, nil
+}
+
+// cacheKey hashes the token so the cache never holds usable credentials
+func cacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
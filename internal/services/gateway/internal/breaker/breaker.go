@@ -0,0 +1,175 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker is a circuit breaker for a single upstream service.
+// Closed: requests pass, FailureThreshold consecutive failures open the circuit.
+// Open: requests fail fast until OpenTimeout has elapsed, then the circuit is half-open.
+// Half-open: up to HalfOpenRequests probes pass, they all have to succeed to close the
+// circuit again, any failure opens it.
+type Breaker struct {
+	mu       sync.Mutex
+	name     string
+	settings config.Breaker
+
+	state      State
+	generation uint64 // incremented on every state change, stale results are ignored
+	failures   int
+	successes  int
+	inFlight   int
+	openedAt   time.Time
+	changedAt  time.Time
+}
+
+// Snapshot is the state of a breaker exposed to operators
+type Snapshot struct {
+	Service             string     `json:"service"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	FailureThreshold    int        `json:"failure_threshold"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+	ChangedAt           time.Time  `json:"changed_at"`
+}
+
+func NewBreaker(name string, settings config.Breaker) *Breaker {
+	return &Breaker{
+		name:      name,
+		settings:  settings,
+		state:     StateClosed,
+		changedAt: time.Now(),
+	}
+}
+
+// Allow checks if a request may pass, the returned generation must be passed to Record
+func (b *Breaker) Allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.settings.GetOpenTimeout() {
+		b.setState(StateHalfOpen, now)
+	}
+
+	switch b.state {
+	case StateOpen:
+		return 0, ErrCircuitOpen
+	case StateHalfOpen:
+		if b.inFlight >= b.settings.HalfOpenRequests {
+			return 0, ErrCircuitOpen
+		}
+		b.inFlight++
+	}
+
+	return b.generation, nil
+}
+
+// Record reports the outcome of a request allowed in the given generation
+func (b *Breaker) Record(generation uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	now := time.Now()
+	switch b.state {
+	case StateClosed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		b.inFlight--
+		if !success {
+			b.failures++
+			b.setState(StateOpen, now)
+			return
+		}
+		b.successes++
+		if b.successes >= b.settings.HalfOpenRequests {
+			b.setState(StateClosed, now)
+		}
+	}
+}
+
+// Release gives back a half-open slot without recording an outcome
+func (b *Breaker) Release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation == b.generation && b.state == StateHalfOpen {
+		b.inFlight--
+	}
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snapshot := Snapshot{
+		Service:             b.name,
+		State:               b.state.String(),
+		ConsecutiveFailures: b.failures,
+		FailureThreshold:    b.settings.FailureThreshold,
+		ChangedAt:           b.changedAt,
+	}
+
+	if b.state == StateOpen {
+		openedAt := b.openedAt
+		retryAt := openedAt.Add(b.settings.GetOpenTimeout())
+		snapshot.OpenedAt = &openedAt
+		snapshot.RetryAt = &retryAt
+	}
+
+	return snapshot
+}
+
+func (b *Breaker) setState(state State, now time.Time) {
+	b.state = state
+	b.generation++
+	b.successes = 0
+	b.inFlight = 0
+	b.changedAt = now
+
+	switch state {
+	case StateOpen:
+		b.openedAt = now
+	case StateClosed:
+		b.failures = 0
+	}
+}
@@ -0,0 +1,186 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+	"github.com/hthinh24/go-store/services/gateway/internal/upstream"
+)
+
+func newTestBreaker() *Breaker {
+	return NewBreaker("product", config.Breaker{FailureThreshold: 3, OpenTimeout: 30, HalfOpenRequests: 2})
+}
+
+// record lets a request through and reports its outcome
+func record(t *testing.T, b *Breaker, success bool) {
+	t.Helper()
+
+	generation, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	b.Record(generation, success)
+}
+
+// expireOpenTimeout moves the opening of the circuit back past its open timeout
+func expireOpenTimeout(b *Breaker) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openedAt = b.openedAt.Add(-b.settings.GetOpenTimeout())
+}
+
+func assertState(t *testing.T, b *Breaker, want State) {
+	t.Helper()
+
+	if got := b.Snapshot().State; got != want.String() {
+		t.Fatalf("state = %s, want %s", got, want)
+	}
+}
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	b := newTestBreaker()
+
+	record(t, b, false)
+	record(t, b, false)
+	record(t, b, true)
+	record(t, b, false)
+	record(t, b, false)
+	assertState(t, b, StateClosed)
+
+	record(t, b, false)
+	assertState(t, b, StateOpen)
+
+	if _, err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow error = %v, want %v", err, ErrCircuitOpen)
+	}
+	snapshot := b.Snapshot()
+	if snapshot.OpenedAt == nil || snapshot.RetryAt == nil || snapshot.RetryAt.Sub(*snapshot.OpenedAt) != 30*time.Second {
+		t.Errorf("snapshot = %+v, want the open and retry times", snapshot)
+	}
+}
+
+func TestBreakerHalfOpenCloses(t *testing.T) {
+	b := newTestBreaker()
+	for range 3 {
+		record(t, b, false)
+	}
+	expireOpenTimeout(b)
+
+	first, err := b.Allow()
+	if err != nil {
+		t.Fatalf("first probe: %v", err)
+	}
+	assertState(t, b, StateHalfOpen)
+	second, err := b.Allow()
+	if err != nil {
+		t.Fatalf("second probe: %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("probe over the limit error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	b.Record(first, true)
+	assertState(t, b, StateHalfOpen)
+	b.Record(second, true)
+	assertState(t, b, StateClosed)
+
+	if failures := b.Snapshot().ConsecutiveFailures; failures != 0 {
+		t.Errorf("consecutive failures = %d, want 0", failures)
+	}
+}
+
+func TestBreakerHalfOpenFailureReopens(t *testing.T) {
+	b := newTestBreaker()
+	for range 3 {
+		record(t, b, false)
+	}
+	expireOpenTimeout(b)
+
+	first, _ := b.Allow()
+	second, _ := b.Allow()
+	b.Record(first, false)
+	assertState(t, b, StateOpen)
+
+	// The other probe finishes after the circuit opened again, its result is stale
+	b.Record(second, true)
+	assertState(t, b, StateOpen)
+}
+
+func TestBreakerReleaseFreesProbe(t *testing.T) {
+	b := NewBreaker("product", config.Breaker{FailureThreshold: 1, OpenTimeout: 30, HalfOpenRequests: 1})
+	record(t, b, false)
+	expireOpenTimeout(b)
+
+	generation, err := b.Allow()
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	b.Release(generation)
+
+	record(t, b, true)
+	assertState(t, b, StateClosed)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransportRecordsOutcomes(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		err        error
+		wantFailed bool
+	}{
+		{"success", http.StatusOK, nil, false},
+		{"client error", http.StatusNotFound, nil, false},
+		{"server error", http.StatusInternalServerError, nil, true},
+		{"transport error", 0, errors.New("connection refused"), true},
+		{"canceled by the client", 0, context.Canceled, false},
+		{"no healthy instance", 0, upstream.ErrNoHealthyInstance, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker("product", config.Breaker{FailureThreshold: 1, OpenTimeout: 30, HalfOpenRequests: 1})
+			transport := NewTransport(b, roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &http.Response{StatusCode: tt.status, Body: http.NoBody}, nil
+			}))
+
+			req, _ := http.NewRequest(http.MethodGet, "http://product/api/v1/products", nil)
+			_, _ = transport.RoundTrip(req)
+
+			if failed := b.Snapshot().State == StateOpen.String(); failed != tt.wantFailed {
+				t.Errorf("recorded failure = %v, want %v", failed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestTransportFailsFastWhileOpen(t *testing.T) {
+	b := NewBreaker("product", config.Breaker{FailureThreshold: 1, OpenTimeout: 30, HalfOpenRequests: 1})
+	record(t, b, false)
+
+	calls := 0
+	transport := NewTransport(b, roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://product/api/v1/products", nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("RoundTrip error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if calls != 0 {
+		t.Errorf("upstream calls = %d, want 0", calls)
+	}
+}
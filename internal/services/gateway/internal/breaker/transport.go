@@ -0,0 +1,45 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hthinh24/go-store/services/gateway/internal/upstream"
+)
+
+// Transport fails fast while the circuit of its upstream is open.
+// Transport errors and 5xx responses count as failures. A request canceled by the
+// client or finding no healthy instance does not say anything about the upstream and is
+// not recorded, the pool already takes unhealthy instances out of rotation.
+type Transport struct {
+	breaker *Breaker
+	next    http.RoundTripper
+}
+
+func NewTransport(breaker *Breaker, next http.RoundTripper) *Transport {
+	return &Transport{
+		breaker: breaker,
+		next:    next,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	generation, err := t.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, upstream.ErrNoHealthyInstance) {
+			t.breaker.Release(generation)
+			return nil, err
+		}
+		t.breaker.Record(generation, false)
+		return nil, err
+	}
+
+	t.breaker.Record(generation, resp.StatusCode < http.StatusInternalServerError)
+	return resp, nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import "time"
+
+// Auth holds gateway token verification configuration
+type Auth struct {
+	// CacheSize is the maximum number of verified tokens kept in memory
+	CacheSize int `mapstructure:"cache_size"`
+	// CacheMaxTTL caps how long a verified token is cached, in seconds
+	CacheMaxTTL int `mapstructure:"cache_max_ttl"`
+	// RevocationCheck also asks the identity service about tokens missing from the cache, it is
+	// on unless explicitly turned off, see IsRevocationCheckEnabled
+	RevocationCheck *bool `mapstructure:"revocation_check"`
+	// JWKSURL is the key set tokens are verified with, defaults to the identity service one
+	JWKSURL string `mapstructure:"jwks_url"`
+	// JWKSRefreshInterval is how long the key set is cached, in seconds
+	JWKSRefreshInterval int `mapstructure:"jwks_refresh_interval"`
+	// APIKeyCacheTTL is how long an API key verified by the identity service is cached, in
+	// seconds, revoked keys keep working until their entry expires
+	APIKeyCacheTTL int `mapstructure:"api_key_cache_ttl"`
+	// APIKeyNegativeCacheTTL is how long a key rejected by the identity service is rejected
+	// without asking again, in seconds
+	APIKeyNegativeCacheTTL int `mapstructure:"api_key_negative_cache_ttl"`
+}
+
+// SetDefaults sets default values for auth configuration
+func (a *Auth) SetDefaults() {
+	if a.CacheSize == 0 {
+		a.CacheSize = 10000
+	}
+	if a.CacheMaxTTL == 0 {
+		a.CacheMaxTTL = 300 // 5 minutes
+	}
+	if a.JWKSRefreshInterval == 0 {
+		a.JWKSRefreshInterval = 300 // 5 minutes
+	}
+	if a.APIKeyCacheTTL == 0 {
+		a.APIKeyCacheTTL = 60 // 1 minute
+	}
+	if a.APIKeyNegativeCacheTTL == 0 {
+		a.APIKeyNegativeCacheTTL = 10
+	}
+}
+
+// IsRevocationCheckEnabled reports whether tokens are checked for revocation, without the check
+// logged out tokens keep working until they expire
+func (a *Auth) IsRevocationCheckEnabled() bool {
+	return a.RevocationCheck == nil || *a.RevocationCheck
+}
+
+// GetJWKSRefreshInterval returns the key set refresh interval as time.Duration
+func (a *Auth) GetJWKSRefreshInterval() time.Duration {
+	return time.Duration(a.JWKSRefreshInterval) * time.Second
+}
+
+// GetAPIKeyCacheTTL returns the API key cache TTL as time.Duration
+func (a *Auth) GetAPIKeyCacheTTL() time.Duration {
+	return time.Duration(a.APIKeyCacheTTL) * time.Second
+}
+
+// GetAPIKeyNegativeCacheTTL returns the rejected API key cache TTL as time.Duration
+func (a *Auth) GetAPIKeyNegativeCacheTTL() time.Duration {
+	return time.Duration(a.APIKeyNegativeCacheTTL) * time.Second
+}
+
+// GetCacheMaxTTL returns the cache max TTL as time.Duration
+func (a *Auth) GetCacheMaxTTL() time.Duration {
+	return time.Duration(a.CacheMaxTTL) * time.Second
+}
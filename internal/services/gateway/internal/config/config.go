@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"github.com/hthinh24/go-store/internal/pkg/config"
+	"github.com/spf13/viper"
 )
 
 const (
@@ -12,6 +13,10 @@ const (
 
 type GatewayConfig struct {
 	*config.Config
+	Routes    []Route   `mapstructure:"routes"`
+	Proxy     Proxy     `mapstructure:"proxy"`
+	Auth      Auth      `mapstructure:"auth"`
+	RateLimit RateLimit `mapstructure:"rate_limit"`
 }
 
 func LoadConfig(configPath string) (*GatewayConfig, error) {
@@ -25,6 +30,27 @@ func LoadConfig(configPath string) (*GatewayConfig, error) {
 		Config: sharedConfig,
 	}
 
+	// Load gateway specific sections, the config file was already read by the shared loader
+	if err := viper.UnmarshalKey("routes", &gatewayConfig.Routes); err != nil {
+		return nil, fmt.Errorf("error unmarshaling routes: %w", err)
+	}
+	if err := viper.UnmarshalKey("proxy", &gatewayConfig.Proxy); err != nil {
+		return nil, fmt.Errorf("error unmarshaling proxy: %w", err)
+	}
+	if err := viper.UnmarshalKey("auth", &gatewayConfig.Auth); err != nil {
+		return nil, fmt.Errorf("error unmarshaling auth: %w", err)
+	}
+	if err := viper.UnmarshalKey("rate_limit", &gatewayConfig.RateLimit); err != nil {
+		return nil, fmt.Errorf("error unmarshaling rate limit: %w", err)
+	}
+	gatewayConfig.Proxy.SetDefaults()
+	gatewayConfig.Auth.SetDefaults()
+	gatewayConfig.RateLimit.SetDefaults()
+
+	if err := gatewayConfig.RateLimit.Validate(); err != nil {
+		return nil, err
+	}
+
 	return gatewayConfig, nil
 }
 
@@ -0,0 +1,225 @@
+package config
+
+import "time"
+
+// Proxy holds reverse proxy configuration, per-upstream entries override the defaults
+type Proxy struct {
+	Defaults  Upstream            `mapstructure:"defaults"`
+	Upstreams map[string]Upstream `mapstructure:"upstreams"`
+}
+
+// Upstream holds transport settings for a single upstream service (timeouts in seconds)
+type Upstream struct {
+	DialTimeout           int `mapstructure:"dial_timeout"`
+	ResponseHeaderTimeout int `mapstructure:"response_header_timeout"`
+	IdleConnTimeout       int `mapstructure:"idle_conn_timeout"`
+	MaxIdleConnsPerHost   int `mapstructure:"max_idle_conns_per_host"`
+
+	Breaker      Breaker      `mapstructure:"breaker"`
+	Retry        Retry        `mapstructure:"retry"`
+	LoadBalancer LoadBalancer `mapstructure:"load_balancer"`
+}
+
+// Breaker holds circuit breaker settings of an upstream
+type Breaker struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// OpenTimeout is how long the circuit stays open before probing, in seconds
+	OpenTimeout int `mapstructure:"open_timeout"`
+	// HalfOpenRequests is the number of successful probes needed to close the circuit
+	HalfOpenRequests int `mapstructure:"half_open_requests"`
+}
+
+// Retry holds retry settings of an upstream, only idempotent requests without a body are retried
+type Retry struct {
+	// MaxAttempts includes the first attempt, 1 disables retries
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// BaseDelay and MaxDelay bound the jittered exponential backoff, in milliseconds
+	BaseDelay int `mapstructure:"base_delay"`
+	MaxDelay  int `mapstructure:"max_delay"`
+}
+
+// SetDefaults sets default values for proxy configuration
+func (p *Proxy) SetDefaults() {
+	if p.Defaults.DialTimeout == 0 {
+		p.Defaults.DialTimeout = 5
+	}
+	if p.Defaults.ResponseHeaderTimeout == 0 {
+		p.Defaults.ResponseHeaderTimeout = 30
+	}
+	if p.Defaults.IdleConnTimeout == 0 {
+		p.Defaults.IdleConnTimeout = 90
+	}
+	if p.Defaults.MaxIdleConnsPerHost == 0 {
+		p.Defaults.MaxIdleConnsPerHost = 32
+	}
+	if p.Defaults.Breaker.FailureThreshold == 0 {
+		p.Defaults.Breaker.FailureThreshold = 5
+	}
+	if p.Defaults.Breaker.OpenTimeout == 0 {
+		p.Defaults.Breaker.OpenTimeout = 30
+	}
+	if p.Defaults.Breaker.HalfOpenRequests == 0 {
+		p.Defaults.Breaker.HalfOpenRequests = 1
+	}
+	if p.Defaults.Retry.MaxAttempts == 0 {
+		p.Defaults.Retry.MaxAttempts = 3
+	}
+	if p.Defaults.Retry.BaseDelay == 0 {
+		p.Defaults.Retry.BaseDelay = 50
+	}
+	if p.Defaults.Retry.MaxDelay == 0 {
+		p.Defaults.Retry.MaxDelay = 1000
+	}
+	if p.Defaults.LoadBalancer.Strategy == "" {
+		p.Defaults.LoadBalancer.Strategy = StrategyRoundRobin
+	}
+	if p.Defaults.LoadBalancer.HealthCheckPath == "" {
+		p.Defaults.LoadBalancer.HealthCheckPath = "/health/ready"
+	}
+	if p.Defaults.LoadBalancer.HealthCheckInterval == 0 {
+		p.Defaults.LoadBalancer.HealthCheckInterval = 10
+	}
+	if p.Defaults.LoadBalancer.HealthCheckTimeout == 0 {
+		p.Defaults.LoadBalancer.HealthCheckTimeout = 2
+	}
+	if p.Defaults.LoadBalancer.UnhealthyThreshold == 0 {
+		p.Defaults.LoadBalancer.UnhealthyThreshold = 2
+	}
+	if p.Defaults.LoadBalancer.EjectAfter == 0 {
+		p.Defaults.LoadBalancer.EjectAfter = 5
+	}
+	if p.Defaults.LoadBalancer.EjectDuration == 0 {
+		p.Defaults.LoadBalancer.EjectDuration = 30
+	}
+}
+
+// ForService returns the upstream settings of a service, falling back to the defaults
+func (p *Proxy) ForService(name string) Upstream {
+	upstream := p.Defaults
+
+	override, exists := p.Upstreams[name]
+	if !exists {
+		return upstream
+	}
+
+	if override.DialTimeout != 0 {
+		upstream.DialTimeout = override.DialTimeout
+	}
+	if override.ResponseHeaderTimeout != 0 {
+		upstream.ResponseHeaderTimeout = override.ResponseHeaderTimeout
+	}
+	if override.IdleConnTimeout != 0 {
+		upstream.IdleConnTimeout = override.IdleConnTimeout
+	}
+	if override.MaxIdleConnsPerHost != 0 {
+		upstream.MaxIdleConnsPerHost = override.MaxIdleConnsPerHost
+	}
+	if override.Breaker.FailureThreshold != 0 {
+		upstream.Breaker.FailureThreshold = override.Breaker.FailureThreshold
+	}
+	if override.Breaker.OpenTimeout != 0 {
+		upstream.Breaker.OpenTimeout = override.Breaker.OpenTimeout
+	}
+	if override.Breaker.HalfOpenRequests != 0 {
+		upstream.Breaker.HalfOpenRequests = override.Breaker.HalfOpenRequests
+	}
+	if override.Retry.MaxAttempts != 0 {
+		upstream.Retry.MaxAttempts = override.Retry.MaxAttempts
+	}
+	if override.Retry.BaseDelay != 0 {
+		upstream.Retry.BaseDelay = override.Retry.BaseDelay
+	}
+	if override.Retry.MaxDelay != 0 {
+		upstream.Retry.MaxDelay = override.Retry.MaxDelay
+	}
+	if override.LoadBalancer.Strategy != "" {
+		upstream.LoadBalancer.Strategy = override.LoadBalancer.Strategy
+	}
+	if override.LoadBalancer.HealthCheckPath != "" {
+		upstream.LoadBalancer.HealthCheckPath = override.LoadBalancer.HealthCheckPath
+	}
+	if override.LoadBalancer.HealthCheckInterval != 0 {
+		upstream.LoadBalancer.HealthCheckInterval = override.LoadBalancer.HealthCheckInterval
+	}
+	if override.LoadBalancer.HealthCheckTimeout != 0 {
+		upstream.LoadBalancer.HealthCheckTimeout = override.LoadBalancer.HealthCheckTimeout
+	}
+	if override.LoadBalancer.UnhealthyThreshold != 0 {
+		upstream.LoadBalancer.UnhealthyThreshold = override.LoadBalancer.UnhealthyThreshold
+	}
+	if override.LoadBalancer.EjectAfter != 0 {
+		upstream.LoadBalancer.EjectAfter = override.LoadBalancer.EjectAfter
+	}
+	if override.LoadBalancer.EjectDuration != 0 {
+		upstream.LoadBalancer.EjectDuration = override.LoadBalancer.EjectDuration
+	}
+
+	return upstream
+}
+
+const (
+	StrategyRoundRobin       = "round_robin"
+	StrategyLeastConnections = "least_connections"
+)
+
+// LoadBalancer holds instance selection and health checking settings of an upstream
+type LoadBalancer struct {
+	// Strategy is "round_robin" or "least_connections"
+	Strategy string `mapstructure:"strategy"`
+	// HealthCheckPath is called on every instance each HealthCheckInterval seconds,
+	// an instance failing UnhealthyThreshold checks in a row is taken out of rotation
+	// until it passes again
+	HealthCheckPath     string `mapstructure:"health_check_path"`
+	HealthCheckInterval int    `mapstructure:"health_check_interval"`
+	HealthCheckTimeout  int    `mapstructure:"health_check_timeout"`
+	UnhealthyThreshold  int    `mapstructure:"unhealthy_threshold"`
+	// An instance returning EjectAfter consecutive 5xx responses is ejected for EjectDuration seconds
+	EjectAfter    int `mapstructure:"eject_after"`
+	EjectDuration int `mapstructure:"eject_duration"`
+}
+
+// GetDialTimeout returns the dial timeout as time.Duration
+func (u Upstream) GetDialTimeout() time.Duration {
+	return time.Duration(u.DialTimeout) * time.Second
+}
+
+// GetResponseHeaderTimeout returns the response header timeout as time.Duration
+func (u Upstream) GetResponseHeaderTimeout() time.Duration {
+	return time.Duration(u.ResponseHeaderTimeout) * time.Second
+}
+
+// GetIdleConnTimeout returns the idle connection timeout as time.Duration
+func (u Upstream) GetIdleConnTimeout() time.Duration {
+	return time.Duration(u.IdleConnTimeout) * time.Second
+}
+
+// GetOpenTimeout returns the open timeout as time.Duration
+func (b Breaker) GetOpenTimeout() time.Duration {
+	return time.Duration(b.OpenTimeout) * time.Second
+}
+
+// GetBaseDelay returns the base delay as time.Duration
+func (r Retry) GetBaseDelay() time.Duration {
+	return time.Duration(r.BaseDelay) * time.Millisecond
+}
+
+// GetMaxDelay returns the max delay as time.Duration
+func (r Retry) GetMaxDelay() time.Duration {
+	return time.Duration(r.MaxDelay) * time.Millisecond
+}
+
+// GetHealthCheckInterval returns the health check interval as time.Duration
+func (l LoadBalancer) GetHealthCheckInterval() time.Duration {
+	return time.Duration(l.HealthCheckInterval) * time.Second
+}
+
+// GetHealthCheckTimeout returns the health check timeout as time.Duration
+func (l LoadBalancer) GetHealthCheckTimeout() time.Duration {
+	return time.Duration(l.HealthCheckTimeout) * time.Second
+}
+
+// GetEjectDuration returns the passive ejection duration as time.Duration
+func (l LoadBalancer) GetEjectDuration() time.Duration {
+	return time.Duration(l.EjectDuration) * time.Second
+}
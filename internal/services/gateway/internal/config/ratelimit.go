@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	RateLimitBackendMemory = "memory"
+	RateLimitBackendRedis  = "redis"
+
+	RateLimitKeyIP     = "ip"
+	RateLimitKeyUser   = "user"
+	RateLimitKeyAPIKey = "api_key"
+)
+
+// RateLimit holds gateway rate limiting configuration, routes reference policies by name
+type RateLimit struct {
+	// Backend stores the token buckets, "memory" or "redis" (shared between gateway replicas)
+	Backend string `mapstructure:"backend"`
+	// KeyPrefix is prepended to every Redis key
+	KeyPrefix string `mapstructure:"key_prefix"`
+	// DefaultPolicy applies to routes without a rate_limit, empty means unlimited
+	DefaultPolicy string `mapstructure:"default_policy"`
+	// PreAuthPolicy applies to every request of a protected route before its credentials are
+	// verified, it must be keyed by IP. Empty means unlimited.
+	PreAuthPolicy string                     `mapstructure:"pre_auth_policy"`
+	Policies      map[string]RateLimitPolicy `mapstructure:"policies"`
+	// TrustedProxies may set X-Forwarded-For, the client IP of any other peer is its remote address
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// RateLimitPolicy describes a token bucket, refilled with Requests tokens every Period seconds
+type RateLimitPolicy struct {
+	// Key identifies the client: "ip", "user" or "api_key"
+	Key      string `mapstructure:"key"`
+	Requests int    `mapstructure:"requests"`
+	Period   int    `mapstructure:"period"`
+	// Burst is the bucket capacity, defaults to Requests
+	Burst int `mapstructure:"burst"`
+}
+
+// SetDefaults sets default values for rate limit configuration
+func (r *RateLimit) SetDefaults() {
+	if r.Backend == "" {
+		r.Backend = RateLimitBackendMemory
+	}
+	if r.KeyPrefix == "" {
+		r.KeyPrefix = "gateway:ratelimit"
+	}
+
+	for name, policy := range r.Policies {
+		if policy.Key == "" {
+			policy.Key = RateLimitKeyIP
+		}
+		if policy.Period == 0 {
+			policy.Period = 60
+		}
+		if policy.Burst == 0 {
+			policy.Burst = policy.Requests
+		}
+		r.Policies[name] = policy
+	}
+}
+
+// Validate checks the backend and every policy
+func (r *RateLimit) Validate() error {
+	if r.Backend != RateLimitBackendMemory && r.Backend != RateLimitBackendRedis {
+		return fmt.Errorf("unknown rate limit backend: %s", r.Backend)
+	}
+
+	for name, policy := range r.Policies {
+		if !policy.IsValid() {
+			return fmt.Errorf("invalid rate limit policy: %s", name)
+		}
+	}
+
+	if r.DefaultPolicy != "" {
+		if _, exists := r.Policies[r.DefaultPolicy]; !exists {
+			return fmt.Errorf("unknown default rate limit policy: %s", r.DefaultPolicy)
+		}
+	}
+
+	if r.PreAuthPolicy != "" {
+		policy, exists := r.Policies[r.PreAuthPolicy]
+		if !exists {
+			return fmt.Errorf("unknown pre auth rate limit policy: %s", r.PreAuthPolicy)
+		}
+		if policy.Key != RateLimitKeyIP {
+			return fmt.Errorf("pre auth rate limit policy %s must be keyed by ip", r.PreAuthPolicy)
+		}
+	}
+
+	return nil
+}
+
+// ForPreAuth returns the policy applied before authentication, if any
+func (r *RateLimit) ForPreAuth() (string, *RateLimitPolicy, bool) {
+	policy, exists := r.Policies[r.PreAuthPolicy]
+	if r.PreAuthPolicy == "" || !exists {
+		return "", nil, false
+	}
+
+	return r.PreAuthPolicy, &policy, true
+}
+
+// ForRoute returns the policy of a route, falling back to the default policy
+func (r *RateLimit) ForRoute(route *Route) (string, *RateLimitPolicy, bool) {
+	name := route.RateLimit
+	if name == "" {
+		name = r.DefaultPolicy
+	}
+
+	policy, exists := r.Policies[name]
+	if !exists {
+		return "", nil, false
+	}
+
+	return name, &policy, true
+}
+
+// IsValid checks if required policy fields are set
+func (p RateLimitPolicy) IsValid() bool {
+	switch p.Key {
+	case RateLimitKeyIP, RateLimitKeyUser, RateLimitKeyAPIKey:
+	default:
+		return false
+	}
+
+	return p.Requests > 0 && p.Period > 0 && p.Burst > 0
+}
+
+// GetPeriod returns the refill period as time.Duration
+func (p RateLimitPolicy) GetPeriod() time.Duration {
+	return time.Duration(p.Period) * time.Second
+}
@@ -0,0 +1,17 @@
+package config
+
+// Route describes a single entry of the gateway route table
+type Route struct {
+	// Method is the HTTP method to match, "*" matches any method
+	Method string `mapstructure:"method"`
+	// Path is the path pattern, segments can be static, ":param" or a trailing "*wildcard"
+	Path string `mapstructure:"path"`
+	// Service is the upstream service name, resolved through the services configuration
+	Service string `mapstructure:"service"`
+	// Public routes are forwarded without authentication
+	Public bool `mapstructure:"public"`
+	// Permissions the authenticated user must ALL have to access the route
+	Permissions []string `mapstructure:"permissions"`
+	// RateLimit is the name of the rate limit policy, the default policy is used when empty
+	RateLimit string `mapstructure:"rate_limit"`
+}
@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/gateway/internal/breaker"
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+	"github.com/hthinh24/go-store/services/gateway/internal/upstream"
+)
+
+// NewTransport creates the HTTP transport used to reach a single upstream service
+func NewTransport(cfg config.Upstream) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   cfg.GetDialTimeout(),
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ResponseHeaderTimeout: cfg.GetResponseHeaderTimeout(),
+		IdleConnTimeout:       cfg.GetIdleConnTimeout(),
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		TLSHandshakeTimeout:   cfg.GetDialTimeout(),
+		ExpectContinueTimeout: cfg.GetDialTimeout(),
+	}
+}
+
+// NewReverseProxy creates a reverse proxy forwarding requests to the target upstream.
+// The request path and query string are kept as is, X-Forwarded-For/Proto/Host are set
+// and hop-by-hop headers are stripped in both directions. Bodies are streamed.
+func NewReverseProxy(name string, target *url.URL, transport http.RoundTripper, logger logger.Logger) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.SetXForwarded()
+		},
+		Transport: transport,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			status, apiError := upstreamErrorStatus(err)
+			logger.WithContext(r.Context()).Error("Upstream request failed, service: ", name, ", path: ", r.URL.Path,
+				", status: ", status, ", error: ", err)
+
+			message := "Upstream service unavailable"
+			switch status {
+			case http.StatusGatewayTimeout:
+				message = "Upstream service timed out"
+			case http.StatusServiceUnavailable:
+				message = "Upstream service is temporarily disabled, try again later"
+			}
+			writeError(w, status, rest.NewErrorResponse(apiError, message))
+		},
+	}
+}
+
+// upstreamErrorStatus maps a transport error to the status returned to the client
+func upstreamErrorStatus(err error) (int, rest.ApiError) {
+	if errors.Is(err, breaker.ErrCircuitOpen) || errors.Is(err, upstream.ErrNoHealthyInstance) {
+		return http.StatusServiceUnavailable, rest.ServiceUnavailableError
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, rest.GatewayTimeoutError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return http.StatusGatewayTimeout, rest.GatewayTimeoutError
+	}
+
+	return http.StatusBadGateway, rest.BadGatewayError
+}
+
+func writeError(w http.ResponseWriter, status int, response rest.ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
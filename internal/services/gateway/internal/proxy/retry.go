@@ -0,0 +1,102 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/gateway/internal/breaker"
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+	"github.com/hthinh24/go-store/services/gateway/internal/upstream"
+)
+
+// RetryTransport retries idempotent requests without a body on transport errors and
+// 502/503/504 responses, waiting a jittered exponential backoff between attempts
+type RetryTransport struct {
+	name     string
+	settings config.Retry
+	next     http.RoundTripper
+	logger   logger.Logger
+}
+
+func NewRetryTransport(name string, settings config.Retry, next http.RoundTripper, logger logger.Logger) *RetryTransport {
+	return &RetryTransport{
+		name:     name,
+		settings: settings,
+		next:     next,
+		logger:   logger,
+	}
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isRetryable(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.settings.MaxAttempts || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		delay := t.backoff(attempt)
+		t.logger.WithContext(req.Context()).Warn("Retrying upstream request, service: ", t.name, ", path: ", req.URL.Path,
+			", attempt: ", attempt, ", delay: ", delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns a random delay up to base * 2^(attempt-1), capped by the max delay
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	ceiling := t.settings.GetBaseDelay() << (attempt - 1)
+	if ceiling <= 0 || ceiling > t.settings.GetMaxDelay() {
+		ceiling = t.settings.GetMaxDelay()
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
+
+// isRetryable only accepts idempotent methods, a streamed body cannot be sent twice
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+
+	return req.Body == nil || req.Body == http.NoBody
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		// An open circuit, an empty pool or a canceled request will not get better by retrying
+		return !errors.Is(err, breaker.ErrCircuitOpen) && !errors.Is(err, upstream.ErrNoHealthyInstance) &&
+			!errors.Is(err, context.Canceled)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,116 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/gateway/internal/breaker"
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+	"github.com/hthinh24/go-store/services/gateway/internal/upstream"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newCountingTransport answers every attempt with the status or error and counts the attempts
+func newCountingTransport(status int, err error, attempts *int) *RetryTransport {
+	next := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		*attempts++
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+	})
+	return NewRetryTransport("product", config.Retry{MaxAttempts: 3, BaseDelay: 1, MaxDelay: 2}, next,
+		logger.NewAppLogger("development"))
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		status       int
+		err          error
+		wantAttempts int
+	}{
+		{"GET on 503", http.MethodGet, "", http.StatusServiceUnavailable, nil, 3},
+		{"GET on 502", http.MethodGet, "", http.StatusBadGateway, nil, 3},
+		{"GET on 504", http.MethodGet, "", http.StatusGatewayTimeout, nil, 3},
+		{"HEAD on transport error", http.MethodHead, "", 0, errors.New("connection reset"), 3},
+		{"PUT without body", http.MethodPut, "", http.StatusServiceUnavailable, nil, 3},
+		{"DELETE without body", http.MethodDelete, "", http.StatusServiceUnavailable, nil, 3},
+		{"OPTIONS without body", http.MethodOptions, "", http.StatusServiceUnavailable, nil, 3},
+		{"GET on success", http.MethodGet, "", http.StatusOK, nil, 1},
+		{"GET on 500", http.MethodGet, "", http.StatusInternalServerError, nil, 1},
+		{"GET on 404", http.MethodGet, "", http.StatusNotFound, nil, 1},
+		{"POST", http.MethodPost, "", http.StatusServiceUnavailable, nil, 1},
+		{"PATCH", http.MethodPatch, "", http.StatusServiceUnavailable, nil, 1},
+		{"PUT with body", http.MethodPut, `{"quantity":2}`, http.StatusServiceUnavailable, nil, 1},
+		{"DELETE with body", http.MethodDelete, `{"ids":[1]}`, http.StatusServiceUnavailable, nil, 1},
+		{"open circuit", http.MethodGet, "", 0, breaker.ErrCircuitOpen, 1},
+		{"no healthy instance", http.MethodGet, "", 0, upstream.ErrNoHealthyInstance, 1},
+		{"canceled request", http.MethodGet, "", 0, context.Canceled, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			transport := newCountingTransport(tt.status, tt.err, &attempts)
+
+			req, _ := http.NewRequest(tt.method, "http://product/api/v1/products", nil)
+			if tt.body != "" {
+				req, _ = http.NewRequest(tt.method, "http://product/api/v1/products", strings.NewReader(tt.body))
+			}
+			resp, err := transport.RoundTrip(req)
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("RoundTrip error = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && (resp == nil || resp.StatusCode != tt.status) {
+				t.Errorf("response = %+v, want the last status %d", resp, tt.status)
+			}
+		})
+	}
+}
+
+func TestRetryTransportStopsWhenCanceled(t *testing.T) {
+	attempts := 0
+	transport := newCountingTransport(http.StatusServiceUnavailable, nil, &attempts)
+	transport.settings = config.Retry{MaxAttempts: 3, BaseDelay: 60_000, MaxDelay: 60_000}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://product/api/v1/products", nil)
+
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("RoundTrip error = %v, want %v", err, context.Canceled)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	transport := newCountingTransport(http.StatusOK, nil, new(int))
+	transport.settings = config.Retry{MaxAttempts: 5, BaseDelay: 100, MaxDelay: 300}
+
+	for attempt, ceiling := range map[int]int64{1: 100, 2: 200, 3: 300, 10: 300, 64: 300} {
+		for range 20 {
+			delay := transport.backoff(attempt)
+			if delay <= 0 || delay.Milliseconds() > ceiling {
+				t.Errorf("backoff(%d) = %v, want within (0, %dms]", attempt, delay, ceiling)
+			}
+		}
+	}
+}
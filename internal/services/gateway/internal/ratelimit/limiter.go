@@ -0,0 +1,31 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+)
+
+// Result is the outcome of a single token bucket check
+type Result struct {
+	Allowed bool
+	// Limit is the bucket capacity
+	Limit int
+	// Remaining is the number of whole tokens left after this request
+	Remaining int
+	// ResetAfter is the time until the bucket is full again
+	ResetAfter time.Duration
+	// RetryAfter is the time until the next token is available, zero when allowed
+	RetryAfter time.Duration
+}
+
+// Limiter takes one token from the bucket identified by key
+type Limiter interface {
+	Allow(ctx context.Context, key string, policy *config.RateLimitPolicy) (*Result, error)
+}
+
+// refillRate returns the number of tokens added per second
+func refillRate(policy *config.RateLimitPolicy) float64 {
+	return float64(policy.Requests) / policy.GetPeriod().Seconds()
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+)
+
+// sweepInterval is how often full buckets are dropped from memory
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	capacity  float64
+	rate      float64
+}
+
+// MemoryLimiter keeps token buckets in process memory, limits are per gateway replica
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, policy *config.RateLimitPolicy) (*Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	capacity := float64(policy.Burst)
+	rate := refillRate(policy)
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: capacity, updatedAt: now}
+		l.buckets[key] = b
+	}
+	b.capacity = capacity
+	b.rate = rate
+
+	// Refill for the time elapsed since the last request
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+	b.updatedAt = now
+
+	result := &Result{Limit: policy.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.ResetAfter = secondsToDuration((capacity - b.tokens) / rate)
+
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely, they are equal to a new bucket
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updatedAt).Seconds()*b.rate >= b.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
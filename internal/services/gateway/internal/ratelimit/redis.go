@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and takes a token atomically. Redis server time is used so
+// every gateway replica sees the same clock.
+// Returns {allowed, remaining, retry after ms, reset after ms}.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(bucket[1])
+local updated_at = tonumber(bucket[2])
+if tokens == nil or updated_at == nil then
+	tokens = capacity
+	updated_at = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - updated_at) * rate)
+
+local allowed = 0
+local retry_after = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry_after = math.ceil((1 - tokens) / rate)
+end
+
+local reset_after = math.ceil((capacity - tokens) / rate)
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', now)
+redis.call('PEXPIRE', KEYS[1], math.max(reset_after, 1000))
+
+return {allowed, math.floor(tokens), retry_after, reset_after}
+`)
+
+// RedisLimiter keeps token buckets in Redis so limits hold across gateway replicas
+type RedisLimiter struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+func NewRedisLimiter(client *redis.Client, keyPrefix string) *RedisLimiter {
+	return &RedisLimiter{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, policy *config.RateLimitPolicy) (*Result, error) {
+	// The script works in milliseconds
+	ratePerMs := refillRate(policy) / 1000
+
+	values, err := tokenBucketScript.Run(ctx, l.client, []string{l.keyPrefix + ":" + key},
+		policy.Burst, strconv.FormatFloat(ratePerMs, 'g', -1, 64)).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      policy.Burst,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
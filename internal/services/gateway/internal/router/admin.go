@@ -0,0 +1,61 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/gateway/internal/breaker"
+	"github.com/hthinh24/go-store/services/gateway/internal/upstream"
+)
+
+const adminRole = "admin"
+
+// requireRole only lets authenticated users with the given role through
+func (g *Gateway) requireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authResp, ok := g.authenticate(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		if !slices.Contains(authResp.Roles, role) {
+			g.logger.WithContext(c.Request.Context()).Warn("Access denied - missing role, user_id: ", authResp.UserID, ", required_role: ", role)
+			c.AbortWithStatusJSON(http.StatusForbidden, rest.NewErrorResponse(rest.ForbiddenError, "Insufficient permissions"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// getCircuitBreakers returns the circuit breaker state of every upstream service
+func (g *Gateway) getCircuitBreakers(c *gin.Context) {
+	snapshots := make([]breaker.Snapshot, 0, len(g.breakers))
+	for _, b := range g.breakers {
+		snapshots = append(snapshots, b.Snapshot())
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Service < snapshots[j].Service
+	})
+
+	c.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Circuit breakers fetched successfully", snapshots))
+}
+
+// getUpstreams returns the instances and their health of every upstream service
+func (g *Gateway) getUpstreams(c *gin.Context) {
+	snapshots := make([]upstream.Snapshot, 0, len(g.pools))
+	for _, pool := range g.pools {
+		snapshots = append(snapshots, pool.Snapshot())
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Service < snapshots[j].Service
+	})
+
+	c.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Upstreams fetched successfully", snapshots))
+}
@@ -0,0 +1,111 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hthinh24/go-store/services/gateway/internal/breaker"
+	"github.com/hthinh24/go-store/services/gateway/internal/upstream"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var breakerStates = []breaker.State{breaker.StateClosed, breaker.StateOpen, breaker.StateHalfOpen}
+
+// upstreamMetrics records every attempt sent to the upstream services and exposes the state
+// of the circuit breakers and instance pools on scrape
+type upstreamMetrics struct {
+	attemptDuration *prometheus.HistogramVec
+
+	breakers map[string]*breaker.Breaker
+	pools    map[string]*upstream.Pool
+
+	breakerState    *prometheus.Desc
+	breakerFailures *prometheus.Desc
+	instanceHealthy *prometheus.Desc
+	instanceActive  *prometheus.Desc
+}
+
+func newUpstreamMetrics(breakers map[string]*breaker.Breaker, pools map[string]*upstream.Pool) *upstreamMetrics {
+	return &upstreamMetrics{
+		attemptDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "gateway_upstream_request_duration_seconds",
+			Help:    "Latency of every attempt sent to an upstream service, by status code or \"error\".",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"upstream", "code"}),
+		breakers: breakers,
+		pools:    pools,
+		breakerState: prometheus.NewDesc("gateway_circuit_breaker_state",
+			"Circuit breaker state of an upstream service, 1 for the current state.",
+			[]string{"upstream", "state"}, nil),
+		breakerFailures: prometheus.NewDesc("gateway_circuit_breaker_consecutive_failures",
+			"Consecutive failures recorded by the circuit breaker of an upstream service.",
+			[]string{"upstream"}, nil),
+		instanceHealthy: prometheus.NewDesc("gateway_upstream_instance_healthy",
+			"Whether an upstream instance passes its health checks.",
+			[]string{"upstream", "instance"}, nil),
+		instanceActive: prometheus.NewDesc("gateway_upstream_instance_active_requests",
+			"Requests in flight to an upstream instance.",
+			[]string{"upstream", "instance"}, nil),
+	}
+}
+
+func (m *upstreamMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.attemptDuration.Describe(ch)
+	ch <- m.breakerState
+	ch <- m.breakerFailures
+	ch <- m.instanceHealthy
+	ch <- m.instanceActive
+}
+
+func (m *upstreamMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.attemptDuration.Collect(ch)
+
+	for name, b := range m.breakers {
+		snapshot := b.Snapshot()
+		for _, state := range breakerStates {
+			value := 0.0
+			if snapshot.State == state.String() {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(m.breakerState, prometheus.GaugeValue, value, name, state.String())
+		}
+		ch <- prometheus.MustNewConstMetric(m.breakerFailures, prometheus.GaugeValue,
+			float64(snapshot.ConsecutiveFailures), name)
+	}
+
+	for name, pool := range m.pools {
+		for _, instance := range pool.Snapshot().Instances {
+			healthy := 0.0
+			if instance.Healthy {
+				healthy = 1
+			}
+			ch <- prometheus.MustNewConstMetric(m.instanceHealthy, prometheus.GaugeValue, healthy, name, instance.URL)
+			ch <- prometheus.MustNewConstMetric(m.instanceActive, prometheus.GaugeValue,
+				float64(instance.ActiveRequests), name, instance.URL)
+		}
+	}
+}
+
+// transport records the latency of each attempt, it sits below the retries so a retried
+// request is observed once per attempt
+func (m *upstreamMetrics) transport(name string, next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+		m.attemptDuration.WithLabelValues(name, code).Observe(time.Since(start).Seconds())
+
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
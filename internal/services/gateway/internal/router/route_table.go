@@ -0,0 +1,246 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+)
+
+var (
+	ErrRouteNotFound    = errors.New("route not found")
+	ErrMethodNotAllowed = errors.New("method not allowed")
+)
+
+const anyMethod = "*"
+
+type segmentKind int
+
+// Segment kinds are ordered by precedence, a lower kind is more specific
+const (
+	segmentStatic segmentKind = iota
+	segmentParam
+	segmentWildcard
+)
+
+type segment struct {
+	kind  segmentKind
+	value string // literal for static segments, parameter name otherwise
+}
+
+type compiledRoute struct {
+	route    config.Route
+	segments []segment
+}
+
+// RouteMatch is the result of a successful route table lookup
+type RouteMatch struct {
+	Route  *config.Route
+	Params map[string]string
+}
+
+// RouteTable matches requests against the routes declared in the gateway config
+type RouteTable struct {
+	routes []*compiledRoute
+}
+
+// NewRouteTable compiles and validates the configured routes
+func NewRouteTable(routes []config.Route) (*RouteTable, error) {
+	table := &RouteTable{}
+	seen := make(map[string]bool)
+
+	for i := range routes {
+		compiled, err := compileRoute(routes[i])
+		if err != nil {
+			return nil, fmt.Errorf("route #%d (%s %s): %w", i, routes[i].Method, routes[i].Path, err)
+		}
+
+		// Two routes with the same shape and method would make matching ambiguous
+		key := compiled.route.Method + " " + compiled.shape()
+		if seen[key] {
+			return nil, fmt.Errorf("route #%d (%s %s): duplicate route", i, routes[i].Method, routes[i].Path)
+		}
+		seen[key] = true
+
+		table.routes = append(table.routes, compiled)
+	}
+
+	return table, nil
+}
+
+// Match returns the most specific route for the given method and path.
+// Static segments win over ":param" segments, which win over a "*wildcard".
+func (t *RouteTable) Match(method, path string) (*RouteMatch, error) {
+	parts := splitPath(path)
+
+	var best *compiledRoute
+	var bestParams map[string]string
+	pathMatched := false
+
+	for _, r := range t.routes {
+		params, ok := r.match(parts)
+		if !ok {
+			continue
+		}
+		pathMatched = true
+
+		if r.route.Method != anyMethod && r.route.Method != method {
+			continue
+		}
+
+		if best == nil || r.moreSpecificThan(best) {
+			best = r
+			bestParams = params
+		}
+	}
+
+	if best == nil {
+		if pathMatched {
+			return nil, ErrMethodNotAllowed
+		}
+		return nil, ErrRouteNotFound
+	}
+
+	return &RouteMatch{Route: &best.route, Params: bestParams}, nil
+}
+
+// Services returns the distinct upstream service names referenced by the table
+func (t *RouteTable) Services() []string {
+	seen := make(map[string]bool)
+	var services []string
+	for _, r := range t.routes {
+		if !seen[r.route.Service] {
+			seen[r.route.Service] = true
+			services = append(services, r.route.Service)
+		}
+	}
+	return services
+}
+
+func compileRoute(route config.Route) (*compiledRoute, error) {
+	route.Method = strings.ToUpper(strings.TrimSpace(route.Method))
+	if route.Method == "" {
+		return nil, errors.New("method is required")
+	}
+	if route.Method != anyMethod && !isValidMethod(route.Method) {
+		return nil, fmt.Errorf("unsupported method %q", route.Method)
+	}
+	if route.Service == "" {
+		return nil, errors.New("service is required")
+	}
+	if !strings.HasPrefix(route.Path, "/") {
+		return nil, errors.New("path must start with '/'")
+	}
+
+	parts := splitPath(route.Path)
+	segments := make([]segment, 0, len(parts))
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, ":"):
+			if len(part) == 1 {
+				return nil, errors.New("parameter segment must be named")
+			}
+			segments = append(segments, segment{kind: segmentParam, value: part[1:]})
+		case strings.HasPrefix(part, "*"):
+			if i != len(parts)-1 {
+				return nil, errors.New("wildcard segment must be the last segment")
+			}
+			segments = append(segments, segment{kind: segmentWildcard, value: strings.TrimPrefix(part, "*")})
+		case part == "":
+			return nil, errors.New("empty path segment")
+		default:
+			segments = append(segments, segment{kind: segmentStatic, value: part})
+		}
+	}
+
+	return &compiledRoute{route: route, segments: segments}, nil
+}
+
+func (r *compiledRoute) match(parts []string) (map[string]string, bool) {
+	params := make(map[string]string)
+
+	for i, seg := range r.segments {
+		if seg.kind == segmentWildcard {
+			if seg.value != "" {
+				params[seg.value] = strings.Join(parts[i:], "/")
+			}
+			return params, true
+		}
+
+		if i >= len(parts) {
+			return nil, false
+		}
+
+		switch seg.kind {
+		case segmentStatic:
+			if parts[i] != seg.value {
+				return nil, false
+			}
+		case segmentParam:
+			if parts[i] == "" {
+				return nil, false
+			}
+			params[seg.value] = parts[i]
+		}
+	}
+
+	if len(parts) != len(r.segments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+// moreSpecificThan compares two routes that matched the same path segment by segment
+func (r *compiledRoute) moreSpecificThan(other *compiledRoute) bool {
+	for i := 0; i < len(r.segments) && i < len(other.segments); i++ {
+		if r.segments[i].kind != other.segments[i].kind {
+			return r.segments[i].kind < other.segments[i].kind
+		}
+	}
+
+	if len(r.segments) != len(other.segments) {
+		// A longer pattern only matches the same path when the shorter one ends with a wildcard
+		return len(r.segments) > len(other.segments)
+	}
+
+	// Same shape, prefer an explicit method over "*"
+	return r.route.Method != anyMethod && other.route.Method == anyMethod
+}
+
+// shape returns the pattern with parameter names erased, "/users/:id" and "/users/:userID" share a shape
+func (r *compiledRoute) shape() string {
+	var sb strings.Builder
+	for _, seg := range r.segments {
+		sb.WriteString("/")
+		switch seg.kind {
+		case segmentStatic:
+			sb.WriteString(seg.value)
+		case segmentParam:
+			sb.WriteString(":")
+		case segmentWildcard:
+			sb.WriteString("*")
+		}
+	}
+	return sb.String()
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
+
+func isValidMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,129 @@
+package router
+
+import (
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+)
+
+func TestRouteTableMatch(t *testing.T) {
+	table, err := NewRouteTable([]config.Route{
+		{Method: "GET", Path: "/api/v1/users/me", Service: "static"},
+		{Method: "GET", Path: "/api/v1/users/:id", Service: "param"},
+		{Method: "GET", Path: "/api/v1/users/:id/roles", Service: "param-static"},
+		{Method: "DELETE", Path: "/api/v1/users/:id/roles/:role", Service: "param-param"},
+		{Method: "*", Path: "/api/v1/users/*rest", Service: "wildcard"},
+		{Method: "*", Path: "/api/v1/cart/items/*rest", Service: "any-method"},
+		{Method: "put", Path: "/api/v1/cart/items/*rest", Service: "explicit-method"},
+		{Method: "GET", Path: "/", Service: "root"},
+	})
+	if err != nil {
+		t.Fatalf("NewRouteTable: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		wantService string
+		wantParams  map[string]string
+		wantErr     error
+	}{
+		{"static wins over param", "GET", "/api/v1/users/me", "static", map[string]string{}, nil},
+		{"param wins over wildcard", "GET", "/api/v1/users/42", "param", map[string]string{"id": "42"}, nil},
+		{"trailing slash", "GET", "/api/v1/users/42/", "param", map[string]string{"id": "42"}, nil},
+		{"static after param", "GET", "/api/v1/users/42/roles", "param-static", map[string]string{"id": "42"}, nil},
+		{"two params", "DELETE", "/api/v1/users/42/roles/admin", "param-param",
+			map[string]string{"id": "42", "role": "admin"}, nil},
+		{"wildcard catches the rest", "POST", "/api/v1/users/42", "wildcard", map[string]string{"rest": "42"}, nil},
+		{"wildcard spans segments", "GET", "/api/v1/users/42/roles/admin", "wildcard",
+			map[string]string{"rest": "42/roles/admin"}, nil},
+		{"explicit method wins over any", "PUT", "/api/v1/cart/items/7", "explicit-method", map[string]string{"rest": "7"}, nil},
+		{"any method", "PATCH", "/api/v1/cart/items/7", "any-method", map[string]string{"rest": "7"}, nil},
+		{"root", "GET", "/", "root", map[string]string{}, nil},
+		{"method not allowed", "POST", "/", "", nil, ErrMethodNotAllowed},
+		{"unknown path", "GET", "/api/v1/products", "", nil, ErrRouteNotFound},
+		{"static segments are case sensitive", "GET", "/API/v1/users/me", "", nil, ErrRouteNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := table.Match(tt.method, tt.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Match error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if match.Route.Service != tt.wantService {
+				t.Errorf("matched %s, want %s", match.Route.Service, tt.wantService)
+			}
+			if !maps.Equal(match.Params, tt.wantParams) {
+				t.Errorf("params = %v, want %v", match.Params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestNewRouteTableRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		routes  []config.Route
+		wantErr string
+	}{
+		{
+			name: "duplicate route",
+			routes: []config.Route{
+				{Method: "GET", Path: "/users/:id", Service: "identity"},
+				{Method: "GET", Path: "/users/:userID/", Service: "identity"},
+			},
+			wantErr: "duplicate route",
+		},
+		{
+			name: "duplicate route with another method case",
+			routes: []config.Route{
+				{Method: "get", Path: "/users", Service: "identity"},
+				{Method: "GET", Path: "/users", Service: "identity"},
+			},
+			wantErr: "duplicate route",
+		},
+		{
+			name: "duplicate wildcard",
+			routes: []config.Route{
+				{Method: "*", Path: "/cart/*rest", Service: "cart"},
+				{Method: "*", Path: "/cart/*", Service: "cart"},
+			},
+			wantErr: "duplicate route",
+		},
+		{name: "missing method", routes: []config.Route{{Path: "/users", Service: "identity"}}, wantErr: "method is required"},
+		{name: "unknown method", routes: []config.Route{{Method: "TRACE", Path: "/users", Service: "identity"}}, wantErr: "unsupported method"},
+		{name: "missing service", routes: []config.Route{{Method: "GET", Path: "/users"}}, wantErr: "service is required"},
+		{name: "relative path", routes: []config.Route{{Method: "GET", Path: "users", Service: "identity"}}, wantErr: "must start with '/'"},
+		{name: "unnamed param", routes: []config.Route{{Method: "GET", Path: "/users/:", Service: "identity"}}, wantErr: "must be named"},
+		{name: "wildcard not last", routes: []config.Route{{Method: "GET", Path: "/users/*rest/roles", Service: "identity"}}, wantErr: "must be the last"},
+		{name: "empty segment", routes: []config.Route{{Method: "GET", Path: "/users//roles", Service: "identity"}}, wantErr: "empty path segment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRouteTable(tt.routes)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewRouteTable error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRouteTableAllowsSameShapeWithOtherMethod(t *testing.T) {
+	_, err := NewRouteTable([]config.Route{
+		{Method: "GET", Path: "/users/:id", Service: "identity"},
+		{Method: "DELETE", Path: "/users/:id", Service: "identity"},
+		{Method: "*", Path: "/users/:id", Service: "identity"},
+	})
+	if err != nil {
+		t.Errorf("NewRouteTable: %v", err)
+	}
+}
@@ -1,78 +1,204 @@
 package router
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/http/httputil"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/health"
+	"github.com/hthinh24/go-store/internal/pkg/jwks"
 	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/metrics"
+	"github.com/hthinh24/go-store/internal/pkg/middleware/requestid"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/internal/pkg/tracing"
+	"github.com/hthinh24/go-store/services/gateway/internal/auth"
+	"github.com/hthinh24/go-store/services/gateway/internal/breaker"
 	"github.com/hthinh24/go-store/services/gateway/internal/config"
+	"github.com/hthinh24/go-store/services/gateway/internal/proxy"
+	"github.com/hthinh24/go-store/services/gateway/internal/ratelimit"
+	"github.com/hthinh24/go-store/services/gateway/internal/upstream"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Gateway struct {
-	config *config.GatewayConfig
-	logger logger.Logger
-	client *http.Client
+	config   *config.GatewayConfig
+	logger   logger.Logger
+	verifier *auth.Verifier
+	apiKeys  *auth.APIKeyVerifier
+	limiter  ratelimit.Limiter
+	metrics  *metrics.Metrics
+	health   *health.Health
+	routes   *RouteTable
+	proxies  map[string]*httputil.ReverseProxy
+	breakers map[string]*breaker.Breaker
+	pools    map[string]*upstream.Pool
+	cancel   context.CancelFunc
 }
 
-type VerifyResponse struct {
-	UserID      string   `json:"user_id"`
-	Roles       []string `json:"roles"`
-	Permissions []string `json:"permissions"`
-}
+// userHeaders are set by the gateway only, client supplied values must never reach upstreams
+var userHeaders = []string{"X-User-ID", "X-User-Email", "X-User-Roles", "X-User-Permissions", authTypeHeader}
 
-func NewGateway(cfg *config.GatewayConfig, logger logger.Logger) *Gateway {
-	return &Gateway{
-		config: cfg,
-		logger: logger,
-		client: &http.Client{},
+// authTypeHeader tells upstreams whether the user authenticated with an access token or an API key
+const authTypeHeader = "X-User-Auth-Type"
+
+func NewGateway(cfg *config.GatewayConfig, logger logger.Logger, limiter ratelimit.Limiter, appMetrics *metrics.Metrics, appHealth *health.Health) (*Gateway, error) {
+	routes, err := NewRouteTable(cfg.Routes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid route table: %w", err)
+	}
+
+	for _, route := range cfg.Routes {
+		if _, exists := cfg.RateLimit.Policies[route.RateLimit]; route.RateLimit != "" && !exists {
+			return nil, fmt.Errorf("unknown rate limit policy %s for route: %s %s", route.RateLimit, route.Method, route.Path)
+		}
+	}
+
+	// Build one instance pool and reverse proxy per upstream referenced by the route table, at startup
+	proxies := make(map[string]*httputil.ReverseProxy)
+	breakers := make(map[string]*breaker.Breaker)
+	pools := make(map[string]*upstream.Pool)
+	upstreamMetrics := newUpstreamMetrics(breakers, pools)
+	for _, name := range routes.Services() {
+		upstreamConfig := cfg.Proxy.ForService(name)
+
+		pool, err := upstream.NewPool(name, cfg.Services.GetServiceURLs(name), upstreamConfig.LoadBalancer)
+		if err != nil {
+			return nil, err
+		}
+		pools[name] = pool
+
+		// Retries wrap the breaker so every attempt is recorded and an opened circuit stops
+		// retrying, each attempt picks an instance from the pool
+		breakers[name] = breaker.NewBreaker(name, upstreamConfig.Breaker)
+		transport := proxy.NewRetryTransport(name, upstreamConfig.Retry,
+			breaker.NewTransport(breakers[name],
+				upstreamMetrics.transport(name,
+					upstream.NewTransport(pool, tracing.NewTransport(proxy.NewTransport(upstreamConfig))))), logger)
+
+		// The pool replaces the host, the first instance only provides the target base path
+		proxies[name] = proxy.NewReverseProxy(name, pool.Instances()[0].URL, transport, logger)
+	}
+
+	appMetrics.Registerer().MustRegister(upstreamMetrics)
+
+	// Start active health checks, they run until the gateway is closed
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, pool := range pools {
+		go upstream.NewHealthChecker(pool, logger).Run(ctx)
+	}
+
+	// Tokens are verified locally with the published keys of the identity service, the
+	// identity service is only asked for revocation checks
+	jwksURL := cfg.Auth.JWKSURL
+	if jwksURL == "" {
+		jwksURL = cfg.GetIdentityServiceURL() + jwks.Path
+	}
+	keys := jwks.NewClient(logger, jwksURL, cfg.Auth.GetJWKSRefreshInterval(),
+		appMetrics.NewTransport("jwks", tracing.NewTransport(nil)))
+	appHealth.AddCheck("jwks", keys.Ready)
+
+	// API keys are always verified by the identity service, access tokens only with revocation checks
+	identityClient := auth.NewIdentityClient(cfg.GetIdentityServiceURL()+"/"+config.ApiVersionV1+"/auth/verify",
+		appMetrics.NewTransport("identity", tracing.NewTransport(nil)))
+	var revocationClient *auth.IdentityClient
+	if cfg.Auth.IsRevocationCheckEnabled() {
+		revocationClient = identityClient
 	}
+
+	return &Gateway{
+		config:   cfg,
+		logger:   logger,
+		verifier: auth.NewVerifier(logger, keys, cfg.Auth, revocationClient),
+		apiKeys:  auth.NewAPIKeyVerifier(logger, identityClient, cfg.Auth),
+		limiter:  limiter,
+		metrics:  appMetrics,
+		health:   appHealth,
+		routes:   routes,
+		proxies:  proxies,
+		breakers: breakers,
+		pools:    pools,
+		cancel:   cancel,
+	}, nil
 }
 
 func (g *Gateway) SetupRoutes(r *gin.Engine) {
-	r.Any("/*path", g.handleRequest)
-}
+	// Accept or generate the request ID first, it is forwarded to upstreams with the request
+	r.Use(requestid.RequestID())
+	r.Use(tracing.Middleware(g.config.ServiceName))
+	r.Use(g.metrics.Middleware())
 
-// Public endpoints that don't require authentication
-var publicEndpoints = map[string][]string{
-	"/api/v1/auth/login":    {"POST"},
-	"/api/v1/auth/register": {"POST"},
-	"/api/v1/auth/refresh":  {"POST"},
+	// Metrics reveal the upstreams, their traffic and breaker state, only admins may scrape them
+	r.GET(metrics.Path, g.requireRole(adminRole), gin.WrapH(g.metrics.Handler()))
+	g.health.Register(r)
 
-	"/api/v1/users":         {"POST"}, // Public user list
-	"/api/v1/cart/register": {"POST"}, // Public user list
+	// Gateway own endpoints, everything else goes through the route table
+	admin := r.Group("/admin", g.requireRole(adminRole))
+	{
+		admin.GET("/circuit-breakers", g.getCircuitBreakers)
+		admin.GET("/upstreams", g.getUpstreams)
+	}
+
+	r.NoRoute(g.handleRequest)
+}
 
-	"/api/v1/products/:id": {"GET"}, // Public product details
-	"/api/v1/products":     {"GET"},
+// Close stops the background health checks
+func (g *Gateway) Close() {
+	g.cancel()
 }
 
 func (g *Gateway) handleRequest(c *gin.Context) {
 	path := c.Request.URL.Path
 	method := c.Request.Method
+	logger := g.logger.WithContext(c.Request.Context())
 
-	g.logger.Info("Received request, ", "path: ", path, " | ", "method: ", method)
+	logger.Info("Received request, ", "path: ", path, " | ", "method: ", method)
 
-	// Check if endpoint is public
-	if g.isPublicEndpoint(path, method) {
-		g.forwardToService(c, path)
+	match, err := g.routes.Match(method, path)
+	if err != nil {
+		if errors.Is(err, ErrMethodNotAllowed) {
+			c.JSON(http.StatusMethodNotAllowed, rest.NewErrorResponse(rest.MethodNotAllowedError, "Method not allowed"))
+			return
+		}
+		logger.Warn("No route found for path: ", path, ", method: ", method)
+		c.JSON(http.StatusNotFound, rest.NewErrorResponse(rest.NotFoundError, "Page not found"))
 		return
 	}
 
-	// For non-public endpoints, verify auth with identity service
-	authToken := c.GetHeader("Authorization")
-	if authToken == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+	// Name the server span and label the metrics after the route pattern, gin only knows the catch-all handler
+	trace.SpanFromContext(c.Request.Context()).SetName(method + " " + match.Route.Path)
+	metrics.SetRoute(c, match.Route.Path)
+
+	for _, header := range userHeaders {
+		c.Request.Header.Del(header)
+	}
+
+	// Public routes are forwarded without authentication
+	if match.Route.Public {
+		if g.allowRequest(c, match, "") {
+			g.forwardToService(c, match)
+		}
 		return
 	}
 
-	// Verify token with identity service
-	authResp, err := g.verifyWithIdentityService(authToken)
-	if err != nil {
-		g.logger.Error("Auth verification failed, error: ", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+	// For non-public endpoints, verify the bearer token or API key
+	authResp, ok := g.authenticate(c)
+	if !ok {
+		return
+	}
+
+	if !hasAllPermissions(authResp.Permissions, match.Route.Permissions) {
+		logger.Warn("Access denied - missing permissions, user_id: ", authResp.UserID,
+			", required_permissions: ", match.Route.Permissions)
+		c.JSON(http.StatusForbidden, rest.NewErrorResponse(rest.ForbiddenError, "Insufficient permissions"))
+		return
+	}
+
+	if !g.allowRequest(c, match, authResp.UserID) {
 		return
 	}
 
@@ -80,101 +206,66 @@ func (g *Gateway) handleRequest(c *gin.Context) {
 	c.Request.Header.Set("X-User-ID", authResp.UserID)
 	c.Request.Header.Set("X-User-Roles", strings.Join(authResp.Roles, ","))
 	c.Request.Header.Set("X-User-Permissions", strings.Join(authResp.Permissions, ","))
+	c.Request.Header.Set(authTypeHeader, authResp.AuthType)
 
 	// Forward to appropriate service
-	g.forwardToService(c, path)
+	g.forwardToService(c, match)
 }
 
-func (g *Gateway) isPublicEndpoint(path, method string) bool {
-	for publicPath, methods := range publicEndpoints {
-		if strings.HasPrefix(path, publicPath) {
-			for _, acceptMethod := range methods {
-				if acceptMethod == method {
-					return true
-				}
-			}
-		}
+// authenticate verifies the bearer token or API key of the request, it returns false after
+// writing a 401 or 429 response
+func (g *Gateway) authenticate(c *gin.Context) (*auth.VerifyResponse, bool) {
+	if !g.allowUnauthenticated(c) {
+		return nil, false
 	}
 
-	return false
-}
-
-func (g *Gateway) verifyWithIdentityService(authToken string) (*VerifyResponse, error) {
-	req, err := http.NewRequest("GET",
-		g.config.GetIdentityServiceURL()+"/"+config.ApiVersionV1+"/auth/verify", nil)
-	if err != nil {
-		return nil, err
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, rest.NewErrorResponse(rest.UnauthorizedError, "Authorization header required"))
+		return nil, false
 	}
 
-	req.Header.Set("Authorization", authToken)
-
-	resp, err := g.client.Do(req)
-	if err != nil {
-		return nil, err
+	if apiKey, found := strings.CutPrefix(authHeader, "ApiKey "); found {
+		authResp, err := g.apiKeys.Verify(c.Request.Context(), apiKey)
+		if err != nil {
+			g.logger.WithContext(c.Request.Context()).Error("API key verification failed, error: ", err)
+			c.JSON(http.StatusUnauthorized, rest.NewErrorResponse(rest.UnauthorizedError, "Invalid API key"))
+			return nil, false
+		}
+		return authResp, true
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("auth verification failed with status: %d", resp.StatusCode)
+	authToken, found := strings.CutPrefix(authHeader, "Bearer ")
+	if !found || authToken == "" {
+		c.JSON(http.StatusUnauthorized, rest.NewErrorResponse(rest.UnauthorizedError, "Invalid authorization header format"))
+		return nil, false
 	}
 
-	var verifyResponse VerifyResponse
-	if err := json.NewDecoder(resp.Body).Decode(&verifyResponse); err != nil {
-		return nil, err
+	authResp, err := g.verifier.Verify(c.Request.Context(), authToken)
+	if err != nil {
+		g.logger.WithContext(c.Request.Context()).Error("Auth verification failed, error: ", err)
+		c.JSON(http.StatusUnauthorized, rest.NewErrorResponse(rest.UnauthorizedError, "Invalid token"))
+		return nil, false
 	}
 
-	return &verifyResponse, nil
+	return authResp, true
 }
 
-func (g *Gateway) forwardToService(c *gin.Context, path string) {
-	var targetURL string
-
-	// Route to appropriate service based on path
-	if strings.HasPrefix(path, "/"+config.ApiVersionV1+"/auth") ||
-		strings.HasPrefix(path, "/"+config.ApiVersionV1+"/users") {
-		targetURL = g.config.GetIdentityServiceURL() + path
-	} else if strings.HasPrefix(path, "/"+config.ApiVersionV1+"/products") {
-		targetURL = g.config.GetProductServiceURL() + path
-	} else if strings.HasPrefix(path, "/"+config.ApiVersionV1+"/cart") {
-		targetURL = g.config.GetCartServiceURL() + path
-	} else {
-		g.logger.Warn("No service found for path: ", path)
-		c.JSON(http.StatusNotFound, gin.H{"error": "page not found"})
-		return
-	}
-
-	// Create new request
-	req, err := http.NewRequest(c.Request.Method, targetURL, c.Request.Body)
-	if err != nil {
-		g.logger.Error("Failed to create request: ", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		return
+func hasAllPermissions(userPermissions, requiredPermissions []string) bool {
+	userPermSet := make(map[string]bool, len(userPermissions))
+	for _, perm := range userPermissions {
+		userPermSet[perm] = true
 	}
 
-	// Copy headers
-	for key, values := range c.Request.Header {
-		for _, value := range values {
-			req.Header.Add(key, value)
+	for _, requiredPerm := range requiredPermissions {
+		if !userPermSet[requiredPerm] {
+			return false
 		}
 	}
 
-	// Forward request
-	resp, err := g.client.Do(req)
-	if err != nil {
-		g.logger.Error("Failed to forward request: ", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Service unavailable"})
-		return
-	}
-	defer resp.Body.Close()
-
-	// Copy response headers
-	for key, values := range resp.Header {
-		for _, value := range values {
-			c.Header(key, value)
-		}
-	}
+	return true
+}
 
-	// Copy response
-	c.Status(resp.StatusCode)
-	io.Copy(c.Writer, resp.Body)
+func (g *Gateway) forwardToService(c *gin.Context, match *RouteMatch) {
+	g.proxies[match.Route.Service].ServeHTTP(c.Writer, c.Request)
 }
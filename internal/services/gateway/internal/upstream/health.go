@@ -0,0 +1,87 @@
+package upstream
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+)
+
+// HealthChecker periodically calls the health check path of every instance of a pool
+type HealthChecker struct {
+	pool       *Pool
+	httpClient *http.Client
+	logger     logger.Logger
+}
+
+func NewHealthChecker(pool *Pool, logger logger.Logger) *HealthChecker {
+	return &HealthChecker{
+		pool: pool,
+		httpClient: &http.Client{
+			Timeout: pool.settings.GetHealthCheckTimeout(),
+		},
+		logger: logger,
+	}
+}
+
+// Run checks the instances until the context is canceled
+func (h *HealthChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.pool.settings.GetHealthCheckInterval())
+	defer ticker.Stop()
+
+	for {
+		h.checkAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *HealthChecker) checkAll(ctx context.Context) {
+	for _, instance := range h.pool.Instances() {
+		healthy := h.check(ctx, instance)
+		if ctx.Err() != nil {
+			return
+		}
+
+		instance.mu.Lock()
+		wasHealthy := instance.healthy
+		if healthy {
+			instance.failedChecks = 0
+			instance.healthy = true
+		} else {
+			instance.failedChecks++
+			if instance.failedChecks >= h.pool.settings.UnhealthyThreshold {
+				instance.healthy = false
+			}
+		}
+		nowHealthy := instance.healthy
+		instance.mu.Unlock()
+
+		if wasHealthy && !nowHealthy {
+			h.logger.Warn("Upstream instance marked unhealthy, service: ", h.pool.name, ", instance: ", instance.URL)
+		} else if !wasHealthy && nowHealthy {
+			h.logger.Info("Upstream instance healthy again, service: ", h.pool.name, ", instance: ", instance.URL)
+		}
+	}
+}
+
+func (h *HealthChecker) check(ctx context.Context, instance *Instance) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		instance.URL.JoinPath(h.pool.settings.HealthCheckPath).String(), nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices
+}
@@ -0,0 +1,167 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hthinh24/go-store/services/gateway/internal/config"
+)
+
+var ErrNoHealthyInstance = errors.New("no healthy upstream instance")
+
+// Instance is a single endpoint of an upstream service
+type Instance struct {
+	URL *url.URL
+
+	activeRequests atomic.Int64
+
+	mu                sync.Mutex
+	healthy           bool // result of active health checks
+	failedChecks      int
+	consecutiveErrors int
+	ejectedUntil      time.Time
+}
+
+// available reports whether the instance may receive requests
+func (i *Instance) available(now time.Time) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.healthy && !now.Before(i.ejectedUntil)
+}
+
+// InstanceSnapshot is the state of an instance exposed to operators
+type InstanceSnapshot struct {
+	URL            string     `json:"url"`
+	Healthy        bool       `json:"healthy"`
+	ActiveRequests int64      `json:"active_requests"`
+	EjectedUntil   *time.Time `json:"ejected_until,omitempty"`
+}
+
+// Snapshot is the state of a pool exposed to operators
+type Snapshot struct {
+	Service   string             `json:"service"`
+	Strategy  string             `json:"strategy"`
+	Instances []InstanceSnapshot `json:"instances"`
+}
+
+// Pool balances requests of an upstream service over its instances
+type Pool struct {
+	name      string
+	settings  config.LoadBalancer
+	instances []*Instance
+	next      atomic.Uint64
+}
+
+func NewPool(name string, urls []string, settings config.LoadBalancer) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no instances configured for service: %s", name)
+	}
+
+	if settings.Strategy != config.StrategyRoundRobin && settings.Strategy != config.StrategyLeastConnections {
+		return nil, fmt.Errorf("unknown load balancing strategy for service %s: %s", name, settings.Strategy)
+	}
+
+	instances := make([]*Instance, 0, len(urls))
+	for _, rawURL := range urls {
+		instanceURL, err := url.Parse(rawURL)
+		if err != nil || instanceURL.Host == "" {
+			return nil, fmt.Errorf("invalid URL for service %s: %s", name, rawURL)
+		}
+
+		// Instances are healthy until a health check says otherwise
+		instances = append(instances, &Instance{URL: instanceURL, healthy: true})
+	}
+
+	return &Pool{
+		name:      name,
+		settings:  settings,
+		instances: instances,
+	}, nil
+}
+
+// Pick selects an available instance according to the pool strategy
+func (p *Pool) Pick() (*Instance, error) {
+	now := time.Now()
+
+	available := make([]*Instance, 0, len(p.instances))
+	for _, instance := range p.instances {
+		if instance.available(now) {
+			available = append(available, instance)
+		}
+	}
+
+	if len(available) == 0 {
+		return nil, ErrNoHealthyInstance
+	}
+
+	start := int(p.next.Add(1) % uint64(len(available)))
+	if p.settings.Strategy == config.StrategyRoundRobin {
+		return available[start], nil
+	}
+
+	// Least connections, starting at the round robin position so ties are spread
+	picked := available[start]
+	for offset := 1; offset < len(available); offset++ {
+		candidate := available[(start+offset)%len(available)]
+		if candidate.activeRequests.Load() < picked.activeRequests.Load() {
+			picked = candidate
+		}
+	}
+
+	return picked, nil
+}
+
+// Record reports the outcome of a request, consecutive 5xx responses or transport
+// errors eject the instance for a while
+func (p *Pool) Record(instance *Instance, failed bool) {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	if !failed {
+		instance.consecutiveErrors = 0
+		return
+	}
+
+	instance.consecutiveErrors++
+	if instance.consecutiveErrors >= p.settings.EjectAfter {
+		instance.consecutiveErrors = 0
+		instance.ejectedUntil = time.Now().Add(p.settings.GetEjectDuration())
+	}
+}
+
+func (p *Pool) Instances() []*Instance {
+	return p.instances
+}
+
+func (p *Pool) Snapshot() Snapshot {
+	now := time.Now()
+
+	snapshot := Snapshot{
+		Service:   p.name,
+		Strategy:  p.settings.Strategy,
+		Instances: make([]InstanceSnapshot, 0, len(p.instances)),
+	}
+
+	for _, instance := range p.instances {
+		instance.mu.Lock()
+		instanceSnapshot := InstanceSnapshot{
+			URL:            instance.URL.String(),
+			Healthy:        instance.healthy,
+			ActiveRequests: instance.activeRequests.Load(),
+		}
+		if now.Before(instance.ejectedUntil) {
+			ejectedUntil := instance.ejectedUntil
+			instanceSnapshot.EjectedUntil = &ejectedUntil
+		}
+		instance.mu.Unlock()
+
+		snapshot.Instances = append(snapshot.Instances, instanceSnapshot)
+	}
+
+	return snapshot
+}
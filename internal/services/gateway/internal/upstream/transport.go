@@ -0,0 +1,70 @@
+package upstream
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Transport sends each request to an instance picked from the pool.
+// Only the scheme and host of the request URL are replaced, instances of a service
+// are expected to serve the same paths.
+type Transport struct {
+	pool *Pool
+	next http.RoundTripper
+}
+
+func NewTransport(pool *Pool, next http.RoundTripper) *Transport {
+	return &Transport{
+		pool: pool,
+		next: next,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	instance, err := t.pool.Pick()
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the request, send a copy
+	outReq := req.Clone(req.Context())
+	outReq.URL.Scheme = instance.URL.Scheme
+	outReq.URL.Host = instance.URL.Host
+	outReq.Host = ""
+
+	instance.activeRequests.Add(1)
+	resp, err := t.next.RoundTrip(outReq)
+	if err != nil {
+		instance.activeRequests.Add(-1)
+		// A request canceled by the client says nothing about the instance
+		if req.Context().Err() == nil {
+			t.pool.Record(instance, true)
+		}
+		return nil, err
+	}
+
+	t.pool.Record(instance, resp.StatusCode >= http.StatusInternalServerError)
+
+	// Upgraded connections need the writable body, they are not counted as active
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		instance.activeRequests.Add(-1)
+		return resp, nil
+	}
+
+	// The request is active until its response body is closed
+	resp.Body = &trackedBody{ReadCloser: resp.Body, done: func() { instance.activeRequests.Add(-1) }}
+	return resp, nil
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *trackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
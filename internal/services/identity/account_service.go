@@ -0,0 +1,26 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+)
+
+type AccountService interface {
+	// SendVerificationEmail mails a new verification link to a pending user
+	SendVerificationEmail(ctx context.Context, user *entity.User) error
+	// ResendVerificationEmail never tells whether the email belongs to a pending user
+	ResendVerificationEmail(ctx context.Context, request request.EmailRequest) error
+	VerifyEmail(ctx context.Context, request request.VerifyEmailRequest) error
+	// RequestEmailChange mails a confirmation link to the new email, the user keeps the old
+	// email until the link is used
+	RequestEmailChange(ctx context.Context, user *entity.User, newEmail string) error
+	// ConfirmEmailChange replaces the email of the user with the confirmed one and invalidates
+	// the tokens mailed to the old email
+	ConfirmEmailChange(ctx context.Context, request request.VerifyEmailRequest) error
+	// RequestPasswordReset never tells whether the email belongs to a user
+	RequestPasswordReset(ctx context.Context, request request.EmailRequest) error
+	// ResetPassword sets the new password and ends every session of the user
+	ResetPassword(ctx context.Context, request request.ResetPasswordRequest) error
+}
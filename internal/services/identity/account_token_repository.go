@@ -0,0 +1,14 @@
+package identity
+
+import "github.com/hthinh24/go-store/services/identity/internal/entity"
+
+type AccountTokenRepository interface {
+	// CreateAccountToken stores the token and invalidates the unused tokens of the user for
+	// the same purpose, only the latest email a user got works
+	CreateAccountToken(token *entity.AccountToken) error
+	// UseAccountToken marks an unused, unexpired token as used and returns it, it fails with
+	// ErrInvalidAccountToken otherwise so a token works exactly once
+	UseAccountToken(tokenHash string, purpose entity.AccountTokenPurpose) (*entity.AccountToken, error)
+	// InvalidateAccountTokens marks the unused tokens of the user for the purposes as used
+	InvalidateAccountTokens(userID int64, purposes ...entity.AccountTokenPurpose) error
+}
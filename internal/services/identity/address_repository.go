@@ -0,0 +1,17 @@
+package identity
+
+import "github.com/hthinh24/go-store/services/identity/internal/entity"
+
+type AddressRepository interface {
+	FindAddressesByUserID(userID int64) (*[]entity.UserAddress, error)
+	// FindAddressByID returns the address only when it belongs to the user
+	FindAddressByID(userID int64, id int64) (*entity.UserAddress, error)
+	// CreateAddress adds the address unless the user already has maxPerUser addresses, the
+	// first address of a user becomes the default shipping and billing address
+	CreateAddress(address *entity.UserAddress, maxPerUser int) error
+	// UpdateAddress saves the address, the default flags it sets are taken from the other
+	// addresses
+	UpdateAddress(address *entity.UserAddress) error
+	// DeleteAddress removes the address, its default flags move to the latest other address
+	DeleteAddress(address *entity.UserAddress) error
+}
@@ -0,0 +1,17 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+)
+
+// AddressService manages the address book of the authenticated user
+type AddressService interface {
+	GetAddresses(ctx context.Context, userID int64) (*[]response.AddressResponse, error)
+	GetAddress(ctx context.Context, userID int64, id int64) (*response.AddressResponse, error)
+	CreateAddress(ctx context.Context, userID int64, data request.AddressRequest) (*response.AddressResponse, error)
+	UpdateAddress(ctx context.Context, userID int64, id int64, data request.AddressRequest) (*response.AddressResponse, error)
+	DeleteAddress(ctx context.Context, userID int64, id int64) error
+}
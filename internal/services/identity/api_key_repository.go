@@ -0,0 +1,19 @@
+package identity
+
+import (
+	"time"
+
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(key *entity.APIKey) error
+	// FindAPIKeysByUserID returns every key of the user, revoked and expired ones included
+	FindAPIKeysByUserID(userID int64) (*[]entity.APIKey, error)
+	// FindAPIKeyByID returns the key only when it belongs to the user
+	FindAPIKeyByID(userID int64, id int64) (*entity.APIKey, error)
+	FindAPIKeyByHash(keyHash string) (*entity.APIKey, error)
+	// RevokeAPIKey does nothing when the key was revoked already
+	RevokeAPIKey(key *entity.APIKey) error
+	UpdateAPIKeyLastUsed(id int64, usedAt time.Time) error
+}
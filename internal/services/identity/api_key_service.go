@@ -0,0 +1,20 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+)
+
+// APIKeyService manages the API keys of the authenticated user and verifies keys for the gateway
+type APIKeyService interface {
+	GetAPIKeys(ctx context.Context, userID int64) (*[]response.APIKeyResponse, error)
+	// CreateAPIKey only grants scopes held by both the user and the caller, callerPermissions are
+	// the permissions of the access token the request was made with
+	CreateAPIKey(ctx context.Context, userID int64, callerPermissions []string, data request.CreateAPIKeyRequest) (*response.CreatedAPIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, userID int64, id int64) error
+	// VerifyAPIKey returns the user of an active key with the scopes the user still holds, and
+	// no roles so role checks never pass for a key
+	VerifyAPIKey(ctx context.Context, key string) (*response.VerifyResponse, error)
+}
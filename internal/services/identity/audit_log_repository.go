@@ -0,0 +1,7 @@
+package identity
+
+import "github.com/hthinh24/go-store/services/identity/internal/entity"
+
+type AuditLogRepository interface {
+	CreateAuditLog(auditLog *entity.AuditLog) error
+}
@@ -3,10 +3,28 @@ package identity
 import "github.com/hthinh24/go-store/services/identity/internal/entity"
 
 type AuthRepository interface {
+	FindRoles() (*[]entity.Role, error)
 	FindRoleByName(name string) (*entity.Role, error)
+	CreateRole(role *entity.Role) error
+	UpdateRole(role *entity.Role) error
+	UpdateRoleMFARequired(name string, required bool) error
+	// DeleteRole also removes the role from its users and its permissions
+	DeleteRole(id int64) error
+
+	FindPermissions() (*[]entity.Permission, error)
+	FindPermissionByName(name string) (*entity.Permission, error)
+	CreatePermission(permission *entity.Permission) error
+	UpdatePermission(permission *entity.Permission) error
+	// DeletePermission also removes the permission from every role
+	DeletePermission(id int64) error
+
 	FindAllUserRolesByUserID(userID int64) (*[]entity.Role, error)
 	FindAllPermissionsByRoleNames(roleNames []string) (*[]entity.Permission, error)
 	FindAllPermissionsByRoleIDs(roleIDs []int64) (*[]entity.Permission, error)
-	FindPermissionByName(name string) (*entity.Permission, error)
+	// AddRoleToUser and AddPermissionToRole do nothing when the grant exists, removals of
+	// missing grants do nothing as well
 	AddRoleToUser(userRole *entity.UserRoles) error
+	RemoveRoleFromUser(userID int64, roleID int64) error
+	AddPermissionToRole(rolePermission *entity.RolePermissions) error
+	RemovePermissionFromRole(roleID int64, permissionID int64) error
 }
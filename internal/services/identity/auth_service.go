@@ -1,12 +1,30 @@
 package identity
 
 import (
+	"context"
+
+	"github.com/hthinh24/go-store/internal/pkg/jwks"
 	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
 	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
 )
 
 type AuthService interface {
-	Login(request request.AuthRequest) (*response.AuthResponse, error)
-	Logout(token string) error
-	Verify(token string) (*response.VerifyResponse, error)
+	// Login checks the password, failures are counted per account and per client IP
+	Login(ctx context.Context, request request.AuthRequest, clientIP string) (*response.AuthResponse, error)
+	Refresh(ctx context.Context, request request.RefreshTokenRequest) (*response.AuthResponse, error)
+	Logout(ctx context.Context, token string, request request.LogoutRequest) error
+	LogoutAll(ctx context.Context, userID int64) error
+	Verify(ctx context.Context, token string) (*response.VerifyResponse, error)
+	// FindUserPermissions returns the distinct permission names of the roles of the user
+	FindUserPermissions(ctx context.Context, userID int64) ([]string, error)
+	// AuthenticateUser logs in a user whose first factor was checked, it returns an MFA
+	// challenge instead of tokens when MFA is enabled or required
+	AuthenticateUser(ctx context.Context, user *entity.User) (*response.AuthResponse, error)
+	// IssueTokens starts a session for a user that was already authenticated
+	IssueTokens(ctx context.Context, user *entity.User) (*response.AuthResponse, error)
+	// UnlockUser ends the lockout of a user, adminID is recorded in the audit log
+	UnlockUser(ctx context.Context, userID int64, adminID int64) error
+	// KeySet returns the public keys access tokens are verified with
+	KeySet() (jwks.Set, error)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"github.com/hthinh24/go-store/services/identity"
 	config "github.com/hthinh24/go-store/services/identity/internal/config"
@@ -9,8 +10,13 @@ import (
 	v1 "github.com/hthinh24/go-store/services/identity/internal/controller/http/v1"
 	"github.com/hthinh24/go-store/services/identity/internal/entity"
 	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"github.com/hthinh24/go-store/services/identity/internal/keys"
+	"github.com/hthinh24/go-store/services/identity/internal/mfa"
 	"github.com/hthinh24/go-store/services/identity/internal/middleware"
+	"github.com/hthinh24/go-store/services/identity/internal/oidc"
+	"github.com/hthinh24/go-store/services/identity/internal/outbox"
 	repository "github.com/hthinh24/go-store/services/identity/internal/repository/postgres"
+	redisRepository "github.com/hthinh24/go-store/services/identity/internal/repository/redis"
 	"github.com/hthinh24/go-store/services/identity/internal/service"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
@@ -18,8 +24,19 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/health"
+	"github.com/hthinh24/go-store/internal/pkg/jwks"
 	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/mail"
+	"github.com/hthinh24/go-store/internal/pkg/metrics"
+	"github.com/hthinh24/go-store/internal/pkg/middleware/policy"
+	"github.com/hthinh24/go-store/internal/pkg/middleware/requestid"
+	"github.com/hthinh24/go-store/internal/pkg/middleware/serviceauth"
+	"github.com/hthinh24/go-store/internal/pkg/tracing"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	gormTracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
 func main() {
@@ -36,41 +53,136 @@ func main() {
 	appLogger.Info("Starting Identity Service...")
 	appLogger.Info("Environment: %s", cfg.GetEnvironment())
 
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(cfg.ServiceName, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Initialize metrics
+	appMetrics := metrics.New(cfg.ServiceName)
+
+	// Initialize Redis, it holds the access token revocations
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:         cfg.GetRedisAddress(),
+		Password:     cfg.GetRedisPassword(),
+		DB:           cfg.Redis.DB,
+		PoolSize:     cfg.Redis.PoolSize,
+		MinIdleConns: cfg.Redis.MinIdleConns,
+		DialTimeout:  time.Duration(cfg.Redis.DialTimeout) * time.Second,
+		ReadTimeout:  time.Duration(cfg.Redis.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(cfg.Redis.WriteTimeout) * time.Second,
+	})
+	if err := redisotel.InstrumentTracing(redisClient); err != nil {
+		log.Fatalf("Failed to instrument redis: %v", err)
+	}
+
 	// Initialize database connection
-	db, err := initDatabase(cfg)
+	db, err := initDatabase(cfg, appMetrics)
 	if err != nil {
 		appLogger.Error("Failed to connect to database: %v", err)
 		log.Fatal(err)
 	}
 	appLogger.Info("Database connected successfully")
 
+	// Initialize health checks
+	appHealth, err := initHealth(cfg, db, redisClient)
+	if err != nil {
+		appLogger.Error("Failed to initialize health checks: %v", err)
+		log.Fatal(err)
+	}
+
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(logger.WithComponent(cfg.GetLogLevel(), "USER-REPOSITORY"), db)
 	authRepo := repository.NewAuthRepository(logger.WithComponent(cfg.GetLogLevel(), "AUTH-REPOSITORY"), db)
-
-	// Initialize external service clients
-	var cartClient client.CartClient
+	refreshTokenRepo := repository.NewRefreshTokenRepository(logger.WithComponent(cfg.GetLogLevel(), "REFRESH-TOKEN-REPOSITORY"), db)
+	accountTokenRepo := repository.NewAccountTokenRepository(logger.WithComponent(cfg.GetLogLevel(), "ACCOUNT-TOKEN-REPOSITORY"), db)
+	revocationRepo := redisRepository.NewTokenRevocationRepository(logger.WithComponent(cfg.GetLogLevel(), "TOKEN-REVOCATION-REPOSITORY"), redisClient)
+	oidcStateRepo := redisRepository.NewOIDCStateRepository(logger.WithComponent(cfg.GetLogLevel(), "OIDC-STATE-REPOSITORY"), redisClient)
+	auditLogRepo := repository.NewAuditLogRepository(logger.WithComponent(cfg.GetLogLevel(), "AUDIT-LOG-REPOSITORY"), db)
+	loginAttemptRepo := redisRepository.NewLoginAttemptRepository(logger.WithComponent(cfg.GetLogLevel(), "LOGIN-ATTEMPT-REPOSITORY"), redisClient)
+	mfaRepo := repository.NewMFARepository(logger.WithComponent(cfg.GetLogLevel(), "MFA-REPOSITORY"), db)
+	mfaChallengeRepo := redisRepository.NewMFAChallengeRepository(logger.WithComponent(cfg.GetLogLevel(), "MFA-CHALLENGE-REPOSITORY"), redisClient)
+	permissionCacheRepo := redisRepository.NewPermissionCacheRepository(logger.WithComponent(cfg.GetLogLevel(), "PERMISSION-CACHE-REPOSITORY"), redisClient)
+	merchantApplicationRepo := repository.NewMerchantApplicationRepository(logger.WithComponent(cfg.GetLogLevel(), "MERCHANT-APPLICATION-REPOSITORY"), db)
+	apiKeyRepo := repository.NewAPIKeyRepository(logger.WithComponent(cfg.GetLogLevel(), "API-KEY-REPOSITORY"), db)
+	addressRepo := repository.NewAddressRepository(logger.WithComponent(cfg.GetLogLevel(), "ADDRESS-REPOSITORY"), db)
+	outboxRepo := repository.NewOutboxRepository(logger.WithComponent(cfg.GetLogLevel(), "OUTBOX-REPOSITORY"), db)
+
+	// Initialize the outbox relay, it delivers the side effects of registrations
+	relay := outbox.NewRelay(logger.WithComponent(cfg.GetLogLevel(), "OUTBOX-RELAY"), outboxRepo, cfg.Outbox)
 	if cfg.GetCartServiceURL() != "" {
-		cartClient = client.NewCartClient(cfg.GetServiceURL("cart"))
+		// The cart service only creates carts for signed requests of the identity service
+		cartTransport, err := serviceauth.NewTransport(cfg.ServiceName, cfg.ServiceAuth, appMetrics.NewTransport("cart", tracing.NewTransport(nil)))
+		if err != nil {
+			appLogger.Error("Failed to initialize service auth: %v", err)
+			log.Fatal(err)
+		}
+		cartClient := client.NewCartClient(cfg.GetServiceURL("cart"), cartTransport)
+		relay.Handle(entity.OutboxEventUserRegistered, outbox.CreateCart(cartClient))
 		appLogger.Info("Cart service client initialized with URL: %s", cfg.GetCartServiceURL())
 	} else {
 		appLogger.Warn("Cart service URL not configured, cart creation will be skipped")
-		cartClient = client.NewCartClient("") // This will effectively disable cart creation
 	}
 
+	// Initialize token signing keys, development setups get a generated key
+	keyManager, err := keys.LoadKeyManager(cfg.JWT, cfg.IsDevelopment())
+	if err != nil {
+		appLogger.Error("Failed to load signing keys: %v", err)
+		log.Fatal(err)
+	}
+
+	// Initialize the cipher of the TOTP secrets
+	mfaCipher, err := mfa.NewCipher(cfg.MFA.EncryptionKey)
+	if err != nil {
+		appLogger.Error("Failed to initialize MFA cipher: %v", err)
+		log.Fatal(err)
+	}
+
+	// Initialize mail sender, development setups log or write mails to a file
+	mailSender, err := mail.New(cfg.Mail, logger.WithComponent(cfg.GetLogLevel(), "MAIL"))
+	if err != nil {
+		appLogger.Error("Failed to initialize mail sender: %v", err)
+		log.Fatal(err)
+	}
+
+	// Initialize external identity providers
+	oidcProviders := initOIDCProviders(cfg, appMetrics)
+
 	// Initialize services
-	authService := service.NewAuthService(logger.WithComponent(cfg.GetLogLevel(), "AUTH-SERVICE"), userRepo, authRepo, cfg)
-	userService := service.NewUserService(logger.WithComponent(cfg.GetLogLevel(), "USER-SERVICE"), userRepo, authRepo, cartClient)
+	authService := service.NewAuthService(logger.WithComponent(cfg.GetLogLevel(), "AUTH-SERVICE"), userRepo, authRepo, refreshTokenRepo, revocationRepo, mfaRepo, mfaChallengeRepo, loginAttemptRepo, auditLogRepo, permissionCacheRepo, keyManager, cfg)
+	accountService := service.NewAccountService(logger.WithComponent(cfg.GetLogLevel(), "ACCOUNT-SERVICE"), userRepo, accountTokenRepo, authService, mailSender, cfg)
+	userService := service.NewUserService(logger.WithComponent(cfg.GetLogLevel(), "USER-SERVICE"), userRepo, authRepo, accountService)
+	oidcService := service.NewOIDCService(logger.WithComponent(cfg.GetLogLevel(), "OIDC-SERVICE"), oidcProviders, oidcStateRepo, userRepo, userService, authService, cfg)
+	mfaService := service.NewMFAService(logger.WithComponent(cfg.GetLogLevel(), "MFA-SERVICE"), mfaRepo, mfaChallengeRepo, userRepo, authRepo, auditLogRepo, authService, mfaCipher, cfg)
+	merchantService := service.NewMerchantService(logger.WithComponent(cfg.GetLogLevel(), "MERCHANT-SERVICE"), merchantApplicationRepo, userRepo, authRepo)
+	apiKeyService := service.NewAPIKeyService(logger.WithComponent(cfg.GetLogLevel(), "API-KEY-SERVICE"), apiKeyRepo, userRepo, auditLogRepo, authService)
+	addressService := service.NewAddressService(logger.WithComponent(cfg.GetLogLevel(), "ADDRESS-SERVICE"), addressRepo, cfg.Address)
+	roleService := service.NewRoleService(logger.WithComponent(cfg.GetLogLevel(), "ROLE-SERVICE"), authRepo, userRepo, auditLogRepo, permissionCacheRepo)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(logger.WithComponent(cfg.GetLogLevel(), "AUTH-MIDDLEWARE"), cfg.GetJWTSecret())
+	authMiddleware := middleware.NewAuthMiddleware(logger.WithComponent(cfg.GetLogLevel(), "AUTH-MIDDLEWARE"), keyManager.Keyfunc(), revocationRepo)
+	policyMiddleware := policy.NewPolicyMiddleware(logger.WithComponent(cfg.GetLogLevel(), "POLICY-MIDDLEWARE"))
 
 	// Initialize controllers
-	authController := v1.NewAuthController(logger.WithComponent(cfg.GetLogLevel(), "AUTH-CONTROLLER"), authService)
+	authController := v1.NewAuthController(logger.WithComponent(cfg.GetLogLevel(), "AUTH-CONTROLLER"), authService, apiKeyService)
 	userController := v1.NewUserController(logger.WithComponent(cfg.GetLogLevel(), "USER-CONTROLLER"), userService)
+	oidcController := v1.NewOIDCController(logger.WithComponent(cfg.GetLogLevel(), "OIDC-CONTROLLER"), oidcService)
+	accountController := v1.NewAccountController(logger.WithComponent(cfg.GetLogLevel(), "ACCOUNT-CONTROLLER"), accountService)
+	mfaController := v1.NewMFAController(logger.WithComponent(cfg.GetLogLevel(), "MFA-CONTROLLER"), mfaService)
+	merchantController := v1.NewMerchantController(logger.WithComponent(cfg.GetLogLevel(), "MERCHANT-CONTROLLER"), merchantService)
+	roleController := v1.NewRoleController(logger.WithComponent(cfg.GetLogLevel(), "ROLE-CONTROLLER"), roleService)
+	apiKeyController := v1.NewAPIKeyController(logger.WithComponent(cfg.GetLogLevel(), "API-KEY-CONTROLLER"), apiKeyService)
+	addressController := v1.NewAddressController(logger.WithComponent(cfg.GetLogLevel(), "ADDRESS-CONTROLLER"), addressService)
 
 	// Setup router
-	router := setupRouter(authController, userController, authMiddleware)
+	router := setupRouter(authController, userController, oidcController, accountController, mfaController, roleController, merchantController, addressController, apiKeyController, authMiddleware, policyMiddleware, cfg, appMetrics, appHealth)
+	// Logins are counted per client IP, only the gateway may tell it with X-Forwarded-For
+	if err := router.SetTrustedProxies(cfg.LoginProtection.TrustedProxies); err != nil {
+		appLogger.Error("Invalid trusted proxies: %v", err)
+		log.Fatal(err)
+	}
 
 	// Initialize user data
 	if err := initUserData(userRepo, authRepo); err != nil {
@@ -80,6 +192,8 @@ func main() {
 		appLogger.Info("User data initialized successfully")
 	}
 
+	go relay.Run(context.Background())
+
 	// Start server
 	serverAddr := cfg.GetServerAddress()
 	appLogger.Info("Server starting on %s", serverAddr)
@@ -89,23 +203,69 @@ func main() {
 	}
 }
 
-func initDatabase(cfg *config.AppConfig) (*gorm.DB, error) {
+func initDatabase(cfg *config.AppConfig, appMetrics *metrics.Metrics) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(cfg.GetDatabaseURL()), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
 
+	// Trace every query, spans are children of the request span when the context is passed
+	if err := db.Use(gormTracing.NewPlugin(gormTracing.WithoutMetrics())); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	appMetrics.RegisterDB(cfg.PG.Database, sqlDB)
+
 	return db, nil
 }
 
-func setupRouter(authController *v1.AuthController, userController *v1.UserController, authMiddleware *middleware.AuthMiddleware) *gin.Engine {
-	router := gin.Default()
+func initHealth(cfg *config.AppConfig, db *gorm.DB, redisClient *redis.Client) (*health.Health, error) {
+	appHealth := health.New(cfg.Health)
 
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "healthy"})
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	appHealth.AddCheck("postgres", health.DBCheck(sqlDB))
+	appHealth.AddCheck("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
 	})
 
+	if err := appHealth.AddServiceChecks(cfg.Services); err != nil {
+		return nil, err
+	}
+
+	return appHealth, nil
+}
+
+func initOIDCProviders(cfg *config.AppConfig, appMetrics *metrics.Metrics) map[string]*oidc.Provider {
+	providers := make(map[string]*oidc.Provider, len(cfg.OIDC.Providers))
+	for name, providerConfig := range cfg.OIDC.Providers {
+		transport := appMetrics.NewTransport("oidc_"+name, tracing.NewTransport(nil))
+		providers[name] = oidc.NewProvider(logger.WithComponent(cfg.GetLogLevel(), "OIDC-PROVIDER"), name, providerConfig, transport)
+	}
+
+	return providers
+}
+
+func setupRouter(authController *v1.AuthController, userController *v1.UserController, oidcController *v1.OIDCController, accountController *v1.AccountController, mfaController *v1.MFAController, roleController *v1.RoleController, merchantController *v1.MerchantController, addressController *v1.AddressController, apiKeyController *v1.APIKeyController, authMiddleware *middleware.AuthMiddleware, policyMiddleware *policy.PolicyMiddleware, cfg *config.AppConfig, appMetrics *metrics.Metrics, appHealth *health.Health) *gin.Engine {
+	router := gin.Default()
+	router.Use(requestid.RequestID())
+	router.Use(tracing.Middleware(cfg.ServiceName))
+	router.Use(appMetrics.Middleware())
+
+	appMetrics.Register(router)
+
+	// Liveness and readiness probes
+	appHealth.Register(router)
+
+	// Public keys of the access tokens
+	router.GET(jwks.Path, authController.JWKS())
+
 	// API routes
 	api := router.Group("/api/v1")
 	{
@@ -117,29 +277,105 @@ func setupRouter(authController *v1.AuthController, userController *v1.UserContr
 			users.POST("", userController.CreateUser())
 
 			auth.POST("/login", authController.Login())
+			auth.POST("/refresh", authController.Refresh())
 			auth.GET("/verify", authController.Verify())
+
+			// Login with an external identity provider
+			auth.GET("/oidc/:provider/login", oidcController.Login())
+			auth.GET("/oidc/:provider/callback", oidcController.Callback())
+
+			// Email verification and password reset, tokens are mailed to the user
+			auth.POST("/verify-email", accountController.VerifyEmail())
+			auth.POST("/verify-email/resend", accountController.ResendVerification())
+			auth.POST("/email/confirm", accountController.ConfirmEmailChange())
+			auth.POST("/password/forgot", accountController.ForgotPassword())
+			auth.POST("/password/reset", accountController.ResetPassword())
+
+			// Second login step, the mfa_token of the login response proves the password
+			auth.POST("/mfa/verify", mfaController.Verify())
+			auth.POST("/mfa/setup", mfaController.Setup())
+			auth.POST("/mfa/setup/confirm", mfaController.ConfirmSetup())
 		}
 
 		auth.Use(authMiddleware.AuthRequired())
 		{
-			// TODO - Create login, register, logout endpoints
+			// TODO - Create register endpoint
 			//auth.POST("/register", authController.Register())
-			//auth.POST("/logout", authMiddleware.AuthRequired(), authController.Logout())
+			auth.POST("/logout", authController.Logout())
+			auth.POST("/logout-all", authController.LogoutAll())
+
+			auth.POST("/mfa/enroll", mfaController.Enroll())
+			auth.POST("/mfa/enroll/confirm", mfaController.ConfirmEnrollment())
+			auth.POST("/mfa/disable", mfaController.Disable())
 		}
 
 		// User routes (protected)
 		users.Use(authMiddleware.AuthRequired())
 		{
-			users.GET(":id", userController.GetUserByID())
-
-			users.PUT("/:id/profile", userController.UpdateUserProfile())
-			users.PATCH("/:id/register-merchant",
-				userController.UpdateToMerchantAccount())
-			users.PATCH("/:id/password", userController.UpdateUserPassword())
-			users.DELETE("/:id", userController.DeleteUser())
+			// Users act on their own account, user managers on any
+			selfOrManager := policyMiddleware.Require(policy.SelfOrPermission("id", string(constants.PermissionUserManage)))
+
+			users.GET(":id", selfOrManager, userController.GetUserByID())
+
+			// Address book of the authenticated user
+			users.GET("/me/addresses", addressController.GetAddresses())
+			users.POST("/me/addresses", addressController.CreateAddress())
+			users.GET("/me/addresses/:addressID", addressController.GetAddress())
+			users.PUT("/me/addresses/:addressID", addressController.UpdateAddress())
+			users.DELETE("/me/addresses/:addressID", addressController.DeleteAddress())
+
+			// API keys of the authenticated user
+			users.GET("/me/api-keys", apiKeyController.GetAPIKeys())
+			users.POST("/me/api-keys", apiKeyController.CreateAPIKey())
+			users.DELETE("/me/api-keys/:keyID", apiKeyController.RevokeAPIKey())
+
+			users.PUT("/:id/profile", selfOrManager, userController.UpdateUserProfile())
+			users.POST("/:id/merchant-application", policyMiddleware.Require(policy.Self("id")), merchantController.SubmitApplication())
+			users.GET("/:id/merchant-application", selfOrManager, merchantController.GetUserApplication())
+			// The current password is required, so only the user can change it
+			users.PATCH("/:id/password", policyMiddleware.Require(policy.Self("id")), userController.UpdateUserPassword())
+			users.DELETE("/:id", selfOrManager, userController.DeleteUser())
 
 			// Admin only routes
 			users.GET("", authMiddleware.RequireRole("admin"), userController.GetUsers())
+			users.POST("/:id/unlock", authMiddleware.RequireRole("admin"), authController.UnlockUser())
+			users.GET("/:id/roles", authMiddleware.RequireRole("admin"), roleController.GetUserRoles())
+			users.POST("/:id/roles", authMiddleware.RequireRole("admin"), roleController.AssignRoleToUser())
+			users.DELETE("/:id/roles/:role", authMiddleware.RequireRole("admin"), roleController.RevokeRoleFromUser())
+		}
+
+		// Role routes (admin only)
+		roles := api.Group("/roles")
+		roles.Use(authMiddleware.AuthRequired(), authMiddleware.RequireRole("admin"))
+		{
+			roles.GET("", roleController.GetRoles())
+			roles.POST("", roleController.CreateRole())
+			roles.GET("/:name", roleController.GetRole())
+			roles.PUT("/:name", roleController.UpdateRole())
+			roles.DELETE("/:name", roleController.DeleteRole())
+			roles.POST("/:name/permissions", roleController.AddPermissionToRole())
+			roles.DELETE("/:name/permissions/:permission", roleController.RemovePermissionFromRole())
+			roles.PUT("/:name/mfa", mfaController.RequireForRole())
+		}
+
+		// Merchant application routes (admin only)
+		merchantApplications := api.Group("/merchant-applications")
+		merchantApplications.Use(authMiddleware.AuthRequired(), authMiddleware.RequireRole("admin"))
+		{
+			merchantApplications.GET("", merchantController.GetApplications())
+			merchantApplications.GET("/:id", merchantController.GetApplication())
+			merchantApplications.POST("/:id/review", merchantController.StartReview())
+			merchantApplications.POST("/:id/decision", merchantController.DecideApplication())
+		}
+
+		// Permission routes (admin only)
+		permissions := api.Group("/permissions")
+		permissions.Use(authMiddleware.AuthRequired(), authMiddleware.RequireRole("admin"))
+		{
+			permissions.GET("", roleController.GetPermissions())
+			permissions.POST("", roleController.CreatePermission())
+			permissions.PUT("/:name", roleController.UpdatePermission())
+			permissions.DELETE("/:name", roleController.DeletePermission())
 		}
 	}
 
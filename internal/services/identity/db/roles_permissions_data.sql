@@ -21,7 +21,8 @@ INSERT INTO permissions (id, name, description) VALUES
 
 -- System Administration Permissions
 (14, 'system.admin', 'Full system administration access'),
-(15, 'role.manage', 'Manage roles and permissions');
+(15, 'role.manage', 'Manage roles and permissions'),
+(16, 'user.manage', 'Manage the accounts of other users');
 
 -- Insert Roles for Ecommerce Platform
 INSERT INTO roles VALUES
@@ -33,7 +34,8 @@ INSERT INTO roles VALUES
 -- Role Permission Assignments
 -- Admin - All permissions
 INSERT INTO role_permissions (role_id, permission_id) VALUES
-(1, 14); -- System admin permission
+(1, 14), -- System admin permission
+(1, 16); -- Manage other users
 
 -- Staff - Limited management permissions
 INSERT INTO role_permissions (role_id, permission_id) VALUES
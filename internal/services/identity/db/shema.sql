@@ -12,6 +12,7 @@ CREATE TABLE IF NOT EXISTS users
     phone_number  varchar(20)  NOT NULL,
     date_of_birth timestamp    NOT NULL,
     status        varchar(255) NOT NULL,
+    email_verified_at timestamp,
     created_by    varchar(255) NOT NULL,
     updated_by    varchar(255) NOT NULL,
     created_at    timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -23,15 +24,16 @@ CREATE TABLE IF NOT EXISTS users
 CREATE TABLE IF NOT EXISTS roles
 (
     id          BIGSERIAL    NOT NULL,
-    name        varchar(255) NOT NULL,
+    name        varchar(255) NOT NULL UNIQUE,
     description varchar(255) NOT NULL,
+    mfa_required boolean     NOT NULL DEFAULT false,
     PRIMARY KEY (id)
 );
 
 CREATE TABLE IF NOT EXISTS permissions
 (
     id          BIGSERIAL    NOT NULL,
-    name        varchar(255) NOT NULL,
+    name        varchar(255) NOT NULL UNIQUE,
     description varchar(255) NOT NULL,
     PRIMARY KEY (id)
 );
@@ -50,11 +52,177 @@ CREATE TABLE IF NOT EXISTS role_permissions
     PRIMARY KEY (role_id, permission_id)
 );
 
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    id         BIGSERIAL    NOT NULL,
+    user_id    int8         NOT NULL,
+    family_id  varchar(36)  NOT NULL,
+    token_hash varchar(64)  NOT NULL UNIQUE,
+    expires_at timestamp    NOT NULL,
+    revoked_at timestamp,
+    created_at timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+
+CREATE TABLE IF NOT EXISTS account_tokens
+(
+    id         BIGSERIAL    NOT NULL,
+    user_id    int8         NOT NULL,
+    purpose    varchar(32)  NOT NULL,
+    token_hash varchar(64)  NOT NULL UNIQUE,
+    email      varchar(255),
+    expires_at timestamp    NOT NULL,
+    used_at    timestamp,
+    created_at timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_tokens_user_id_purpose ON account_tokens (user_id, purpose);
+
+CREATE TABLE IF NOT EXISTS user_mfa
+(
+    user_id        int8         NOT NULL,
+    secret         varchar(255) NOT NULL,
+    confirmed_at   timestamp,
+    last_used_step int8         NOT NULL DEFAULT 0,
+    created_at     timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes
+(
+    id         BIGSERIAL   NOT NULL,
+    user_id    int8        NOT NULL,
+    code_hash  varchar(64) NOT NULL,
+    used_at    timestamp,
+    created_at timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS audit_logs
+(
+    id         BIGSERIAL    NOT NULL,
+    event      varchar(64)  NOT NULL,
+    user_id    int8,
+    actor_id   int8,
+    ip_address varchar(64),
+    details    varchar(255),
+    created_at timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id);
+
+CREATE TABLE IF NOT EXISTS outbox_events
+(
+    id              BIGSERIAL   NOT NULL,
+    event_type      varchar(64) NOT NULL,
+    aggregate_id    int8        NOT NULL,
+    payload         text        NOT NULL,
+    attempts        int4        NOT NULL DEFAULT 0,
+    next_attempt_at timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error      varchar(512),
+    processed_at    timestamp,
+    created_at      timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_due ON outbox_events (next_attempt_at) WHERE processed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS merchant_applications
+(
+    id               BIGSERIAL    NOT NULL,
+    user_id          int8         NOT NULL,
+    business_name    varchar(255) NOT NULL,
+    tax_id           varchar(64)  NOT NULL,
+    contact_name     varchar(255) NOT NULL,
+    contact_email    varchar(255) NOT NULL,
+    contact_phone    varchar(32)  NOT NULL,
+    business_address varchar(512) NOT NULL,
+    status           varchar(32)  NOT NULL,
+    reviewer_id      int8,
+    decision_reason  varchar(512),
+    decided_at       timestamp,
+    created_at       timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_applications_user_id ON merchant_applications (user_id);
+CREATE INDEX IF NOT EXISTS idx_merchant_applications_status ON merchant_applications (status);
+-- A user has at most one application waiting for a decision
+CREATE UNIQUE INDEX IF NOT EXISTS uq_merchant_applications_open ON merchant_applications (user_id)
+    WHERE status IN ('SUBMITTED', 'UNDER_REVIEW');
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           BIGSERIAL    NOT NULL,
+    user_id      int8         NOT NULL,
+    name         varchar(100) NOT NULL,
+    prefix       varchar(16)  NOT NULL,
+    key_hash     varchar(64)  NOT NULL UNIQUE,
+    scopes       text         NOT NULL,
+    expires_at   timestamp,
+    last_used_at timestamp,
+    revoked_at   timestamp,
+    created_at   timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS user_addresses
+(
+    id                  BIGSERIAL    NOT NULL,
+    user_id             int8         NOT NULL,
+    full_name           varchar(255) NOT NULL,
+    phone               varchar(20)  NOT NULL,
+    address_line        varchar(500) NOT NULL,
+    city                varchar(100) NOT NULL,
+    state               varchar(100) NOT NULL DEFAULT '',
+    postal_code         varchar(20)  NOT NULL,
+    country             varchar(2)   NOT NULL,
+    is_default_shipping bool         NOT NULL DEFAULT FALSE,
+    is_default_billing  bool         NOT NULL DEFAULT FALSE,
+    created_at          timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_addresses_user_id ON user_addresses (user_id);
+-- A user has at most one default shipping and one default billing address
+CREATE UNIQUE INDEX IF NOT EXISTS uq_user_addresses_default_shipping ON user_addresses (user_id)
+    WHERE is_default_shipping;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_user_addresses_default_billing ON user_addresses (user_id)
+    WHERE is_default_billing;
+
+CREATE INDEX IF NOT EXISTS idx_users_provider ON users (provider_name, provider_id);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_users_status ON users (status);
+
 ALTER TABLE user_roles
     ADD CONSTRAINT FKuser_has_r352169 FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
 ALTER TABLE user_roles
     ADD CONSTRAINT FKuser_has_r355910 FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE;
 ALTER TABLE role_permissions
-    ADD CONSTRAINT FKrole_has_p648170 FOREIGN KEY (permission_id) REFERENCES permissions (id) ON DELETE SET NULL;
+    ADD CONSTRAINT FKrole_has_p648170 FOREIGN KEY (permission_id) REFERENCES permissions (id) ON DELETE CASCADE;
 ALTER TABLE role_permissions
-    ADD CONSTRAINT FKrole_has_p131704 FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE SET NULL;
\ No newline at end of file
+    ADD CONSTRAINT FKrole_has_p131704 FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE;
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT FKrefresh_to_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE account_tokens
+    ADD CONSTRAINT FKaccount_to_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE user_mfa
+    ADD CONSTRAINT FKuser_mfa_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE mfa_recovery_codes
+    ADD CONSTRAINT FKmfa_recov_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE merchant_applications
+    ADD CONSTRAINT FKmerchant_a_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE user_addresses
+    ADD CONSTRAINT FKuser_addre_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE api_keys
+    ADD CONSTRAINT FKapi_keys_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
\ No newline at end of file
@@ -0,0 +1,77 @@
+package address
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Rule is how postal codes and phone numbers of a country are written, phones are checked
+// after separators are removed
+type Rule struct {
+	PostalCode *regexp.Regexp
+	Phone      *regexp.Regexp
+	// StateRequired is set for countries whose addresses name a state, province or prefecture
+	StateRequired bool
+}
+
+// rules are keyed by ISO 3166-1 alpha-2 country code
+var rules = map[string]Rule{
+	"VN": {
+		PostalCode:    regexp.MustCompile(`^\d{6}$`),
+		Phone:         regexp.MustCompile(`^(\+84|0)[35789]\d{8}$`),
+		StateRequired: true,
+	},
+	"US": {
+		PostalCode:    regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+		Phone:         regexp.MustCompile(`^(\+1)?[2-9]\d{2}[2-9]\d{6}$`),
+		StateRequired: true,
+	},
+	"GB": {
+		PostalCode: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+		Phone:      regexp.MustCompile(`^(\+44|0)\d{9,10}$`),
+	},
+	"SG": {
+		PostalCode: regexp.MustCompile(`^\d{6}$`),
+		Phone:      regexp.MustCompile(`^(\+65)?[3689]\d{7}$`),
+	},
+	"JP": {
+		PostalCode:    regexp.MustCompile(`^\d{3}-\d{4}$`),
+		Phone:         regexp.MustCompile(`^(\+81|0)\d{9,10}$`),
+		StateRequired: true,
+	},
+}
+
+// defaultRule checks countries without a rule of their own, phones must be in E.164 format and
+// the state is optional
+var defaultRule = Rule{
+	PostalCode: regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,9}$`),
+	Phone:      regexp.MustCompile(`^\+[1-9]\d{6,14}$`),
+}
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// phoneSeparators are dropped from phone numbers, e.g. "(028) 3822-1234"
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+// IsCountryCode tells whether the country is written as an ISO 3166-1 alpha-2 code
+func IsCountryCode(country string) bool {
+	return countryPattern.MatchString(country)
+}
+
+// RuleFor returns the rule of the country
+func RuleFor(country string) Rule {
+	if rule, ok := rules[country]; ok {
+		return rule
+	}
+	return defaultRule
+}
+
+// NormalizePostalCode upper cases the postal code and collapses its spaces
+func NormalizePostalCode(postalCode string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(postalCode)), " ")
+}
+
+// NormalizePhone removes the separators of the phone number
+func NormalizePhone(phone string) string {
+	return phoneSeparators.Replace(strings.TrimSpace(phone))
+}
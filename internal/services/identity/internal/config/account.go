@@ -0,0 +1,45 @@
+package config
+
+import "time"
+
+// Account holds the email verification, email change and password reset configuration
+type Account struct {
+	// VerificationURL, EmailChangeURL and PasswordResetURL are the frontend pages mailed links
+	// point to, the token is appended as the token query parameter
+	VerificationURL  string `mapstructure:"verification_url"`
+	EmailChangeURL   string `mapstructure:"email_change_url"`
+	PasswordResetURL string `mapstructure:"password_reset_url"`
+	// VerificationExpiration and PasswordResetExpiration are the lifetimes of mailed tokens,
+	// email change tokens last as long as verification tokens
+	VerificationExpiration  string `mapstructure:"verification_expiration"`
+	PasswordResetExpiration string `mapstructure:"password_reset_expiration"`
+}
+
+// SetDefaults sets default values for account configuration
+func (a *Account) SetDefaults() {
+	if a.VerificationURL == "" {
+		a.VerificationURL = "http://localhost:3000/verify-email"
+	}
+	if a.EmailChangeURL == "" {
+		a.EmailChangeURL = "http://localhost:3000/confirm-email"
+	}
+	if a.PasswordResetURL == "" {
+		a.PasswordResetURL = "http://localhost:3000/reset-password"
+	}
+	if a.VerificationExpiration == "" {
+		a.VerificationExpiration = "24h"
+	}
+	if a.PasswordResetExpiration == "" {
+		a.PasswordResetExpiration = "1h"
+	}
+}
+
+// GetVerificationExpiration returns the email verification token lifetime as time.Duration
+func (a *Account) GetVerificationExpiration() (time.Duration, error) {
+	return time.ParseDuration(a.VerificationExpiration)
+}
+
+// GetPasswordResetExpiration returns the password reset token lifetime as time.Duration
+func (a *Account) GetPasswordResetExpiration() (time.Duration, error) {
+	return time.ParseDuration(a.PasswordResetExpiration)
+}
@@ -0,0 +1,25 @@
+package config
+
+import "fmt"
+
+// Address holds the address book configuration
+type Address struct {
+	// MaxPerUser is how many addresses a user can keep
+	MaxPerUser int `mapstructure:"max_per_user"`
+}
+
+// SetDefaults sets default values for address configuration
+func (a *Address) SetDefaults() {
+	if a.MaxPerUser == 0 {
+		a.MaxPerUser = 10
+	}
+}
+
+// Validate checks the limit
+func (a *Address) Validate() error {
+	if a.MaxPerUser < 0 {
+		return fmt.Errorf("invalid address max_per_user: must not be negative")
+	}
+
+	return nil
+}
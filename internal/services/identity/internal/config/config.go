@@ -3,11 +3,19 @@ package config
 import (
 	"fmt"
 	"github.com/hthinh24/go-store/internal/pkg/config"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/spf13/viper"
 	"time"
 )
 
 type AppConfig struct {
 	*config.Config
+	OIDC            OIDC            `mapstructure:"oidc"`
+	Account         Account         `mapstructure:"account"`
+	MFA             MFA             `mapstructure:"mfa"`
+	LoginProtection LoginProtection `mapstructure:"login_protection"`
+	Outbox          Outbox          `mapstructure:"outbox"`
+	Address         Address         `mapstructure:"address"`
 }
 
 func LoadConfig(configPath string) (*AppConfig, error) {
@@ -21,6 +29,67 @@ func LoadConfig(configPath string) (*AppConfig, error) {
 		Config: sharedConfig,
 	}
 
+	// Load identity specific sections, the config file was already read by the shared loader
+	if err := viper.UnmarshalKey("oidc", &appConfig.OIDC); err != nil {
+		return nil, fmt.Errorf("error unmarshaling oidc: %w", err)
+	}
+	if err := viper.UnmarshalKey("account", &appConfig.Account); err != nil {
+		return nil, fmt.Errorf("error unmarshaling account: %w", err)
+	}
+	if err := viper.UnmarshalKey("mfa", &appConfig.MFA); err != nil {
+		return nil, fmt.Errorf("error unmarshaling mfa: %w", err)
+	}
+	if err := viper.UnmarshalKey("login_protection", &appConfig.LoginProtection); err != nil {
+		return nil, fmt.Errorf("error unmarshaling login_protection: %w", err)
+	}
+	if err := viper.UnmarshalKey("outbox", &appConfig.Outbox); err != nil {
+		return nil, fmt.Errorf("error unmarshaling outbox: %w", err)
+	}
+	if err := viper.UnmarshalKey("address", &appConfig.Address); err != nil {
+		return nil, fmt.Errorf("error unmarshaling address: %w", err)
+	}
+	// The key is a secret, production setups pass it in the environment
+	viper.BindEnv("mfa.encryption_key", "MFA_ENCRYPTION_KEY")
+	if key := viper.GetString("mfa.encryption_key"); key != "" {
+		appConfig.MFA.EncryptionKey = key
+	}
+	appConfig.OIDC.SetDefaults()
+	appConfig.Account.SetDefaults()
+	appConfig.MFA.SetDefaults()
+	appConfig.LoginProtection.SetDefaults()
+	appConfig.Outbox.SetDefaults()
+	appConfig.Address.SetDefaults()
+
+	if _, err := appConfig.Account.GetVerificationExpiration(); err != nil {
+		return nil, fmt.Errorf("invalid account verification_expiration: %w", err)
+	}
+	if _, err := appConfig.Account.GetPasswordResetExpiration(); err != nil {
+		return nil, fmt.Errorf("invalid account password_reset_expiration: %w", err)
+	}
+
+	if !appConfig.IsDevelopment() && appConfig.MFA.HasDevelopmentKey() {
+		return nil, fmt.Errorf("mfa encryption_key for development only is set in environment %s, pass MFA_ENCRYPTION_KEY instead", appConfig.Environment)
+	}
+
+	if err := appConfig.LoginProtection.Validate(); err != nil {
+		return nil, err
+	}
+	if err := appConfig.Outbox.Validate(); err != nil {
+		return nil, err
+	}
+	if err := appConfig.Address.Validate(); err != nil {
+		return nil, err
+	}
+
+	for name, provider := range appConfig.OIDC.Providers {
+		if constants.IsAppProvider(name) {
+			return nil, fmt.Errorf("oidc provider name %s is reserved", name)
+		}
+		if !provider.IsValid() {
+			return nil, fmt.Errorf("oidc provider %s needs an issuer, client_id and redirect_url", name)
+		}
+	}
+
 	return appConfig, nil
 }
 
@@ -49,18 +118,23 @@ func (c *AppConfig) GetDBSSLMode() string {
 	return c.PG.SSLMode // Updated field name
 }
 
-func (c *AppConfig) GetJWTSecret() string {
-	return c.JWT.Secret
-}
-
 func (c *AppConfig) GetJWTExpiresIn() time.Duration {
 	duration, _ := time.ParseDuration(c.JWT.Expiration) // Updated field name
 	return duration
 }
 
 func (c *AppConfig) GetJWTRefreshExpiresIn() time.Duration {
-	// Since refresh_expires_in is not in pkg struct, use a default or derive from expiration
-	duration, _ := time.ParseDuration("168h") // 7 days default
+	duration, _ := c.JWT.GetRefreshExpirationDuration()
+	return duration
+}
+
+func (c *AppConfig) GetVerificationExpiresIn() time.Duration {
+	duration, _ := c.Account.GetVerificationExpiration()
+	return duration
+}
+
+func (c *AppConfig) GetPasswordResetExpiresIn() time.Duration {
+	duration, _ := c.Account.GetPasswordResetExpiration()
 	return duration
 }
 
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoginProtection holds the brute-force protection of password logins, failures are counted
+// per account and per client IP
+type LoginProtection struct {
+	// MaxAccountFailures and MaxIPFailures lock the account or IP out once reached
+	MaxAccountFailures int `mapstructure:"max_account_failures"`
+	MaxIPFailures      int `mapstructure:"max_ip_failures"`
+	// FailureWindow is how long failures are remembered after the last one
+	FailureWindow   string `mapstructure:"failure_window"`
+	LockoutDuration string `mapstructure:"lockout_duration"`
+	// Failed logins after the first DelayAfter failures of an account are answered after a
+	// delay starting at BaseDelay, doubling with every failure up to MaxDelay
+	DelayAfter int    `mapstructure:"delay_after"`
+	BaseDelay  string `mapstructure:"base_delay"`
+	MaxDelay   string `mapstructure:"max_delay"`
+	// TrustedProxies may set X-Forwarded-For, the client IP of any other peer is its remote address
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// SetDefaults sets default values for login protection configuration
+func (l *LoginProtection) SetDefaults() {
+	if l.MaxAccountFailures == 0 {
+		l.MaxAccountFailures = 5
+	}
+	if l.MaxIPFailures == 0 {
+		l.MaxIPFailures = 20
+	}
+	if l.FailureWindow == "" {
+		l.FailureWindow = "15m"
+	}
+	if l.LockoutDuration == "" {
+		l.LockoutDuration = "15m"
+	}
+	if l.DelayAfter == 0 {
+		l.DelayAfter = 2
+	}
+	if l.BaseDelay == "" {
+		l.BaseDelay = "250ms"
+	}
+	if l.MaxDelay == "" {
+		l.MaxDelay = "4s"
+	}
+	if l.TrustedProxies == nil {
+		l.TrustedProxies = []string{"127.0.0.1", "::1"}
+	}
+}
+
+// Validate checks the durations
+func (l *LoginProtection) Validate() error {
+	for name, value := range map[string]string{
+		"failure_window":   l.FailureWindow,
+		"lockout_duration": l.LockoutDuration,
+		"base_delay":       l.BaseDelay,
+		"max_delay":        l.MaxDelay,
+	} {
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid login_protection %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// GetFailureWindow returns the failure window as time.Duration
+func (l *LoginProtection) GetFailureWindow() time.Duration {
+	duration, _ := time.ParseDuration(l.FailureWindow)
+	return duration
+}
+
+// GetLockoutDuration returns the lockout duration as time.Duration
+func (l *LoginProtection) GetLockoutDuration() time.Duration {
+	duration, _ := time.ParseDuration(l.LockoutDuration)
+	return duration
+}
+
+// GetDelay returns how long the answer to a failed login is held back after failures failures
+func (l *LoginProtection) GetDelay(failures int64) time.Duration {
+	if failures <= int64(l.DelayAfter) {
+		return 0
+	}
+
+	delay, _ := time.ParseDuration(l.BaseDelay)
+	maxDelay, _ := time.ParseDuration(l.MaxDelay)
+	for i := int64(l.DelayAfter) + 1; i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginProtectionGetDelay(t *testing.T) {
+	cfg := LoginProtection{}
+	cfg.SetDefaults()
+
+	tests := []struct {
+		failures int64
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 0},
+		{3, 250 * time.Millisecond},
+		{4, 500 * time.Millisecond},
+		{5, time.Second},
+		{6, 2 * time.Second},
+		{7, 4 * time.Second},
+		{8, 4 * time.Second},
+		{1000, 4 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := cfg.GetDelay(tt.failures); got != tt.want {
+			t.Errorf("GetDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLoginProtectionValidate(t *testing.T) {
+	cfg := LoginProtection{}
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate defaults: %v", err)
+	}
+
+	cfg.LockoutDuration = "forever"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate accepted an invalid lockout duration")
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import "time"
+
+// developmentEncryptionKey is the key shipped in config.yaml, "dev-only-mfa-key-do-not-use-32by"
+const developmentEncryptionKey = "ZGV2LW9ubHktbWZhLWtleS1kby1ub3QtdXNlLTMyYnk="
+
+// MFA holds the TOTP multi-factor authentication configuration
+type MFA struct {
+	// Issuer is the account name prefix authenticator apps show
+	Issuer string `mapstructure:"issuer"`
+	// EncryptionKey encrypts TOTP secrets at rest, a base64 encoded 32 byte key
+	EncryptionKey string `mapstructure:"encryption_key"`
+	// ChallengeTTL is how long the second login step can take, in seconds
+	ChallengeTTL int `mapstructure:"challenge_ttl"`
+	// RecoveryCodes is the number of one time codes given on enrollment
+	RecoveryCodes int `mapstructure:"recovery_codes"`
+}
+
+// SetDefaults sets default values for MFA configuration
+func (m *MFA) SetDefaults() {
+	if m.Issuer == "" {
+		m.Issuer = "go-store"
+	}
+	if m.ChallengeTTL == 0 {
+		m.ChallengeTTL = 300
+	}
+	if m.RecoveryCodes == 0 {
+		m.RecoveryCodes = 10
+	}
+}
+
+// HasDevelopmentKey reports whether TOTP secrets are encrypted with the key shipped in the
+// config file, anyone can read it
+func (m *MFA) HasDevelopmentKey() bool {
+	return m.EncryptionKey == developmentEncryptionKey
+}
+
+// GetChallengeTTL returns the MFA challenge lifetime as time.Duration
+func (m *MFA) GetChallengeTTL() time.Duration {
+	return time.Duration(m.ChallengeTTL) * time.Second
+}
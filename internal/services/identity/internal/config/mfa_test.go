@@ -0,0 +1,21 @@
+package config
+
+import "testing"
+
+func TestMFAHasDevelopmentKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{developmentEncryptionKey, true},
+		{"c2VjcmV0LWtleS1vZi10aGUtcHJvZHVjdGlvbi1zZXR1cA==", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		cfg := MFA{EncryptionKey: tt.key}
+		if got := cfg.HasDevelopmentKey(); got != tt.want {
+			t.Errorf("HasDevelopmentKey() with %q = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import "time"
+
+// OIDC holds the external identity providers users can log in with
+type OIDC struct {
+	// StateTTL is how long a started login can be completed, in seconds
+	StateTTL int `mapstructure:"state_ttl"`
+	// Providers are keyed by the name used in the login routes and stored as the user provider
+	Providers map[string]OIDCProvider `mapstructure:"providers"`
+}
+
+// OIDCProvider is an OpenID Connect provider, its endpoints are discovered from the issuer
+type OIDCProvider struct {
+	Issuer       string   `mapstructure:"issuer"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
+}
+
+// SetDefaults sets default values for OIDC configuration
+func (o *OIDC) SetDefaults() {
+	if o.StateTTL == 0 {
+		o.StateTTL = 600 // 10 minutes
+	}
+	for name, provider := range o.Providers {
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+			o.Providers[name] = provider
+		}
+	}
+}
+
+// GetStateTTL returns the login state lifetime as time.Duration
+func (o *OIDC) GetStateTTL() time.Duration {
+	return time.Duration(o.StateTTL) * time.Second
+}
+
+// IsValid checks if required provider fields are set
+func (p *OIDCProvider) IsValid() bool {
+	return p.Issuer != "" && p.ClientID != "" && p.RedirectURL != ""
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Outbox holds the relay delivering outbox events to other services
+type Outbox struct {
+	PollInterval string `mapstructure:"poll_interval"`
+	BatchSize    int    `mapstructure:"batch_size"`
+	// DeliveryTimeout bounds one delivery, a claimed event is retried after it at the latest
+	DeliveryTimeout string `mapstructure:"delivery_timeout"`
+	// Failed deliveries are retried after BaseBackoff, doubling with every attempt up to MaxBackoff
+	BaseBackoff string `mapstructure:"base_backoff"`
+	MaxBackoff  string `mapstructure:"max_backoff"`
+}
+
+// SetDefaults sets default values for outbox configuration
+func (o *Outbox) SetDefaults() {
+	if o.PollInterval == "" {
+		o.PollInterval = "2s"
+	}
+	if o.BatchSize == 0 {
+		o.BatchSize = 20
+	}
+	if o.DeliveryTimeout == "" {
+		o.DeliveryTimeout = "10s"
+	}
+	if o.BaseBackoff == "" {
+		o.BaseBackoff = "1s"
+	}
+	if o.MaxBackoff == "" {
+		o.MaxBackoff = "5m"
+	}
+}
+
+// Validate checks the durations
+func (o *Outbox) Validate() error {
+	for name, value := range map[string]string{
+		"poll_interval":    o.PollInterval,
+		"delivery_timeout": o.DeliveryTimeout,
+		"base_backoff":     o.BaseBackoff,
+		"max_backoff":      o.MaxBackoff,
+	} {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid outbox %s: %w", name, err)
+		}
+		if duration <= 0 {
+			return fmt.Errorf("invalid outbox %s: must be positive", name)
+		}
+	}
+
+	return nil
+}
+
+// GetPollInterval returns the poll interval as time.Duration
+func (o *Outbox) GetPollInterval() time.Duration {
+	duration, _ := time.ParseDuration(o.PollInterval)
+	return duration
+}
+
+// GetDeliveryTimeout returns the delivery timeout as time.Duration
+func (o *Outbox) GetDeliveryTimeout() time.Duration {
+	duration, _ := time.ParseDuration(o.DeliveryTimeout)
+	return duration
+}
+
+// GetBackoff returns how long to wait before the next delivery after attempts failed attempts
+func (o *Outbox) GetBackoff(attempts int) time.Duration {
+	backoff, _ := time.ParseDuration(o.BaseBackoff)
+	maxBackoff, _ := time.ParseDuration(o.MaxBackoff)
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
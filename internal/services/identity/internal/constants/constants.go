@@ -1,8 +1,13 @@
 package constants
 
+import "strings"
+
 type Gender string
 type UserStatus string
 
+// ProviderApp is the provider of users signed up with a password, the schema stores it as 'APP'
+const ProviderApp = "app"
+
 const (
 	GenderMale   Gender = "MALE"
 	GenderFemale Gender = "FEMALE"
@@ -10,7 +15,9 @@ const (
 )
 
 const (
-	UserStatusActive   UserStatus = "ACTIVE"
+	UserStatusActive UserStatus = "ACTIVE"
+	// UserStatusPending users signed up but did not verify their email yet
+	UserStatusPending  UserStatus = "PENDING"
 	UserStatusInactive UserStatus = "INACTIVE"
 	UserStatusDeleted  UserStatus = "DELETED"
 )
@@ -32,7 +39,14 @@ func GetStatus(s string) string {
 		return string(UserStatusActive)
 	case string(UserStatusDeleted):
 		return string(UserStatusDeleted)
+	case string(UserStatusPending):
+		return string(UserStatusPending)
 	default:
 		return string(UserStatusInactive)
 	}
 }
+
+// IsAppProvider reports whether the provider name is the app itself, in any letter case
+func IsAppProvider(name string) bool {
+	return strings.EqualFold(name, ProviderApp)
+}
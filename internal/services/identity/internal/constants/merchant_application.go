@@ -0,0 +1,32 @@
+package constants
+
+type MerchantApplicationStatus string
+
+const (
+	MerchantApplicationSubmitted   MerchantApplicationStatus = "SUBMITTED"
+	MerchantApplicationUnderReview MerchantApplicationStatus = "UNDER_REVIEW"
+	MerchantApplicationApproved    MerchantApplicationStatus = "APPROVED"
+	MerchantApplicationRejected    MerchantApplicationStatus = "REJECTED"
+)
+
+// merchantApplicationTransitions lists the statuses an application can move to, approved
+// and rejected applications are final
+var merchantApplicationTransitions = map[MerchantApplicationStatus][]MerchantApplicationStatus{
+	MerchantApplicationSubmitted:   {MerchantApplicationUnderReview},
+	MerchantApplicationUnderReview: {MerchantApplicationApproved, MerchantApplicationRejected},
+}
+
+// CanTransition tells whether an application can move from one status to another
+func (s MerchantApplicationStatus) CanTransition(to MerchantApplicationStatus) bool {
+	for _, next := range merchantApplicationTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen tells whether the application still waits for a decision
+func (s MerchantApplicationStatus) IsOpen() bool {
+	return s == MerchantApplicationSubmitted || s == MerchantApplicationUnderReview
+}
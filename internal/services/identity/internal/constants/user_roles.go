@@ -8,3 +8,10 @@ const (
 	RoleStaff    Role = "staff"
 	RoleMerchant Role = "merchant"
 )
+
+type Permission string
+
+const (
+	// PermissionUserManage lets staff act on the accounts of other users
+	PermissionUserManage Permission = "user.manage"
+)
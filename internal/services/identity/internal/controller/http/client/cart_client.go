@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/middleware/requestid"
 )
 
 type CartClient interface {
@@ -18,11 +20,12 @@ type cartClient struct {
 	httpClient *http.Client
 }
 
-func NewCartClient(baseURL string) CartClient {
+func NewCartClient(baseURL string, transport http.RoundTripper) CartClient {
 	return &cartClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 5 * time.Second,
+			Transport: transport,
+			Timeout:   5 * time.Second,
 		},
 	}
 }
@@ -47,6 +50,7 @@ func (c *cartClient) CreateCart(ctx context.Context, userID int64) error {
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
+	requestid.Inject(httpReq)
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
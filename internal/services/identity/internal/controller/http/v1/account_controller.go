@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+)
+
+type AccountController struct {
+	logger         logger.Logger
+	accountService identity.AccountService
+}
+
+func NewAccountController(logger logger.Logger, service identity.AccountService) *AccountController {
+	return &AccountController{
+		logger:         logger,
+		accountService: service,
+	}
+}
+
+func (a *AccountController) VerifyEmail() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var verifyEmailRequest request.VerifyEmailRequest
+		if err := ctx.ShouldBindJSON(&verifyEmailRequest); err != nil {
+			a.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := a.accountService.VerifyEmail(ctx.Request.Context(), verifyEmailRequest); err != nil {
+			a.logger.Error("Error verifying email: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Email verified successfully", nil))
+	}
+}
+
+func (a *AccountController) ConfirmEmailChange() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var verifyEmailRequest request.VerifyEmailRequest
+		if err := ctx.ShouldBindJSON(&verifyEmailRequest); err != nil {
+			a.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := a.accountService.ConfirmEmailChange(ctx.Request.Context(), verifyEmailRequest); err != nil {
+			a.logger.Error("Error confirming email change: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Email changed successfully", nil))
+	}
+}
+
+// ResendVerification answers the same whether or not the email belongs to a pending user
+func (a *AccountController) ResendVerification() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var emailRequest request.EmailRequest
+		if err := ctx.ShouldBindJSON(&emailRequest); err != nil {
+			a.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := a.accountService.ResendVerificationEmail(ctx.Request.Context(), emailRequest); err != nil {
+			a.logger.Error("Error resending verification email: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, rest.NewAPIResponse(http.StatusAccepted, "If the account is pending, a verification email was sent", nil))
+	}
+}
+
+// ForgotPassword answers the same whether or not the email belongs to a user
+func (a *AccountController) ForgotPassword() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var emailRequest request.EmailRequest
+		if err := ctx.ShouldBindJSON(&emailRequest); err != nil {
+			a.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := a.accountService.RequestPasswordReset(ctx.Request.Context(), emailRequest); err != nil {
+			a.logger.Error("Error requesting password reset: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, rest.NewAPIResponse(http.StatusAccepted, "If the account exists, a password reset email was sent", nil))
+	}
+}
+
+func (a *AccountController) ResetPassword() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var resetPasswordRequest request.ResetPasswordRequest
+		if err := ctx.ShouldBindJSON(&resetPasswordRequest); err != nil {
+			a.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := resetPasswordRequest.Validate(); err != nil {
+			a.logger.Error("Validation fail: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		if err := a.accountService.ResetPassword(ctx.Request.Context(), resetPasswordRequest); err != nil {
+			a.logger.Error("Error resetting password: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Password reset successfully, please log in again", nil))
+	}
+}
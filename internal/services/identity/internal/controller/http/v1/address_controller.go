@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+)
+
+// AddressController serves the address book of the authenticated user, addresses of other
+// users are reported as not found
+type AddressController struct {
+	logger         logger.Logger
+	addressService identity.AddressService
+}
+
+func NewAddressController(logger logger.Logger, service identity.AddressService) *AddressController {
+	return &AddressController{
+		logger:         logger,
+		addressService: service,
+	}
+}
+
+func (a *AddressController) GetAddresses() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		addresses, err := a.addressService.GetAddresses(ctx.Request.Context(), ctx.GetInt64("user_id"))
+		if err != nil {
+			a.logger.Error("Error getting addresses: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Addresses retrieved successfully", addresses))
+	}
+}
+
+func (a *AddressController) GetAddress() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		id, ok := a.parseAddressID(ctx)
+		if !ok {
+			return
+		}
+
+		address, err := a.addressService.GetAddress(ctx.Request.Context(), ctx.GetInt64("user_id"), id)
+		if err != nil {
+			a.logger.Error("Error getting address: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Address retrieved successfully", address))
+	}
+}
+
+func (a *AddressController) CreateAddress() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		addressRequest, ok := a.bindAddressRequest(ctx)
+		if !ok {
+			return
+		}
+
+		address, err := a.addressService.CreateAddress(ctx.Request.Context(), ctx.GetInt64("user_id"), *addressRequest)
+		if err != nil {
+			a.logger.Error("Error creating address: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, rest.NewAPIResponse(http.StatusCreated, "Address created successfully", address))
+	}
+}
+
+func (a *AddressController) UpdateAddress() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		id, ok := a.parseAddressID(ctx)
+		if !ok {
+			return
+		}
+
+		addressRequest, ok := a.bindAddressRequest(ctx)
+		if !ok {
+			return
+		}
+
+		address, err := a.addressService.UpdateAddress(ctx.Request.Context(), ctx.GetInt64("user_id"), id, *addressRequest)
+		if err != nil {
+			a.logger.Error("Error updating address: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Address updated successfully", address))
+	}
+}
+
+func (a *AddressController) DeleteAddress() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		id, ok := a.parseAddressID(ctx)
+		if !ok {
+			return
+		}
+
+		if err := a.addressService.DeleteAddress(ctx.Request.Context(), ctx.GetInt64("user_id"), id); err != nil {
+			a.logger.Error("Error deleting address: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusNoContent, rest.NewAPIResponse(http.StatusNoContent, "Address deleted successfully", nil))
+	}
+}
+
+func (a *AddressController) bindAddressRequest(ctx *gin.Context) (*request.AddressRequest, bool) {
+	var addressRequest request.AddressRequest
+	if err := ctx.ShouldBindJSON(&addressRequest); err != nil {
+		a.logger.Error("Error binding JSON: ", err)
+		ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+		return nil, false
+	}
+
+	if err := addressRequest.Validate(); err != nil {
+		a.logger.Error("Validation fail: ", err)
+		HandleError(ctx, err)
+		return nil, false
+	}
+
+	return &addressRequest, true
+}
+
+func (a *AddressController) parseAddressID(ctx *gin.Context) (int64, bool) {
+	idStr := ctx.Param("addressID")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		a.logger.Error("Invalid address ID: ", idStr, ", Error: ", err)
+		ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid ID format"})
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+)
+
+// APIKeyController serves the API keys of the authenticated user, the routes need an access
+// token so a key can never create or revoke keys
+type APIKeyController struct {
+	logger        logger.Logger
+	apiKeyService identity.APIKeyService
+}
+
+func NewAPIKeyController(logger logger.Logger, service identity.APIKeyService) *APIKeyController {
+	return &APIKeyController{
+		logger:        logger,
+		apiKeyService: service,
+	}
+}
+
+func (a *APIKeyController) GetAPIKeys() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		keys, err := a.apiKeyService.GetAPIKeys(ctx.Request.Context(), ctx.GetInt64("user_id"))
+		if err != nil {
+			a.logger.Error("Error getting API keys: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "API keys retrieved successfully", keys))
+	}
+}
+
+// CreateAPIKey returns the key itself, it is not stored and cannot be shown again
+func (a *APIKeyController) CreateAPIKey() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var keyRequest request.CreateAPIKeyRequest
+		if err := ctx.ShouldBindJSON(&keyRequest); err != nil {
+			a.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := keyRequest.Validate(); err != nil {
+			a.logger.Error("Validation fail: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		key, err := a.apiKeyService.CreateAPIKey(ctx.Request.Context(), ctx.GetInt64("user_id"), ctx.GetStringSlice("permissions"), keyRequest)
+		if err != nil {
+			a.logger.Error("Error creating API key: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, rest.NewAPIResponse(http.StatusCreated, "API key created, store it now as it cannot be shown again", key))
+	}
+}
+
+func (a *APIKeyController) RevokeAPIKey() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		idStr := ctx.Param("keyID")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			a.logger.Error("Invalid API key ID: ", idStr, ", Error: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid ID format"})
+			return
+		}
+
+		if err := a.apiKeyService.RevokeAPIKey(ctx.Request.Context(), ctx.GetInt64("user_id"), id); err != nil {
+			a.logger.Error("Error revoking API key: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusNoContent, rest.NewAPIResponse(http.StatusNoContent, "API key revoked successfully", nil))
+	}
+}
@@ -1,24 +1,29 @@
 package v1
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/hthinh24/go-store/internal/pkg/logger"
 	"github.com/hthinh24/go-store/internal/pkg/rest"
 	"github.com/hthinh24/go-store/services/identity"
 	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
 type AuthController struct {
-	logger      logger.Logger
-	authService identity.AuthService
+	logger        logger.Logger
+	authService   identity.AuthService
+	apiKeyService identity.APIKeyService
 }
 
-func NewAuthController(logger logger.Logger, service identity.AuthService) *AuthController {
+func NewAuthController(logger logger.Logger, service identity.AuthService, apiKeyService identity.APIKeyService) *AuthController {
 	return &AuthController{
-		logger:      logger,
-		authService: service,
+		logger:        logger,
+		authService:   service,
+		apiKeyService: apiKeyService,
 	}
 }
 
@@ -32,15 +37,119 @@ func (a *AuthController) Login() func(ctx *gin.Context) {
 		}
 
 		a.logger.Info("Processing login for user: ", AuthRequest.Email)
-		authResponse, err := a.authService.Login(AuthRequest)
+		authResponse, err := a.authService.Login(ctx.Request.Context(), AuthRequest, ctx.ClientIP())
 		if err != nil {
 			a.logger.Error("Error during login: ", err)
-			ctx.JSON(http.StatusInternalServerError, rest.ErrorResponse{ApiError: rest.InternalServerErrorError, Message: "Login failed"})
+			HandleError(ctx, err)
 			return
 		}
 
 		a.logger.Info("Login successful for user: ", AuthRequest.Email)
-		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Login successful", authResponse))
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, loginMessage(authResponse), authResponse))
+	}
+}
+
+// loginMessage tells a finished login from one that continues with an MFA challenge
+func loginMessage(authResponse *response.AuthResponse) string {
+	if authResponse.MFARequired || authResponse.MFASetupRequired {
+		return "Multi-factor authentication required"
+	}
+	return "Login successful"
+}
+
+func (a *AuthController) Refresh() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var refreshTokenRequest request.RefreshTokenRequest
+		if err := ctx.ShouldBindJSON(&refreshTokenRequest); err != nil {
+			a.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		authResponse, err := a.authService.Refresh(ctx.Request.Context(), refreshTokenRequest)
+		if err != nil {
+			a.logger.Error("Error refreshing token: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Token refreshed successfully", authResponse))
+	}
+}
+
+// Logout revokes the access token of the request, the route must be behind AuthRequired
+func (a *AuthController) Logout() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		// The body is optional, it only carries the refresh token to revoke along
+		var logoutRequest request.LogoutRequest
+		if ctx.Request.ContentLength > 0 {
+			if err := ctx.ShouldBindJSON(&logoutRequest); err != nil {
+				a.logger.Error("Error binding JSON: ", err)
+				ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+				return
+			}
+		}
+
+		token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		if err := a.authService.Logout(ctx.Request.Context(), token, logoutRequest); err != nil {
+			a.logger.Error("Error during logout: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Logout successful", nil))
+	}
+}
+
+// LogoutAll revokes every access and refresh token of the user, the route must be behind AuthRequired
+func (a *AuthController) LogoutAll() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt64("user_id")
+		if err := a.authService.LogoutAll(ctx.Request.Context(), userID); err != nil {
+			a.logger.Error("Error during logout of all sessions: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Logged out of all sessions", nil))
+	}
+}
+
+// UnlockUser ends the login lockout of a user, the route must be admin only
+func (a *AuthController) UnlockUser() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		idStr := ctx.Param("id")
+
+		userID, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			a.logger.Error("Invalid user ID: ", idStr, ", Error: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid user ID format"})
+			return
+		}
+
+		if err := a.authService.UnlockUser(ctx.Request.Context(), userID, ctx.GetInt64("user_id")); err != nil {
+			a.logger.Error("Error unlocking user: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "User unlocked", nil))
+	}
+}
+
+// JWKS serves the public keys access tokens are verified with, in the JSON Web Key Set format
+func (a *AuthController) JWKS() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		keySet, err := a.authService.KeySet()
+		if err != nil {
+			a.logger.Error("Error building key set: ", err)
+			ctx.JSON(http.StatusInternalServerError, rest.ErrorResponse{ApiError: rest.InternalServerErrorError, Message: "Failed to fetch keys"})
+			return
+		}
+
+		// Verifiers refetch on unknown kids, so a short cache is enough
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, keySet)
 	}
 }
 
@@ -53,10 +162,29 @@ func (a *AuthController) Verify() func(ctx *gin.Context) {
 			return
 		}
 
+		// The gateway verifies API keys here as well, they are never logged
+		if key, found := strings.CutPrefix(token, "ApiKey "); found {
+			verifyResponse, err := a.apiKeyService.VerifyAPIKey(ctx.Request.Context(), key)
+			if err != nil {
+				a.logger.Error("API key verification failed: ", err)
+				// Only a rejected key is a 401, the gateway caches that answer
+				if !errors.Is(err, rest.AuthenticationError{}) {
+					HandleError(ctx, err)
+					return
+				}
+				ctx.JSON(http.StatusUnauthorized, rest.ErrorResponse{ApiError: rest.UnauthorizedError, Message: "Invalid API key"})
+				return
+			}
+
+			a.logger.Info("API key verified successfully for user: ", verifyResponse.UserID)
+			ctx.JSON(http.StatusOK, verifyResponse)
+			return
+		}
+
 		token = strings.TrimPrefix(token, "Bearer ")
 
 		a.logger.Info("Verifying token: ", token)
-		verifyResponse, err := a.authService.Verify(token)
+		verifyResponse, err := a.authService.Verify(ctx.Request.Context(), token)
 		if err != nil {
 			a.logger.Error("Token verification failed: ", err)
 			ctx.JSON(http.StatusUnauthorized, rest.ErrorResponse{ApiError: rest.UnauthorizedError, Message: "Invalid token"})
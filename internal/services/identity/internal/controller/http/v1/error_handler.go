@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hthinh24/go-store/internal/pkg/rest"
@@ -17,18 +19,103 @@ func HandleError(c *gin.Context, err error) {
 	case errors.ErrUserAlreadyExists:
 		response := rest.NewErrorResponse(rest.ConflictError, e.Error())
 		c.JSON(http.StatusConflict, response)
-	case errors.ErrCartCreationFailed:
-		response := rest.NewErrorResponse(rest.InternalServerErrorError, e.Error())
-		c.JSON(http.StatusInternalServerError, response)
 	case errors.ErrInvalidCredentials:
 		response := rest.NewErrorResponse(rest.UnauthorizedError, e.Error())
 		c.JSON(http.StatusUnauthorized, response)
+	case errors.ErrInvalidRefreshToken:
+		response := rest.NewErrorResponse(rest.UnauthorizedError, e.Error())
+		c.JSON(http.StatusUnauthorized, response)
+	case errors.ErrRefreshTokenReused:
+		response := rest.NewErrorResponse(rest.UnauthorizedError, e.Error())
+		c.JSON(http.StatusUnauthorized, response)
+	case errors.ErrInvalidAccountToken:
+		response := rest.NewErrorResponse(rest.BadRequestError, e.Error())
+		c.JSON(http.StatusBadRequest, response)
+	case rest.AuthenticationError:
+		response := rest.NewErrorResponse(rest.UnauthorizedError, e.Error())
+		c.JSON(http.StatusUnauthorized, response)
+	case errors.ErrTooManyLoginAttempts:
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
+		response := rest.NewErrorResponse(rest.TooManyRequestsError, e.Error())
+		c.JSON(http.StatusTooManyRequests, response)
+	case errors.ErrTokenRevoked:
+		response := rest.NewErrorResponse(rest.UnauthorizedError, e.Error())
+		c.JSON(http.StatusUnauthorized, response)
+	case errors.ErrOIDCProviderNotFound:
+		response := rest.NewErrorResponse(rest.NotFoundError, e.Error())
+		c.JSON(http.StatusNotFound, response)
+	case errors.ErrInvalidOIDCState:
+		response := rest.NewErrorResponse(rest.BadRequestError, e.Error())
+		c.JSON(http.StatusBadRequest, response)
+	case errors.ErrOIDCAuthentication:
+		response := rest.NewErrorResponse(rest.UnauthorizedError, e.Error())
+		c.JSON(http.StatusUnauthorized, response)
+	case errors.ErrEmailNotVerified:
+		response := rest.NewErrorResponse(rest.ForbiddenError, e.Error())
+		c.JSON(http.StatusForbidden, response)
+	case errors.ErrAccountLinkedToOtherProvider:
+		response := rest.NewErrorResponse(rest.ConflictError, e.Error())
+		c.JSON(http.StatusConflict, response)
+	case errors.ErrAccountEmailNotVerified:
+		response := rest.NewErrorResponse(rest.ConflictError, e.Error())
+		c.JSON(http.StatusConflict, response)
+	case errors.ErrMFANotEnrolled:
+		response := rest.NewErrorResponse(rest.BadRequestError, e.Error())
+		c.JSON(http.StatusBadRequest, response)
+	case errors.ErrMFAAlreadyEnabled:
+		response := rest.NewErrorResponse(rest.ConflictError, e.Error())
+		c.JSON(http.StatusConflict, response)
+	case errors.ErrMFARequired:
+		response := rest.NewErrorResponse(rest.ForbiddenError, e.Error())
+		c.JSON(http.StatusForbidden, response)
+	case errors.ErrInvalidMFACode:
+		response := rest.NewErrorResponse(rest.UnauthorizedError, e.Error())
+		c.JSON(http.StatusUnauthorized, response)
+	case errors.ErrInvalidMFAChallenge:
+		response := rest.NewErrorResponse(rest.UnauthorizedError, e.Error())
+		c.JSON(http.StatusUnauthorized, response)
 	case errors.ErrInvalidUserData:
 		response := rest.NewErrorResponse(rest.BadRequestError, e.Error())
 		c.JSON(http.StatusBadRequest, response)
 	case errors.ErrRoleNotFound:
+		response := rest.NewErrorResponse(rest.NotFoundError, e.Error())
+		c.JSON(http.StatusNotFound, response)
+	case errors.ErrRoleAlreadyExists:
+		response := rest.NewErrorResponse(rest.ConflictError, e.Error())
+		c.JSON(http.StatusConflict, response)
+	case errors.ErrProtectedRole:
+		response := rest.NewErrorResponse(rest.ForbiddenError, e.Error())
+		c.JSON(http.StatusForbidden, response)
+	case errors.ErrInvalidRoleChange:
 		response := rest.NewErrorResponse(rest.BadRequestError, e.Error())
 		c.JSON(http.StatusBadRequest, response)
+	case errors.ErrPermissionNotFound:
+		response := rest.NewErrorResponse(rest.NotFoundError, e.Error())
+		c.JSON(http.StatusNotFound, response)
+	case errors.ErrPermissionAlreadyExists:
+		response := rest.NewErrorResponse(rest.ConflictError, e.Error())
+		c.JSON(http.StatusConflict, response)
+	case errors.ErrMerchantApplicationNotFound:
+		response := rest.NewErrorResponse(rest.NotFoundError, e.Error())
+		c.JSON(http.StatusNotFound, response)
+	case errors.ErrOpenMerchantApplication:
+		response := rest.NewErrorResponse(rest.ConflictError, e.Error())
+		c.JSON(http.StatusConflict, response)
+	case errors.ErrAlreadyMerchant:
+		response := rest.NewErrorResponse(rest.ConflictError, e.Error())
+		c.JSON(http.StatusConflict, response)
+	case errors.ErrInvalidApplicationTransition:
+		response := rest.NewErrorResponse(rest.ConflictError, e.Error())
+		c.JSON(http.StatusConflict, response)
+	case errors.ErrAddressNotFound:
+		response := rest.NewErrorResponse(rest.NotFoundError, e.Error())
+		c.JSON(http.StatusNotFound, response)
+	case errors.ErrAddressLimitReached:
+		response := rest.NewErrorResponse(rest.ConflictError, e.Error())
+		c.JSON(http.StatusConflict, response)
+	case errors.ErrAPIKeyNotFound:
+		response := rest.NewErrorResponse(rest.NotFoundError, e.Error())
+		c.JSON(http.StatusNotFound, response)
 	case errors.ErrDatabaseTransaction:
 		response := rest.NewErrorResponse(rest.InternalServerErrorError, e.Error())
 		c.JSON(http.StatusInternalServerError, response)
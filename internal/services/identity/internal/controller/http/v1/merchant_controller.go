@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+)
+
+type MerchantController struct {
+	logger          logger.Logger
+	merchantService identity.MerchantService
+}
+
+func NewMerchantController(logger logger.Logger, service identity.MerchantService) *MerchantController {
+	return &MerchantController{
+		logger:          logger,
+		merchantService: service,
+	}
+}
+
+// SubmitApplication applies for a merchant account of the user in the path
+func (m *MerchantController) SubmitApplication() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		userID, ok := m.parseID(ctx, "id")
+		if !ok {
+			return
+		}
+
+		var applicationRequest request.MerchantApplicationRequest
+		if err := ctx.ShouldBindJSON(&applicationRequest); err != nil {
+			m.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		application, err := m.merchantService.SubmitApplication(ctx.Request.Context(), userID, applicationRequest)
+		if err != nil {
+			m.logger.Error("Error submitting merchant application: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, rest.NewAPIResponse(http.StatusCreated, "Merchant application submitted", application))
+	}
+}
+
+// GetUserApplication returns the latest application of the user in the path
+func (m *MerchantController) GetUserApplication() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		userID, ok := m.parseID(ctx, "id")
+		if !ok {
+			return
+		}
+
+		application, err := m.merchantService.GetUserApplication(ctx.Request.Context(), userID)
+		if err != nil {
+			m.logger.Error("Error getting merchant application: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Merchant application retrieved successfully", application))
+	}
+}
+
+func (m *MerchantController) GetApplications() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var listRequest request.ListMerchantApplicationsRequest
+		if err := ctx.ShouldBindQuery(&listRequest); err != nil {
+			m.logger.Error("Error binding query: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid query parameters"})
+			return
+		}
+
+		applications, err := m.merchantService.GetApplications(ctx.Request.Context(), listRequest)
+		if err != nil {
+			m.logger.Error("Error getting merchant applications: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Merchant applications retrieved successfully", applications))
+	}
+}
+
+func (m *MerchantController) GetApplication() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		id, ok := m.parseID(ctx, "id")
+		if !ok {
+			return
+		}
+
+		application, err := m.merchantService.GetApplication(ctx.Request.Context(), id)
+		if err != nil {
+			m.logger.Error("Error getting merchant application: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Merchant application retrieved successfully", application))
+	}
+}
+
+// StartReview takes a submitted application into review, the route must be admin only
+func (m *MerchantController) StartReview() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		id, ok := m.parseID(ctx, "id")
+		if !ok {
+			return
+		}
+
+		application, err := m.merchantService.StartReview(ctx.Request.Context(), id, ctx.GetInt64("user_id"))
+		if err != nil {
+			m.logger.Error("Error starting merchant application review: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Merchant application is under review", application))
+	}
+}
+
+// DecideApplication approves or rejects an application under review, the route must be admin only
+func (m *MerchantController) DecideApplication() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		id, ok := m.parseID(ctx, "id")
+		if !ok {
+			return
+		}
+
+		var decisionRequest request.MerchantApplicationDecisionRequest
+		if err := ctx.ShouldBindJSON(&decisionRequest); err != nil {
+			m.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := decisionRequest.Validate(); err != nil {
+			m.logger.Error("Validation fail: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		application, err := m.merchantService.DecideApplication(ctx.Request.Context(), id, decisionRequest, ctx.GetInt64("user_id"))
+		if err != nil {
+			m.logger.Error("Error deciding merchant application: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Merchant application decided", application))
+	}
+}
+
+func (m *MerchantController) parseID(ctx *gin.Context, param string) (int64, bool) {
+	idStr := ctx.Param(param)
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		m.logger.Error("Invalid ID: ", idStr, ", Error: ", err)
+		ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid ID format"})
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,173 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+)
+
+type MFAController struct {
+	logger     logger.Logger
+	mfaService identity.MFAService
+}
+
+func NewMFAController(logger logger.Logger, service identity.MFAService) *MFAController {
+	return &MFAController{
+		logger:     logger,
+		mfaService: service,
+	}
+}
+
+// Enroll starts an enrollment of the logged in user, the route must be behind AuthRequired
+func (m *MFAController) Enroll() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		enrollment, err := m.mfaService.Enroll(ctx.Request.Context(), ctx.GetInt64("user_id"))
+		if err != nil {
+			m.logger.Error("Error starting MFA enrollment: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Scan the secret and confirm it with a code", enrollment))
+	}
+}
+
+// ConfirmEnrollment enables MFA of the logged in user, the route must be behind AuthRequired
+func (m *MFAController) ConfirmEnrollment() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var confirmRequest request.MFAConfirmRequest
+		if err := ctx.ShouldBindJSON(&confirmRequest); err != nil {
+			m.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		recoveryCodes, err := m.mfaService.ConfirmEnrollment(ctx.Request.Context(), ctx.GetInt64("user_id"), confirmRequest)
+		if err != nil {
+			m.logger.Error("Error confirming MFA enrollment: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "MFA enabled, store the recovery codes safely", recoveryCodes))
+	}
+}
+
+// Disable turns MFA of the logged in user off, the route must be behind AuthRequired
+func (m *MFAController) Disable() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var codeRequest request.MFACodeRequest
+		if err := ctx.ShouldBindJSON(&codeRequest); err != nil {
+			m.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := codeRequest.Validate(); err != nil {
+			m.logger.Error("Validation fail: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		if err := m.mfaService.Disable(ctx.Request.Context(), ctx.GetInt64("user_id"), codeRequest); err != nil {
+			m.logger.Error("Error disabling MFA: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "MFA disabled", nil))
+	}
+}
+
+// Verify completes a login that returned an MFA challenge
+func (m *MFAController) Verify() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var challengeRequest request.MFAChallengeRequest
+		if err := ctx.ShouldBindJSON(&challengeRequest); err != nil {
+			m.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := challengeRequest.Validate(); err != nil {
+			m.logger.Error("Validation fail: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		authResponse, err := m.mfaService.Verify(ctx.Request.Context(), challengeRequest)
+		if err != nil {
+			m.logger.Error("Error verifying MFA code: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Login successful", authResponse))
+	}
+}
+
+// Setup starts the enrollment of a login that returned an MFA setup challenge
+func (m *MFAController) Setup() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var setupRequest request.MFASetupRequest
+		if err := ctx.ShouldBindJSON(&setupRequest); err != nil {
+			m.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		enrollment, err := m.mfaService.StartSetup(ctx.Request.Context(), setupRequest)
+		if err != nil {
+			m.logger.Error("Error starting MFA setup: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Scan the secret and confirm it with a code", enrollment))
+	}
+}
+
+// ConfirmSetup enables MFA and completes the login
+func (m *MFAController) ConfirmSetup() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var setupConfirmRequest request.MFASetupConfirmRequest
+		if err := ctx.ShouldBindJSON(&setupConfirmRequest); err != nil {
+			m.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		setupResponse, err := m.mfaService.CompleteSetup(ctx.Request.Context(), setupConfirmRequest)
+		if err != nil {
+			m.logger.Error("Error confirming MFA setup: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Login successful, store the recovery codes safely", setupResponse))
+	}
+}
+
+// RequireForRole sets whether users of a role must use MFA, the route must be admin only
+func (m *MFAController) RequireForRole() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var roleRequest request.UpdateRoleMFARequest
+		if err := ctx.ShouldBindJSON(&roleRequest); err != nil {
+			m.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := m.mfaService.SetRoleMFARequired(ctx.Request.Context(), ctx.Param("name"), *roleRequest.Required, ctx.GetInt64("user_id")); err != nil {
+			m.logger.Error("Error updating role MFA requirement: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Role MFA requirement updated", nil))
+	}
+}
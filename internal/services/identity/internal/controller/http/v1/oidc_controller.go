@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+)
+
+type OIDCController struct {
+	logger      logger.Logger
+	oidcService identity.OIDCService
+}
+
+func NewOIDCController(logger logger.Logger, service identity.OIDCService) *OIDCController {
+	return &OIDCController{
+		logger:      logger,
+		oidcService: service,
+	}
+}
+
+// Login redirects the user to the login page of the provider
+func (o *OIDCController) Login() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		providerName := ctx.Param("provider")
+
+		authURL, err := o.oidcService.StartLogin(ctx.Request.Context(), providerName)
+		if err != nil {
+			o.logger.Error("Error starting OIDC login: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// Callback completes the login the provider redirected back from
+func (o *OIDCController) Callback() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var callbackRequest request.OIDCCallbackRequest
+		if err := ctx.ShouldBindQuery(&callbackRequest); err != nil {
+			o.logger.Error("Error binding query: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid callback parameters"})
+			return
+		}
+
+		authResponse, err := o.oidcService.CompleteLogin(ctx.Request.Context(), ctx.Param("provider"), callbackRequest)
+		if err != nil {
+			o.logger.Error("Error completing OIDC login: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, loginMessage(authResponse), authResponse))
+	}
+}
@@ -0,0 +1,288 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+)
+
+// RoleController serves the admin API of roles, permissions and user roles, every route
+// must be admin only
+type RoleController struct {
+	logger      logger.Logger
+	roleService identity.RoleService
+}
+
+func NewRoleController(logger logger.Logger, service identity.RoleService) *RoleController {
+	return &RoleController{
+		logger:      logger,
+		roleService: service,
+	}
+}
+
+func (r *RoleController) GetRoles() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		roles, err := r.roleService.GetRoles(ctx.Request.Context())
+		if err != nil {
+			r.logger.Error("Error getting roles: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Roles retrieved successfully", roles))
+	}
+}
+
+func (r *RoleController) GetRole() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		role, err := r.roleService.GetRole(ctx.Request.Context(), ctx.Param("name"))
+		if err != nil {
+			r.logger.Error("Error getting role: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Role retrieved successfully", role))
+	}
+}
+
+func (r *RoleController) CreateRole() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var createRoleRequest request.CreateRoleRequest
+		if err := ctx.ShouldBindJSON(&createRoleRequest); err != nil {
+			r.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := createRoleRequest.Validate(); err != nil {
+			r.logger.Error("Validation fail: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		role, err := r.roleService.CreateRole(ctx.Request.Context(), createRoleRequest, ctx.GetInt64("user_id"))
+		if err != nil {
+			r.logger.Error("Error creating role: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, rest.NewAPIResponse(http.StatusCreated, "Role created successfully", role))
+	}
+}
+
+func (r *RoleController) UpdateRole() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var updateRoleRequest request.UpdateRoleRequest
+		if err := ctx.ShouldBindJSON(&updateRoleRequest); err != nil {
+			r.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		role, err := r.roleService.UpdateRole(ctx.Request.Context(), ctx.Param("name"), updateRoleRequest, ctx.GetInt64("user_id"))
+		if err != nil {
+			r.logger.Error("Error updating role: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Role updated successfully", role))
+	}
+}
+
+func (r *RoleController) DeleteRole() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		if err := r.roleService.DeleteRole(ctx.Request.Context(), ctx.Param("name"), ctx.GetInt64("user_id")); err != nil {
+			r.logger.Error("Error deleting role: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Role deleted successfully", nil))
+	}
+}
+
+func (r *RoleController) AddPermissionToRole() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var rolePermissionRequest request.RolePermissionRequest
+		if err := ctx.ShouldBindJSON(&rolePermissionRequest); err != nil {
+			r.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		role, err := r.roleService.AddPermissionToRole(ctx.Request.Context(), ctx.Param("name"), rolePermissionRequest, ctx.GetInt64("user_id"))
+		if err != nil {
+			r.logger.Error("Error adding permission to role: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Permission added to role", role))
+	}
+}
+
+func (r *RoleController) RemovePermissionFromRole() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		role, err := r.roleService.RemovePermissionFromRole(ctx.Request.Context(), ctx.Param("name"), ctx.Param("permission"), ctx.GetInt64("user_id"))
+		if err != nil {
+			r.logger.Error("Error removing permission from role: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Permission removed from role", role))
+	}
+}
+
+func (r *RoleController) GetPermissions() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		permissions, err := r.roleService.GetPermissions(ctx.Request.Context())
+		if err != nil {
+			r.logger.Error("Error getting permissions: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Permissions retrieved successfully", permissions))
+	}
+}
+
+func (r *RoleController) CreatePermission() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var createPermissionRequest request.CreatePermissionRequest
+		if err := ctx.ShouldBindJSON(&createPermissionRequest); err != nil {
+			r.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		if err := createPermissionRequest.Validate(); err != nil {
+			r.logger.Error("Validation fail: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		permission, err := r.roleService.CreatePermission(ctx.Request.Context(), createPermissionRequest, ctx.GetInt64("user_id"))
+		if err != nil {
+			r.logger.Error("Error creating permission: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, rest.NewAPIResponse(http.StatusCreated, "Permission created successfully", permission))
+	}
+}
+
+func (r *RoleController) UpdatePermission() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var updatePermissionRequest request.UpdatePermissionRequest
+		if err := ctx.ShouldBindJSON(&updatePermissionRequest); err != nil {
+			r.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		permission, err := r.roleService.UpdatePermission(ctx.Request.Context(), ctx.Param("name"), updatePermissionRequest, ctx.GetInt64("user_id"))
+		if err != nil {
+			r.logger.Error("Error updating permission: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Permission updated successfully", permission))
+	}
+}
+
+func (r *RoleController) DeletePermission() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		if err := r.roleService.DeletePermission(ctx.Request.Context(), ctx.Param("name"), ctx.GetInt64("user_id")); err != nil {
+			r.logger.Error("Error deleting permission: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Permission deleted successfully", nil))
+	}
+}
+
+func (r *RoleController) GetUserRoles() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		userID, ok := r.parseUserID(ctx)
+		if !ok {
+			return
+		}
+
+		roles, err := r.roleService.GetUserRoles(ctx.Request.Context(), userID)
+		if err != nil {
+			r.logger.Error("Error getting user roles: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "User roles retrieved successfully", roles))
+	}
+}
+
+func (r *RoleController) AssignRoleToUser() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		userID, ok := r.parseUserID(ctx)
+		if !ok {
+			return
+		}
+
+		var userRoleRequest request.UserRoleRequest
+		if err := ctx.ShouldBindJSON(&userRoleRequest); err != nil {
+			r.logger.Error("Error binding JSON: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid request body"})
+			return
+		}
+
+		roles, err := r.roleService.AssignRoleToUser(ctx.Request.Context(), userID, userRoleRequest, ctx.GetInt64("user_id"))
+		if err != nil {
+			r.logger.Error("Error assigning role to user: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Role assigned, it applies from the next token refresh", roles))
+	}
+}
+
+func (r *RoleController) RevokeRoleFromUser() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		userID, ok := r.parseUserID(ctx)
+		if !ok {
+			return
+		}
+
+		roles, err := r.roleService.RevokeRoleFromUser(ctx.Request.Context(), userID, ctx.Param("role"), ctx.GetInt64("user_id"))
+		if err != nil {
+			r.logger.Error("Error revoking role from user: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Role revoked, it applies from the next token refresh", roles))
+	}
+}
+
+func (r *RoleController) parseUserID(ctx *gin.Context) (int64, bool) {
+	idStr := ctx.Param("id")
+
+	userID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		r.logger.Error("Invalid user ID: ", idStr, ", Error: ", err)
+		ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid user ID format"})
+		return 0, false
+	}
+
+	return userID, true
+}
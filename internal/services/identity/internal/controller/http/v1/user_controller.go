@@ -6,6 +6,7 @@ import (
 	"github.com/hthinh24/go-store/internal/pkg/logger"
 	"github.com/hthinh24/go-store/internal/pkg/rest"
 	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
 	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
 	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
 	"net/http"
@@ -57,17 +58,30 @@ func (u *UserController) GetUserByID() func(ctx *gin.Context) {
 
 func (u *UserController) GetUsers() func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
-		u.logger.Info("Get all users")
+		u.logger.Info("Get users")
 
-		users, err := u.userService.GetUsers()
+		var listUsersRequest request.ListUsersRequest
+		if err := ctx.ShouldBindQuery(&listUsersRequest); err != nil {
+			u.logger.Error("Error binding query: ", err)
+			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid query parameters"})
+			return
+		}
+
+		if err := listUsersRequest.Validate(); err != nil {
+			u.logger.Error("Validation fail: ", err)
+			HandleError(ctx, err)
+			return
+		}
+
+		users, err := u.userService.GetUsers(ctx.Request.Context(), listUsersRequest)
 		if err != nil {
 			u.logger.Error("Error fetching users: ", err)
-			ctx.JSON(http.StatusInternalServerError, rest.ErrorResponse{ApiError: rest.InternalServerErrorError, Message: "Failed to fetch users"})
+			HandleError(ctx, err)
 			return
 		}
 
-		u.logger.Info("Get all users successfully")
-		ctx.JSON(http.StatusOK, users)
+		u.logger.Info("Get users successfully")
+		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "Users fetched successfully", users))
 	}
 }
 
@@ -86,9 +100,16 @@ func (u *UserController) CreateUser() func(ctx *gin.Context) {
 			return
 		}
 
+		// Sign ups always belong to the app, provider identities are only linked by a verified
+		// provider login or anyone could claim one
+		userRequest.ProviderName = constants.ProviderApp
+		userRequest.ProviderID = ""
+		// The account is activated once the user verifies the email
+		userRequest.Status = string(constants.UserStatusPending)
+
 		u.logger.Info("Creating new user with email: ", userRequest.Email)
 
-		user, err := u.userService.CreateUser(&userRequest)
+		user, err := u.userService.CreateUser(ctx.Request.Context(), &userRequest)
 		if err != nil {
 			u.logger.Error("Error creating user: ", err)
 			ctx.JSON(http.StatusInternalServerError, rest.ErrorResponse{ApiError: rest.InternalServerErrorError, Message: "Failed to create user"})
@@ -127,10 +148,10 @@ func (u *UserController) UpdateUserProfile() func(ctx *gin.Context) {
 
 		u.logger.Info("Updating user profile with ID: ", id)
 
-		user, err := u.userService.UpdateUserProfile(int64(id), &updateRequest)
+		user, err := u.userService.UpdateUserProfile(ctx.Request.Context(), int64(id), &updateRequest)
 		if err != nil {
 			u.logger.Error("Error updating user profile: ", err)
-			ctx.JSON(http.StatusInternalServerError, rest.ErrorResponse{ApiError: rest.InternalServerErrorError, Message: "Failed to update user profile"})
+			HandleError(ctx, err)
 			return
 		}
 
@@ -177,30 +198,6 @@ func (u *UserController) UpdateUserPassword() func(ctx *gin.Context) {
 	}
 }
 
-func (u *UserController) UpdateToMerchantAccount() func(ctx *gin.Context) {
-	return func(ctx *gin.Context) {
-		idStr := ctx.Param("id")
-
-		id, err := strconv.Atoi(idStr)
-		if err != nil {
-			u.logger.Error("Invalid user ID: ", idStr, ", Error: ", err)
-			ctx.JSON(http.StatusBadRequest, rest.ErrorResponse{ApiError: rest.BadRequestError, Message: "Invalid user ID format"})
-			return
-		}
-
-		u.logger.Info("Updating user to merchant account with ID: ", id)
-
-		if err := u.userService.UpdateToMerchantAccount(int64(id)); err != nil {
-			u.logger.Error("Error updating user to merchant account with ID: ", id, ", Error: ", err)
-			ctx.JSON(http.StatusInternalServerError, rest.ErrorResponse{ApiError: rest.InternalServerErrorError, Message: "Failed to update user to merchant account"})
-			return
-		}
-
-		u.logger.Info("Successfully updated user to merchant account with ID: ", id)
-		ctx.JSON(http.StatusOK, rest.NewAPIResponse(http.StatusOK, "User updated to merchant account successfully", nil))
-	}
-}
-
 func (u *UserController) DeleteUser() func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
 		idStr := ctx.Param("id")
@@ -0,0 +1,25 @@
+package request
+
+import "github.com/hthinh24/go-store/services/identity/internal/errors"
+
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token              string `json:"token" binding:"required"`
+	NewPassword        string `json:"new_password" binding:"required,min=8,max=64"`
+	NewPasswordConfirm string `json:"new_password_confirm" binding:"required"`
+}
+
+func (r *ResetPasswordRequest) Validate() error {
+	if r.NewPassword != r.NewPasswordConfirm {
+		return errors.ErrPasswordMismatch{}
+	}
+
+	return nil
+}
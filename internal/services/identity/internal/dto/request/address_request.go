@@ -0,0 +1,61 @@
+package request
+
+import (
+	"strings"
+
+	"github.com/hthinh24/go-store/services/identity/internal/address"
+	"github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+// AddressRequest creates an address or replaces all fields of one, the sizes follow
+// order_addresses of the order service
+type AddressRequest struct {
+	FullName          string `json:"full_name" binding:"required,max=255"`
+	Phone             string `json:"phone" binding:"required,max=32"`
+	AddressLine       string `json:"address_line" binding:"required,max=500"`
+	City              string `json:"city" binding:"required,max=100"`
+	State             string `json:"state" binding:"max=100"`
+	PostalCode        string `json:"postal_code" binding:"required,max=20"`
+	Country           string `json:"country" binding:"required"`
+	IsDefaultShipping bool   `json:"is_default_shipping"`
+	IsDefaultBilling  bool   `json:"is_default_billing"`
+}
+
+// Validate normalizes the address and checks the state, postal code and phone against the
+// rules of its country
+func (r *AddressRequest) Validate() error {
+	r.FullName = strings.TrimSpace(r.FullName)
+	r.AddressLine = strings.TrimSpace(r.AddressLine)
+	r.City = strings.TrimSpace(r.City)
+	r.State = strings.TrimSpace(r.State)
+	r.Country = strings.ToUpper(strings.TrimSpace(r.Country))
+	r.PostalCode = address.NormalizePostalCode(r.PostalCode)
+	r.Phone = address.NormalizePhone(r.Phone)
+
+	for field, value := range map[string]string{
+		"full_name":    r.FullName,
+		"address_line": r.AddressLine,
+		"city":         r.City,
+	} {
+		if value == "" {
+			return errors.ErrInvalidUserData{Field: field, Message: "must not be blank"}
+		}
+	}
+
+	if !address.IsCountryCode(r.Country) {
+		return errors.ErrInvalidUserData{Field: "country", Message: "must be an ISO 3166-1 alpha-2 code, e.g. VN"}
+	}
+
+	rule := address.RuleFor(r.Country)
+	if rule.StateRequired && r.State == "" {
+		return errors.ErrInvalidUserData{Field: "state", Message: "is required for " + r.Country}
+	}
+	if !rule.PostalCode.MatchString(r.PostalCode) {
+		return errors.ErrInvalidUserData{Field: "postal_code", Message: "is not valid for " + r.Country}
+	}
+	if !rule.Phone.MatchString(r.Phone) {
+		return errors.ErrInvalidUserData{Field: "phone", Message: "is not valid for " + r.Country}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package request
+
+import (
+	"errors"
+	"testing"
+
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+func TestAddressRequestValidateState(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    AddressRequest
+		wantErr error
+	}{
+		{
+			name: "VN with state",
+			data: AddressRequest{FullName: "A", Phone: "0901234567", AddressLine: "1 Le Loi", City: "Thu Duc",
+				State: "Ho Chi Minh", PostalCode: "700000", Country: "vn"},
+		},
+		{
+			name: "VN without state",
+			data: AddressRequest{FullName: "A", Phone: "0901234567", AddressLine: "1 Le Loi", City: "Thu Duc",
+				State: " ", PostalCode: "700000", Country: "VN"},
+			wantErr: customErr.ErrInvalidUserData{Field: "state", Message: "is required for VN"},
+		},
+		{
+			name: "SG without state",
+			data: AddressRequest{FullName: "A", Phone: "+65 6123 4567", AddressLine: "1 Raffles Place", City: "Singapore",
+				PostalCode: "048616", Country: "SG"},
+		},
+		{
+			name: "GB without state",
+			data: AddressRequest{FullName: "A", Phone: "020 7946 0018", AddressLine: "10 Downing Street", City: "London",
+				PostalCode: "sw1a 2aa", Country: "GB"},
+		},
+		{
+			name: "US without state",
+			data: AddressRequest{FullName: "A", Phone: "(212) 555-0123", AddressLine: "1 Main Street", City: "New York",
+				PostalCode: "10001", Country: "US"},
+			wantErr: customErr.ErrInvalidUserData{Field: "state", Message: "is required for US"},
+		},
+		{
+			name: "other country without state",
+			data: AddressRequest{FullName: "A", Phone: "+33 1 23 45 67 89", AddressLine: "1 Rue de Rivoli", City: "Paris",
+				PostalCode: "75001", Country: "FR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.data.Validate()
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package request
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	// Scopes are the permissions requests made with the key get, each must be held by the user
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,required"`
+	// ExpiresAt is optional, keys without it last until revoked
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (r *CreateAPIKeyRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.ErrInvalidUserData{Field: "name", Message: "must not be blank"}
+	}
+
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		return errors.ErrInvalidUserData{Field: "expires_at", Message: "must be in the future"}
+	}
+
+	return nil
+}
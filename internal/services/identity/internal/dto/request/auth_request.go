@@ -4,3 +4,12 @@ type AuthRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8,max=64"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest optionally carries the refresh token of the session, it is revoked as well
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
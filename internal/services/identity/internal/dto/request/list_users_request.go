@@ -0,0 +1,34 @@
+package request
+
+import (
+	"time"
+
+	"github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+// ListUsersRequest is bound from the query string of the admin user listing. Pages are
+// chosen either by page_number or, for stable paging through large results, by the
+// next_cursor of the previous page
+type ListUsersRequest struct {
+	PageSize    int       `form:"page_size"`
+	PageNumber  int       `form:"page_number"`
+	Cursor      string    `form:"cursor"`
+	Status      string    `form:"status" binding:"omitempty,oneof=ACTIVE PENDING INACTIVE DELETED"`
+	Role        string    `form:"role"`
+	CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Search      string    `form:"q" binding:"max=100"`
+	SortBy      string    `form:"sort_by" binding:"omitempty,oneof=id email first_name last_name status created_at"`
+	SortOrder   string    `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+}
+
+func (r *ListUsersRequest) Validate() error {
+	if !r.CreatedFrom.IsZero() && !r.CreatedTo.IsZero() && r.CreatedTo.Before(r.CreatedFrom) {
+		return errors.ErrInvalidUserData{Field: "created_to", Message: "must not be before created_from"}
+	}
+	if r.Cursor != "" && r.PageNumber > 0 {
+		return errors.ErrInvalidUserData{Field: "cursor", Message: "cannot be combined with page_number"}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package request
+
+import (
+	"strings"
+
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+type MerchantApplicationRequest struct {
+	BusinessName    string `json:"business_name" binding:"required,max=255"`
+	TaxID           string `json:"tax_id" binding:"required,max=64"`
+	ContactName     string `json:"contact_name" binding:"required,max=255"`
+	ContactEmail    string `json:"contact_email" binding:"required,email,max=255"`
+	ContactPhone    string `json:"contact_phone" binding:"required,max=32"`
+	BusinessAddress string `json:"business_address" binding:"required,max=512"`
+}
+
+type ListMerchantApplicationsRequest struct {
+	PageSize   int    `form:"page_size"`
+	PageNumber int    `form:"page_number"`
+	Status     string `form:"status" binding:"omitempty,oneof=SUBMITTED UNDER_REVIEW APPROVED REJECTED"`
+}
+
+type MerchantApplicationDecisionRequest struct {
+	Status string `json:"status" binding:"required,oneof=APPROVED REJECTED"`
+	Reason string `json:"reason" binding:"max=512"`
+}
+
+func (r *MerchantApplicationDecisionRequest) Validate() error {
+	// Applicants are told why they were rejected
+	if r.Status == string(constants.MerchantApplicationRejected) && strings.TrimSpace(r.Reason) == "" {
+		return errors.ErrInvalidUserData{Field: "reason", Message: "is required to reject an application"}
+	}
+
+	return nil
+}
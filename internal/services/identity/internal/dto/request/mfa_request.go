@@ -0,0 +1,42 @@
+package request
+
+import "github.com/hthinh24/go-store/services/identity/internal/errors"
+
+// MFACodeRequest carries a code of the authenticator or, when it is lost, a recovery code
+type MFACodeRequest struct {
+	Code         string `json:"code" binding:"omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+func (r *MFACodeRequest) Validate() error {
+	if (r.Code == "") == (r.RecoveryCode == "") {
+		return errors.ErrInvalidUserData{Field: "code", Message: "exactly one of code and recovery_code is required"}
+	}
+
+	return nil
+}
+
+// MFAConfirmRequest confirms an enrollment with a code of the new authenticator
+type MFAConfirmRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// MFAChallengeRequest completes a login that returned an MFA challenge
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	MFACodeRequest
+}
+
+// MFASetupRequest starts the enrollment a role of the user requires before logging in
+type MFASetupRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+type MFASetupConfirmRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	MFAConfirmRequest
+}
+
+type UpdateRoleMFARequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
@@ -0,0 +1,9 @@
+package request
+
+// OIDCCallbackRequest is the query of the provider redirect after a login
+type OIDCCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
@@ -0,0 +1,54 @@
+package request
+
+import (
+	"regexp"
+
+	"github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+// namePattern keeps role and permission names usable in tokens and configuration, e.g.
+// "support" or "order.refund"
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_.-]{0,63}$`)
+
+type CreateRoleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description" binding:"required,max=255"`
+}
+
+func (r *CreateRoleRequest) Validate() error {
+	return validateName(r.Name)
+}
+
+// UpdateRoleRequest only changes the description, names are referenced by tokens and routes
+type UpdateRoleRequest struct {
+	Description string `json:"description" binding:"required,max=255"`
+}
+
+type CreatePermissionRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description" binding:"required,max=255"`
+}
+
+func (r *CreatePermissionRequest) Validate() error {
+	return validateName(r.Name)
+}
+
+type UpdatePermissionRequest struct {
+	Description string `json:"description" binding:"required,max=255"`
+}
+
+type RolePermissionRequest struct {
+	Permission string `json:"permission" binding:"required"`
+}
+
+type UserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+func validateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return errors.ErrInvalidUserData{Field: "name", Message: "must be lowercase letters, digits, '.', '_' or '-' and start with a letter"}
+	}
+
+	return nil
+}
@@ -6,7 +6,8 @@ import (
 )
 
 type UpdateUserProfileRequest struct {
-	Email       *string    `json:"email,omitempty"`
+	// Email is not changed directly, the new email is mailed a confirmation link
+	Email       *string    `json:"email,omitempty" binding:"omitempty,email"`
 	LastName    *string    `json:"last_name,omitempty"`
 	FirstName   *string    `json:"first_name,omitempty"`
 	Avatar      *string    `json:"avatar,omitempty"`
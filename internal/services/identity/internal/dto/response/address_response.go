@@ -0,0 +1,18 @@
+package response
+
+import "time"
+
+type AddressResponse struct {
+	ID                int64     `json:"id"`
+	FullName          string    `json:"full_name"`
+	Phone             string    `json:"phone"`
+	AddressLine       string    `json:"address_line"`
+	City              string    `json:"city"`
+	State             string    `json:"state"`
+	PostalCode        string    `json:"postal_code"`
+	Country           string    `json:"country"`
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
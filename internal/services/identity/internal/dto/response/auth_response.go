@@ -1,5 +1,14 @@
 package response
 
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	// ExpiresIn is the access token lifetime in seconds
+	ExpiresIn int64 `json:"expires_in,omitempty"`
+	// MFARequired asks for a code of the authenticator, posted with MFAToken to /auth/mfa/verify
+	MFARequired bool `json:"mfa_required,omitempty"`
+	// MFASetupRequired asks to enroll an authenticator first, a role of the user requires it
+	MFASetupRequired bool   `json:"mfa_setup_required,omitempty"`
+	MFAToken         string `json:"mfa_token,omitempty"`
 }
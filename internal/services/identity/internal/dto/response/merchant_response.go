@@ -0,0 +1,20 @@
+package response
+
+import "time"
+
+type MerchantApplicationResponse struct {
+	ID              int64      `json:"id"`
+	UserID          int64      `json:"user_id"`
+	BusinessName    string     `json:"business_name"`
+	TaxID           string     `json:"tax_id"`
+	ContactName     string     `json:"contact_name"`
+	ContactEmail    string     `json:"contact_email"`
+	ContactPhone    string     `json:"contact_phone"`
+	BusinessAddress string     `json:"business_address"`
+	Status          string     `json:"status"`
+	ReviewerID      *int64     `json:"reviewer_id,omitempty"`
+	DecisionReason  string     `json:"decision_reason,omitempty"`
+	DecidedAt       *time.Time `json:"decided_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
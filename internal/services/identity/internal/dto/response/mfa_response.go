@@ -0,0 +1,18 @@
+package response
+
+type MFAEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth URI authenticator apps enroll from, usually shown as a QR code
+	URI string `json:"otpauth_uri"`
+}
+
+// MFARecoveryCodesResponse is the only time recovery codes are shown, they are stored hashed
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFASetupResponse completes a login that required enrolling first
+type MFASetupResponse struct {
+	AuthResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
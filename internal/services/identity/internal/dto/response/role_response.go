@@ -0,0 +1,15 @@
+package response
+
+type RoleResponse struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	MFARequired bool     `json:"mfa_required"`
+	Permissions []string `json:"permissions"`
+}
+
+type PermissionResponse struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
@@ -12,4 +12,5 @@ type UserResponse struct {
 	PhoneNumber string    `json:"phone_number" gorm:"column:phone_number;not null"`
 	DateOfBirth time.Time `json:"date_of_birth" gorm:"column:date_of_birth;not null"`
 	Status      string    `json:"status" gorm:"column:status;not null"`
+	CreatedAt   time.Time `json:"created_at"`
 }
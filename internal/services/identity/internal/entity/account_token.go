@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+type AccountTokenPurpose string
+
+const (
+	AccountTokenEmailVerification AccountTokenPurpose = "EMAIL_VERIFICATION"
+	AccountTokenPasswordReset     AccountTokenPurpose = "PASSWORD_RESET"
+	AccountTokenEmailChange       AccountTokenPurpose = "EMAIL_CHANGE"
+)
+
+// AccountToken is a single use token mailed to a user to verify the email, confirm a new
+// email or reset the password, only its SHA-256 hash is stored
+type AccountToken struct {
+	ID        int64               `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int64               `json:"user_id" gorm:"column:user_id;not null"`
+	Purpose   AccountTokenPurpose `json:"purpose" gorm:"column:purpose;not null"`
+	TokenHash string              `json:"-" gorm:"column:token_hash;unique;not null"`
+	// Email is the new email of an email change token, it replaces the email once confirmed
+	Email     string     `json:"email" gorm:"column:email"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	UsedAt    *time.Time `json:"used_at" gorm:"column:used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;<-:create"`
+}
+
+func (a AccountToken) TableName() string {
+	return "account_tokens"
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// APIKey is a long lived credential for automation, only its SHA-256 hash is stored. Its
+// scopes were a subset of the permissions of the user at creation, requests made with it get
+// the scopes the user still holds.
+type APIKey struct {
+	ID     int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID int64  `json:"user_id" gorm:"column:user_id;not null"`
+	Name   string `json:"name" gorm:"column:name;not null"`
+	// Prefix is the non secret start of the key, shown so users can tell their keys apart
+	Prefix     string     `json:"prefix" gorm:"column:prefix;not null"`
+	KeyHash    string     `json:"-" gorm:"column:key_hash;unique;not null"`
+	Scopes     []string   `json:"scopes" gorm:"column:scopes;serializer:json;not null"`
+	ExpiresAt  *time.Time `json:"expires_at" gorm:"column:expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" gorm:"column:last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"column:revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;<-:create"`
+}
+
+func (a APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsActive reports whether the key can still authenticate requests, keys without expiry
+// last until revoked
+func (a APIKey) IsActive(now time.Time) bool {
+	return a.RevokedAt == nil && (a.ExpiresAt == nil || now.Before(*a.ExpiresAt))
+}
@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+type AuditEvent string
+
+const (
+	AuditEventAccountLocked   AuditEvent = "ACCOUNT_LOCKED"
+	AuditEventIPLocked        AuditEvent = "IP_LOCKED"
+	AuditEventAccountUnlocked AuditEvent = "ACCOUNT_UNLOCKED"
+
+	AuditEventRoleCreated           AuditEvent = "ROLE_CREATED"
+	AuditEventRoleUpdated           AuditEvent = "ROLE_UPDATED"
+	AuditEventRoleDeleted           AuditEvent = "ROLE_DELETED"
+	AuditEventRoleMFAUpdated        AuditEvent = "ROLE_MFA_UPDATED"
+	AuditEventPermissionCreated     AuditEvent = "PERMISSION_CREATED"
+	AuditEventPermissionUpdated     AuditEvent = "PERMISSION_UPDATED"
+	AuditEventPermissionDeleted     AuditEvent = "PERMISSION_DELETED"
+	AuditEventRolePermissionAdded   AuditEvent = "ROLE_PERMISSION_ADDED"
+	AuditEventRolePermissionRemoved AuditEvent = "ROLE_PERMISSION_REMOVED"
+	AuditEventUserRoleAssigned      AuditEvent = "USER_ROLE_ASSIGNED"
+	AuditEventUserRoleRevoked       AuditEvent = "USER_ROLE_REVOKED"
+
+	AuditEventMerchantApplicationSubmitted   AuditEvent = "MERCHANT_APPLICATION_SUBMITTED"
+	AuditEventMerchantApplicationUnderReview AuditEvent = "MERCHANT_APPLICATION_UNDER_REVIEW"
+	AuditEventMerchantApplicationApproved    AuditEvent = "MERCHANT_APPLICATION_APPROVED"
+	AuditEventMerchantApplicationRejected    AuditEvent = "MERCHANT_APPLICATION_REJECTED"
+
+	AuditEventAPIKeyCreated AuditEvent = "API_KEY_CREATED"
+	AuditEventAPIKeyRevoked AuditEvent = "API_KEY_REVOKED"
+)
+
+// AuditLog records a security relevant event, rows are only ever inserted
+type AuditLog struct {
+	ID    int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Event AuditEvent `json:"event" gorm:"column:event;not null"`
+	// UserID is the user the event is about, unknown for events of emails without a user
+	UserID *int64 `json:"user_id" gorm:"column:user_id"`
+	// ActorID is the user that caused the event, empty for events of the system
+	ActorID   *int64    `json:"actor_id" gorm:"column:actor_id"`
+	IPAddress string    `json:"ip_address" gorm:"column:ip_address"`
+	Details   string    `json:"details" gorm:"column:details"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime;<-:create"`
+}
+
+func (a AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+)
+
+// MerchantApplication is the request of a user to sell on the store, the merchant role is
+// granted when an admin approves it
+type MerchantApplication struct {
+	ID              int64                               `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID          int64                               `json:"user_id" gorm:"column:user_id;not null"`
+	BusinessName    string                              `json:"business_name" gorm:"column:business_name;not null"`
+	TaxID           string                              `json:"tax_id" gorm:"column:tax_id;not null"`
+	ContactName     string                              `json:"contact_name" gorm:"column:contact_name;not null"`
+	ContactEmail    string                              `json:"contact_email" gorm:"column:contact_email;not null"`
+	ContactPhone    string                              `json:"contact_phone" gorm:"column:contact_phone;not null"`
+	BusinessAddress string                              `json:"business_address" gorm:"column:business_address;not null"`
+	Status          constants.MerchantApplicationStatus `json:"status" gorm:"column:status;not null"`
+	// ReviewerID is the admin who took the application into review or decided it
+	ReviewerID     *int64     `json:"reviewer_id" gorm:"column:reviewer_id"`
+	DecisionReason string     `json:"decision_reason" gorm:"column:decision_reason"`
+	DecidedAt      *time.Time `json:"decided_at" gorm:"column:decided_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;<-:create"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (m MerchantApplication) TableName() string {
+	return "merchant_applications"
+}
+
+// AuditDetails describes the saved state of the application for its audit log
+func (m MerchantApplication) AuditDetails() string {
+	return fmt.Sprintf("application: %d, status: %s", m.ID, m.Status)
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OutboxEventType string
+
+const (
+	OutboxEventUserRegistered OutboxEventType = "UserRegistered"
+)
+
+// OutboxEvent is a side effect written in the transaction of the change causing it, the
+// relay delivers it at least once afterwards
+type OutboxEvent struct {
+	ID          int64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventType   OutboxEventType `json:"event_type" gorm:"column:event_type;not null"`
+	AggregateID int64           `json:"aggregate_id" gorm:"column:aggregate_id;not null"`
+	Payload     string          `json:"payload" gorm:"column:payload;not null"`
+	Attempts    int             `json:"attempts" gorm:"column:attempts;not null;default:0"`
+	// NextAttemptAt is when the event is due, claiming an event moves it past the delivery
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"column:next_attempt_at;not null"`
+	LastError     string     `json:"last_error" gorm:"column:last_error"`
+	ProcessedAt   *time.Time `json:"processed_at" gorm:"column:processed_at"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;<-:create"`
+}
+
+func (o OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// UserRegisteredPayload is the payload of OutboxEventUserRegistered
+type UserRegisteredPayload struct {
+	UserID int64  `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// NewUserRegisteredEvent returns the event of a created user, due at once
+func NewUserRegisteredEvent(user *User) (*OutboxEvent, error) {
+	payload, err := json.Marshal(UserRegisteredPayload{UserID: user.ID, Email: user.Email})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxEvent{
+		EventType:     OutboxEventUserRegistered,
+		AggregateID:   user.ID,
+		Payload:       string(payload),
+		NextAttemptAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// RefreshToken is a single use refresh token, only its SHA-256 hash is stored.
+// Tokens rotated from the same login share a FamilyID, so a reused token can revoke
+// the whole session.
+type RefreshToken struct {
+	ID        int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int64      `json:"user_id" gorm:"column:user_id;not null"`
+	FamilyID  string     `json:"family_id" gorm:"column:family_id;not null"`
+	TokenHash string     `json:"-" gorm:"column:token_hash;unique;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	RevokedAt *time.Time `json:"revoked_at" gorm:"column:revoked_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;<-:create"`
+}
+
+func (r RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsActive reports whether the token can still be exchanged
+func (r RefreshToken) IsActive(now time.Time) bool {
+	return r.RevokedAt == nil && now.Before(r.ExpiresAt)
+}
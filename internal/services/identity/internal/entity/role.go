@@ -4,12 +4,13 @@ type Role struct {
 	ID          int64  `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name        string `json:"name" gorm:"column:name;not null"`
 	Description string `json:"description" gorm:"column:description;not null"`
+	// MFARequired makes users of the role enroll an authenticator before they can log in
+	MFARequired bool `json:"mfa_required" gorm:"column:mfa_required;not null"`
 }
 
 type RolePermissions struct {
-	ID           int64 `json:"id" gorm:"primaryKey;autoIncrement" `
-	PermissionID int64 `json:"permissions_id" gorm:"column:permission_id;not null" `
-	RoleID       int64 `json:"role_id" gorm:"column:role_id;not null"`
+	RoleID       int64 `json:"role_id" gorm:"column:role_id;primaryKey"`
+	PermissionID int64 `json:"permission_id" gorm:"column:permission_id;primaryKey"`
 }
 
 func (r Role) TableName() string {
@@ -18,6 +18,9 @@ type User struct {
 	PhoneNumber  string    `json:"phone_number" gorm:"column:phone_number;not null"`
 	DateOfBirth  time.Time `json:"date_of_birth" gorm:"column:date_of_birth;not null"`
 	Status       string    `json:"status" gorm:"column:status;not null"`
+	// EmailVerifiedAt is when the user last proved to own the email, by a mailed token or an
+	// identity provider, nil for emails nobody vouched for
+	EmailVerifiedAt *time.Time `json:"email_verified_at" gorm:"column:email_verified_at"`
 }
 
 func (u User) TableName() string {
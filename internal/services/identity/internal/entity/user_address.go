@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// UserAddress is an entry of the address book of a user, its fields match order_addresses
+// of the order service so checkout can copy it as is
+type UserAddress struct {
+	ID          int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      int64  `json:"user_id" gorm:"column:user_id;not null"`
+	FullName    string `json:"full_name" gorm:"column:full_name;not null"`
+	Phone       string `json:"phone" gorm:"column:phone;not null"`
+	AddressLine string `json:"address_line" gorm:"column:address_line;not null"`
+	City        string `json:"city" gorm:"column:city;not null"`
+	State       string `json:"state" gorm:"column:state;not null"`
+	PostalCode  string `json:"postal_code" gorm:"column:postal_code;not null"`
+	// Country is an ISO 3166-1 alpha-2 code
+	Country           string    `json:"country" gorm:"column:country;not null"`
+	IsDefaultShipping bool      `json:"is_default_shipping" gorm:"column:is_default_shipping;not null"`
+	IsDefaultBilling  bool      `json:"is_default_billing" gorm:"column:is_default_billing;not null"`
+	CreatedAt         time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime;<-:create"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (u UserAddress) TableName() string {
+	return "user_addresses"
+}
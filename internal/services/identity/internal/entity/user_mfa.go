@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// UserMFA is the TOTP authenticator of a user, MFA is enabled once it is confirmed
+type UserMFA struct {
+	UserID int64 `json:"user_id" gorm:"column:user_id;primaryKey"`
+	// Secret is encrypted, unlike passwords it has to be read back to check codes
+	Secret      string     `json:"-" gorm:"column:secret;not null"`
+	ConfirmedAt *time.Time `json:"confirmed_at" gorm:"column:confirmed_at"`
+	// LastUsedStep is the time step of the last accepted code, a code is never accepted twice
+	LastUsedStep int64     `json:"-" gorm:"column:last_used_step;not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (u UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+func (u UserMFA) IsConfirmed() bool {
+	return u.ConfirmedAt != nil
+}
+
+// MFARecoveryCode is a one time code that replaces the authenticator, only its SHA-256 hash
+// is stored
+type MFARecoveryCode struct {
+	ID        int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int64      `json:"user_id" gorm:"column:user_id;not null"`
+	CodeHash  string     `json:"-" gorm:"column:code_hash;not null"`
+	UsedAt    *time.Time `json:"used_at" gorm:"column:used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;<-:create"`
+}
+
+func (m MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // User related errors
 type ErrUserNotFound struct{}
@@ -9,12 +12,6 @@ func (e ErrUserNotFound) Error() string {
 	return "User not found"
 }
 
-type ErrCartCreationFailed struct{}
-
-func (e ErrCartCreationFailed) Error() string {
-	return "Failed to create cart for user"
-}
-
 type ErrUserAlreadyExists struct {
 	Email string
 }
@@ -47,6 +44,233 @@ func (e ErrRoleNotFound) Error() string {
 	return fmt.Sprintf("Role '%s' not found", e.Name)
 }
 
+type ErrRoleAlreadyExists struct {
+	Name string
+}
+
+func (e ErrRoleAlreadyExists) Error() string {
+	return fmt.Sprintf("Role '%s' already exists", e.Name)
+}
+
+// ErrProtectedRole is returned when deleting a role the service itself depends on
+type ErrProtectedRole struct {
+	Name string
+}
+
+func (e ErrProtectedRole) Error() string {
+	return fmt.Sprintf("Role '%s' is built in and cannot be deleted", e.Name)
+}
+
+// ErrInvalidRoleChange is returned for role changes that would lock admins out
+type ErrInvalidRoleChange struct {
+	Message string
+}
+
+func (e ErrInvalidRoleChange) Error() string {
+	return e.Message
+}
+
+// Permission related errors
+type ErrPermissionNotFound struct {
+	Name string
+}
+
+func (e ErrPermissionNotFound) Error() string {
+	return fmt.Sprintf("Permission '%s' not found", e.Name)
+}
+
+type ErrPermissionAlreadyExists struct {
+	Name string
+}
+
+func (e ErrPermissionAlreadyExists) Error() string {
+	return fmt.Sprintf("Permission '%s' already exists", e.Name)
+}
+
+// Merchant application related errors
+type ErrMerchantApplicationNotFound struct{}
+
+func (e ErrMerchantApplicationNotFound) Error() string {
+	return "Merchant application not found"
+}
+
+type ErrOpenMerchantApplication struct{}
+
+func (e ErrOpenMerchantApplication) Error() string {
+	return "A merchant application is already waiting for a decision"
+}
+
+type ErrAlreadyMerchant struct{}
+
+func (e ErrAlreadyMerchant) Error() string {
+	return "User is already a merchant"
+}
+
+type ErrInvalidApplicationTransition struct {
+	From string
+	To   string
+}
+
+func (e ErrInvalidApplicationTransition) Error() string {
+	return fmt.Sprintf("Merchant application cannot move from %s to %s", e.From, e.To)
+}
+
+// Address book related errors
+type ErrAddressNotFound struct{}
+
+func (e ErrAddressNotFound) Error() string {
+	return "Address not found"
+}
+
+type ErrAddressLimitReached struct {
+	Limit int
+}
+
+func (e ErrAddressLimitReached) Error() string {
+	return fmt.Sprintf("An address book holds at most %d addresses", e.Limit)
+}
+
+// API key related errors
+type ErrAPIKeyNotFound struct{}
+
+func (e ErrAPIKeyNotFound) Error() string {
+	return "API key not found"
+}
+
+// Refresh token related errors
+type ErrInvalidRefreshToken struct{}
+
+func (e ErrInvalidRefreshToken) Error() string {
+	return "Invalid or expired refresh token"
+}
+
+// ErrRefreshTokenReused is returned when a rotated token is presented again, the whole
+// token family is revoked since either the client or an attacker holds a stolen copy
+type ErrRefreshTokenReused struct{}
+
+func (e ErrRefreshTokenReused) Error() string {
+	return "Refresh token has already been used"
+}
+
+// ErrInvalidAccountToken is returned for email verification and password reset tokens that
+// are unknown, used or expired
+type ErrInvalidAccountToken struct{}
+
+func (e ErrInvalidAccountToken) Error() string {
+	return "Invalid or expired token"
+}
+
+// Access token revocation errors
+type ErrTokenRevoked struct{}
+
+func (e ErrTokenRevoked) Error() string {
+	return "Token has been revoked"
+}
+
+type ErrTokenRevocation struct {
+	Operation string
+}
+
+func (e ErrTokenRevocation) Error() string {
+	return fmt.Sprintf("Token revocation store failed during %s", e.Operation)
+}
+
+// External identity provider errors
+type ErrOIDCProviderNotFound struct {
+	Name string
+}
+
+func (e ErrOIDCProviderNotFound) Error() string {
+	return fmt.Sprintf("Identity provider '%s' not found", e.Name)
+}
+
+// ErrInvalidOIDCState is returned for callbacks of logins that were never started, already
+// completed or expired
+type ErrInvalidOIDCState struct{}
+
+func (e ErrInvalidOIDCState) Error() string {
+	return "Invalid or expired login state"
+}
+
+type ErrOIDCAuthentication struct{}
+
+func (e ErrOIDCAuthentication) Error() string {
+	return "Identity provider authentication failed"
+}
+
+// ErrEmailNotVerified is returned when the provider cannot vouch for the email, it is never
+// used to link or create accounts
+type ErrEmailNotVerified struct{}
+
+func (e ErrEmailNotVerified) Error() string {
+	return "Email is not verified by the identity provider"
+}
+
+// ErrAccountLinkedToOtherProvider is returned when the email belongs to a user of another
+// external provider, a user is linked to a single provider
+type ErrAccountLinkedToOtherProvider struct {
+	Email string
+}
+
+func (e ErrAccountLinkedToOtherProvider) Error() string {
+	return fmt.Sprintf("User with email '%s' is linked to another identity provider", e.Email)
+}
+
+// ErrAccountEmailNotVerified is returned when a provider identity matches the email of a user
+// that never proved to own it, linking would hand the account to whoever registered it
+type ErrAccountEmailNotVerified struct {
+	Email string
+}
+
+func (e ErrAccountEmailNotVerified) Error() string {
+	return fmt.Sprintf("User with email '%s' must verify the email before signing in with an identity provider", e.Email)
+}
+
+// ErrTooManyLoginAttempts is returned while an account or client IP is locked out, whether
+// or not the password is right
+type ErrTooManyLoginAttempts struct {
+	RetryAfter time.Duration
+}
+
+func (e ErrTooManyLoginAttempts) Error() string {
+	return "Too many failed login attempts, try again later"
+}
+
+// Multi-factor authentication errors
+type ErrMFANotEnrolled struct{}
+
+func (e ErrMFANotEnrolled) Error() string {
+	return "Multi-factor authentication is not enabled"
+}
+
+type ErrMFAAlreadyEnabled struct{}
+
+func (e ErrMFAAlreadyEnabled) Error() string {
+	return "Multi-factor authentication is already enabled"
+}
+
+// ErrMFARequired is returned when a role of the user requires MFA, it cannot be disabled
+type ErrMFARequired struct{}
+
+func (e ErrMFARequired) Error() string {
+	return "Multi-factor authentication is required for a role of the user"
+}
+
+// ErrInvalidMFACode is returned for wrong, reused and already used recovery codes
+type ErrInvalidMFACode struct{}
+
+func (e ErrInvalidMFACode) Error() string {
+	return "Invalid authentication code"
+}
+
+// ErrInvalidMFAChallenge is returned for challenges that are unknown, expired, of another
+// purpose or ended after too many wrong codes
+type ErrInvalidMFAChallenge struct{}
+
+func (e ErrInvalidMFAChallenge) Error() string {
+	return "Invalid or expired MFA challenge"
+}
+
 // Database related errors
 type ErrDatabaseTransaction struct {
 	Operation string
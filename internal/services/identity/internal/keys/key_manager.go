@@ -0,0 +1,222 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	jwtConfig "github.com/hthinh24/go-store/internal/pkg/config/jwt"
+	"github.com/hthinh24/go-store/internal/pkg/jwks"
+)
+
+// minRSAKeyBits rejects RSA keys too short to sign tokens with
+const minRSAKeyBits = 2048
+
+// verificationKey is a published key, a zero validUntil never expires
+type verificationKey struct {
+	jwks.Key
+	validUntil time.Time
+}
+
+// KeyManager signs access tokens with the current key and verifies them with the current
+// and the previous keys that are still in their rotation grace period
+type KeyManager struct {
+	signingKeyID string
+	signingKey   crypto.Signer
+	method       jwt.SigningMethod
+	keys         map[string]verificationKey
+}
+
+// LoadKeyManager loads the configured keys from disk. With generateMissing a missing signing
+// key file is created with a new Ed25519 key, for local development only.
+func LoadKeyManager(cfg jwtConfig.JWT, generateMissing bool) (*KeyManager, error) {
+	if cfg.SigningKey.ID == "" || cfg.SigningKey.Path == "" {
+		return nil, errors.New("jwt signing key id and path are required")
+	}
+
+	if generateMissing {
+		if err := generateKeyIfMissing(cfg.SigningKey.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	signingKey, err := loadPrivateKey(cfg.SigningKey.Path)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", cfg.SigningKey.ID, err)
+	}
+
+	current, err := jwks.NewKey(cfg.SigningKey.ID, signingKey.Public())
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", cfg.SigningKey.ID, err)
+	}
+
+	manager := &KeyManager{
+		signingKeyID: current.ID,
+		signingKey:   signingKey,
+		method:       jwt.GetSigningMethod(current.Algorithm),
+		keys:         map[string]verificationKey{current.ID: {Key: current}},
+	}
+
+	gracePeriod, err := cfg.GetRotationGracePeriod()
+	if err != nil {
+		return nil, fmt.Errorf("invalid rotation grace period: %w", err)
+	}
+
+	for _, previous := range cfg.PreviousKeys {
+		if _, exists := manager.keys[previous.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id: %s", previous.ID)
+		}
+
+		retiredAt, err := previous.GetRetiredAt()
+		if err != nil {
+			return nil, fmt.Errorf("previous key %s needs an RFC 3339 retired_at: %w", previous.ID, err)
+		}
+
+		publicKey, err := loadPublicKey(previous.Path)
+		if err != nil {
+			return nil, fmt.Errorf("previous key %s: %w", previous.ID, err)
+		}
+
+		key, err := jwks.NewKey(previous.ID, publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("previous key %s: %w", previous.ID, err)
+		}
+		manager.keys[key.ID] = verificationKey{Key: key, validUntil: retiredAt.Add(gracePeriod)}
+	}
+
+	return manager, nil
+}
+
+// Sign signs the claims with the current key and sets its kid header
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(m.method, claims)
+	token.Header["kid"] = m.signingKeyID
+	return token.SignedString(m.signingKey)
+}
+
+// Keyfunc resolves the verification key of a token, retired keys past their grace period are unknown
+func (m *KeyManager) Keyfunc() jwt.Keyfunc {
+	return jwks.Keyfunc(m.lookup)
+}
+
+// KeySet returns the keys verifiers should accept right now
+func (m *KeyManager) KeySet() (jwks.Set, error) {
+	now := time.Now()
+
+	keys := make([]jwks.Key, 0, len(m.keys))
+	for _, key := range m.keys {
+		if key.active(now) {
+			keys = append(keys, key.Key)
+		}
+	}
+
+	return jwks.NewSet(keys)
+}
+
+func (m *KeyManager) lookup(kid string) (jwks.Key, bool) {
+	key, ok := m.keys[kid]
+	if !ok || !key.active(time.Now()) {
+		return jwks.Key{}, false
+	}
+	return key.Key, true
+}
+
+func (k verificationKey) active(now time.Time) bool {
+	return k.validUntil.IsZero() || now.Before(k.validUntil)
+}
+
+func loadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("expected a private key, got %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch privateKey := key.(type) {
+	case *rsa.PrivateKey:
+		if privateKey.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("rsa key shorter than %d bits", minRSAKeyBits)
+		}
+		return privateKey, nil
+	case ed25519.PrivateKey:
+		return privateKey, nil
+	default:
+		return nil, jwks.ErrUnsupportedKey
+	}
+}
+
+// loadPublicKey accepts a public key, or the private key of a retired signing key as is
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		privateKey, err := loadPrivateKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return privateKey.Public(), nil
+	}
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+	return block, nil
+}
+
+func generateKeyIfMissing(path string) error {
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+}
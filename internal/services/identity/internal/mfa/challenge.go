@@ -0,0 +1,21 @@
+package mfa
+
+type ChallengePurpose string
+
+const (
+	// ChallengeVerify is completed with a code of the enrolled authenticator
+	ChallengeVerify ChallengePurpose = "verify"
+	// ChallengeSetup is given to users whose role requires MFA before they enrolled, it
+	// allows enrollment only and is completed by confirming it
+	ChallengeSetup ChallengePurpose = "setup"
+)
+
+// MaxChallengeAttempts is how many wrong codes end a challenge, the password has to be
+// entered again after that
+const MaxChallengeAttempts = 5
+
+// Challenge is the second login step of a user whose password was already checked
+type Challenge struct {
+	UserID  int64            `json:"user_id"`
+	Purpose ChallengePurpose `json:"purpose"`
+}
@@ -0,0 +1,71 @@
+package mfa
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testEncryptionKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", encryptionKeyBytes)))
+
+func TestCipherRoundTrip(t *testing.T) {
+	c, err := NewCipher(testEncryptionKey)
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+
+	encrypted, err := c.Encrypt(rfcSecret)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if strings.Contains(encrypted, rfcSecret) {
+		t.Error("the secret is readable in the ciphertext")
+	}
+
+	again, _ := c.Encrypt(rfcSecret)
+	if again == encrypted {
+		t.Error("encrypting twice gave the same ciphertext")
+	}
+
+	decrypted, err := c.Decrypt(encrypted)
+	if err != nil || decrypted != rfcSecret {
+		t.Errorf("Decrypt = %q, %v, want %q", decrypted, err, rfcSecret)
+	}
+}
+
+func TestCipherRejectsTamperedCiphertext(t *testing.T) {
+	c, _ := NewCipher(testEncryptionKey)
+	encrypted, _ := c.Encrypt(rfcSecret)
+	sealed, _ := base64.StdEncoding.DecodeString(encrypted)
+	sealed[len(sealed)-1] ^= 1
+
+	other, _ := NewCipher(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("o", encryptionKeyBytes))))
+
+	tests := []struct {
+		name   string
+		cipher *Cipher
+		value  string
+	}{
+		{"tampered", c, base64.StdEncoding.EncodeToString(sealed)},
+		{"other key", other, encrypted},
+		{"truncated", c, base64.StdEncoding.EncodeToString(sealed[:4])},
+		{"not base64", c, "not base64!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.cipher.Decrypt(tt.value); !errors.Is(err, ErrInvalidCiphertext) {
+				t.Errorf("Decrypt error = %v, want %v", err, ErrInvalidCiphertext)
+			}
+		})
+	}
+}
+
+func TestNewCipherRejectsInvalidKeys(t *testing.T) {
+	for _, key := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := NewCipher(key); err == nil {
+			t.Errorf("NewCipher(%q) succeeded", key)
+		}
+	}
+}
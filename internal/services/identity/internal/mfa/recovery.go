@@ -0,0 +1,44 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+// recoveryAlphabet leaves out characters that are easily confused when written down
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// recoveryCodeLength gives about 50 bits of entropy per code
+const recoveryCodeLength = 10
+
+// GenerateRecoveryCodes returns n one time codes formatted as xxxxx-xxxxx
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		var code strings.Builder
+		for j, v := range b {
+			if j == recoveryCodeLength/2 {
+				code.WriteByte('-')
+			}
+			// The modulo bias of 256 % 31 is negligible for these codes
+			code.WriteByte(recoveryAlphabet[int(v)%len(recoveryAlphabet)])
+		}
+		codes[i] = code.String()
+	}
+
+	return codes, nil
+}
+
+// NormalizeRecoveryCode accepts codes typed with spaces, without the dash or in upper case
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+	if len(code) != recoveryCodeLength {
+		return code
+	}
+	return code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+}
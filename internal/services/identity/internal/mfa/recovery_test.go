@@ -0,0 +1,57 @@
+package mfa
+
+import (
+	"regexp"
+	"testing"
+)
+
+var recoveryCodePattern = regexp.MustCompile(`^[abcdefghjkmnpqrstuvwxyz23456789]{5}-[abcdefghjkmnpqrstuvwxyz23456789]{5}$`)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes: %v", err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("generated %d codes, want 10", len(codes))
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if !recoveryCodePattern.MatchString(code) {
+			t.Errorf("code %q is not formatted as xxxxx-xxxxx", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q generated twice", code)
+		}
+		seen[code] = true
+
+		if normalized := NormalizeRecoveryCode(code); normalized != code {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want it unchanged", code, normalized)
+		}
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"abcde-fghjk", "abcde-fghjk"},
+		{"ABCDE-FGHJK", "abcde-fghjk"},
+		{"abcdefghjk", "abcde-fghjk"},
+		{"abcde fghjk", "abcde-fghjk"},
+		{" ab-cde fgh-jk ", "abcde-fghjk"},
+		{"abcde-fghj", "abcdefghj"},
+		{"abcde-fghjkm", "abcdefghjkm"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := NormalizeRecoveryCode(tt.code); got != tt.want {
+				t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package mfa
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 secret of the RFC 6238 test vectors, "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateRFCVectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, time.Unix(tt.unix, 0))
+			if !ok {
+				t.Fatalf("Validate(%q) at %d failed", tt.code, tt.unix)
+			}
+			if want := tt.unix / period; step != want {
+				t.Errorf("step = %d, want %d", step, want)
+			}
+		})
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := now.Unix() / period
+	key, _ := secretEncoding.DecodeString(rfcSecret)
+
+	tests := []struct {
+		name   string
+		offset int64
+		want   bool
+	}{
+		{"previous step", -1, true},
+		{"current step", 0, true},
+		{"next step", 1, true},
+		{"two steps behind", -2, false},
+		{"two steps ahead", 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, generate(key, current+tt.offset), now)
+			if ok != tt.want {
+				t.Fatalf("Validate ok = %v, want %v", ok, tt.want)
+			}
+			if ok && step != current+tt.offset {
+				t.Errorf("step = %d, want %d", step, current+tt.offset)
+			}
+		})
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{"wrong code", rfcSecret, "287083"},
+		{"short code", rfcSecret, "28708"},
+		{"long code", rfcSecret, "2870820"},
+		{"eight digit code", rfcSecret, "94287082"},
+		{"empty code", rfcSecret, ""},
+		{"other secret", "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP", "287082"},
+		{"invalid secret", "not base32!", "287082"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := Validate(tt.secret, tt.code, now); ok {
+				t.Errorf("Validate(%q, %q) succeeded", tt.secret, tt.code)
+			}
+		})
+	}
+}
+
+func TestValidateAcceptsLowerCaseSecret(t *testing.T) {
+	if _, ok := Validate(strings.ToLower(rfcSecret), "287082", time.Unix(59, 0)); !ok {
+		t.Error("Validate with a lower case secret failed")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	key, err := secretEncoding.DecodeString(secret)
+	if err != nil || len(key) != secretBytes {
+		t.Fatalf("secret %q decodes to %d bytes (%v), want %d", secret, len(key), err, secretBytes)
+	}
+
+	other, _ := GenerateSecret()
+	if other == secret {
+		t.Error("GenerateSecret returned the same secret twice")
+	}
+
+	now := time.Now()
+	if _, ok := Validate(secret, generate(key, now.Unix()/period), now); !ok {
+		t.Error("a code of the generated secret was rejected")
+	}
+}
+
+func TestKeyURI(t *testing.T) {
+	uri, err := url.Parse(KeyURI("go-store", "user@example.com", rfcSecret))
+	if err != nil {
+		t.Fatalf("KeyURI is not a URL: %v", err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/go-store:user@example.com" {
+		t.Errorf("KeyURI = %s, want otpauth://totp/go-store:user@example.com", uri)
+	}
+	query := uri.Query()
+	for name, want := range map[string]string{
+		"secret": rfcSecret, "issuer": "go-store", "algorithm": "SHA1", "digits": "6", "period": "30",
+	} {
+		if got := query.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
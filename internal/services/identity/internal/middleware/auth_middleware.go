@@ -1,38 +1,65 @@
 package middleware
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/hthinh24/go-store/internal/pkg/jwks"
 	"github.com/hthinh24/go-store/internal/pkg/logger"
 	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
 	"net/http"
 	"strings"
 )
 
+// authTypeHeader is set by the gateway to the credential type the user authenticated with
+const authTypeHeader = "X-User-Auth-Type"
+
+// authTypeAPIKey marks requests the gateway authenticated with an API key
+const authTypeAPIKey = "api_key"
+
 type AuthMiddleware struct {
-	logger    logger.Logger
-	jwtSecret string
+	logger               logger.Logger
+	keyfunc              jwt.Keyfunc
+	revocationRepository identity.TokenRevocationRepository
 }
 
+// JWTClaims are the access token claims, the token ID is the registered "jti" claim
 type JWTClaims struct {
 	UserID      int64    `json:"user_id"`
 	Email       string   `json:"email"`
 	Roles       []string `json:"roles"`
 	Permissions []string `json:"permissions"`
+	// Generation is the token generation of the user at issue time, see CheckRevocation
+	Generation int64 `json:"gen"`
 	jwt.RegisteredClaims
 }
 
-func NewAuthMiddleware(logger logger.Logger, jwtSecret string) *AuthMiddleware {
+func NewAuthMiddleware(logger logger.Logger, keyfunc jwt.Keyfunc, revocationRepository identity.TokenRevocationRepository) *AuthMiddleware {
 	return &AuthMiddleware{
-		logger:    logger,
-		jwtSecret: jwtSecret,
+		logger:               logger,
+		keyfunc:              keyfunc,
+		revocationRepository: revocationRepository,
 	}
 }
 
-// AuthRequired validates JWT token and sets user info in context
+// AuthRequired validates JWT token and sets user info in context. API keys are refused, every
+// route of the identity service manages the account or its credentials and a key must never
+// mint keys, change the email or disable MFA
 func (m *AuthMiddleware) AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		if c.GetHeader(authTypeHeader) == authTypeAPIKey || strings.HasPrefix(authHeader, "ApiKey ") {
+			m.logger.Warn("API key presented to an account management route, path: ", c.Request.URL.Path)
+			c.JSON(http.StatusForbidden, rest.ErrorResponse{
+				ApiError: rest.ForbiddenError,
+				Message:  "API keys cannot be used for this endpoint",
+			})
+			c.Abort()
+			return
+		}
+
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, rest.ErrorResponse{
 				ApiError: rest.UnauthorizedError,
@@ -52,7 +79,7 @@ func (m *AuthMiddleware) AuthRequired() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := m.ValidateToken(tokenString)
+		claims, err := m.ValidateToken(c.Request.Context(), tokenString)
 		if err != nil {
 			m.logger.Error("Failed to validate JWT token", "error", err)
 			c.JSON(http.StatusUnauthorized, rest.ErrorResponse{
@@ -134,19 +161,45 @@ func (m *AuthMiddleware) RequireRole(role string) gin.HandlerFunc {
 	}
 }
 
-func (m *AuthMiddleware) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(m.jwtSecret), nil
-	})
+func (m *AuthMiddleware) ValidateToken(ctx context.Context, tokenString string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, m.keyfunc, jwt.WithValidMethods(jwks.ValidMethods()))
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert claims to our custom JWTClaims type & check validity
 	claims, ok := token.Claims.(*JWTClaims)
-	if ok {
-		return claims, nil
+	if !ok {
+		return nil, jwt.ErrTokenMalformed
+	}
+
+	if err := CheckRevocation(ctx, m.revocationRepository, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// CheckRevocation rejects tokens that were logged out, or issued before their user logged
+// out of all sessions. Errors of the revocation store reject the token as well.
+func CheckRevocation(ctx context.Context, revocationRepository identity.TokenRevocationRepository, claims *JWTClaims) error {
+	if claims.ID != "" {
+		revoked, err := revocationRepository.IsTokenRevoked(ctx, claims.ID)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return customErr.ErrTokenRevoked{}
+		}
+	}
+
+	generation, err := revocationRepository.GetTokenGeneration(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if claims.Generation < generation {
+		return customErr.ErrTokenRevoked{}
 	}
 
-	return nil, jwt.ErrTokenMalformed
+	return nil
 }
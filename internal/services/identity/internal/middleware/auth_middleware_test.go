@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+)
+
+func TestAuthRequiredRejectsAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keyfunc := func(*jwt.Token) (interface{}, error) {
+		t.Fatal("API key requests must be refused before any token is parsed")
+		return nil, nil
+	}
+	m := NewAuthMiddleware(logger.NewAppLogger("development"), keyfunc, nil)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+	}{
+		{
+			name:    "API key authorization",
+			headers: map[string]string{"Authorization": "ApiKey gsk_secret"},
+		},
+		{
+			name:    "marked by the gateway",
+			headers: map[string]string{"Authorization": "Bearer token", "X-User-Auth-Type": "api_key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.POST("/users/me/api-keys", m.AuthRequired(), func(c *gin.Context) {
+				c.Status(http.StatusCreated)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/users/me/api-keys", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
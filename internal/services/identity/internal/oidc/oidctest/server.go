@@ -0,0 +1,257 @@
+// Package oidctest runs a local OpenID Connect provider for tests, it serves discovery, the
+// authorization and token endpoints and its key set without any network access
+package oidctest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hthinh24/go-store/internal/pkg/jwks"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+)
+
+const (
+	ClientID     = "stub-client"
+	ClientSecret = "stub-secret"
+	RedirectURL  = "http://localhost:8080/api/v1/auth/oidc/stub/callback"
+	keyID        = "stub-key"
+)
+
+// Identity is the user the provider logs in
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+type authorization struct {
+	identity      Identity
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+}
+
+// Server is a stub provider. Authorizations log in the identity set with SetIdentity,
+// ModifyIDToken can break the issued ID tokens to test their verification.
+type Server struct {
+	*httptest.Server
+	ModifyIDToken func(claims jwt.MapClaims)
+
+	mu       sync.Mutex
+	identity Identity
+	key      ed25519.PrivateKey
+	codes    map[string]authorization
+}
+
+// NewServer starts a stub provider, close it when done
+func NewServer() *Server {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{
+		identity: Identity{
+			Subject:       "stub-subject",
+			Email:         "stub@example.com",
+			EmailVerified: true,
+			GivenName:     "Stub",
+			FamilyName:    "User",
+		},
+		key:   key,
+		codes: make(map[string]authorization),
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/.well-known/openid-configuration", s.discovery)
+	router.GET("/authorize", s.authorize)
+	router.POST("/token", s.token)
+	router.GET(jwks.Path, s.keySet)
+
+	s.Server = httptest.NewServer(router)
+	return s
+}
+
+// ProviderConfig returns the configuration of a provider logging in through the stub
+func (s *Server) ProviderConfig() config.OIDCProvider {
+	return config.OIDCProvider{
+		Issuer:       s.URL,
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  RedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+// SetIdentity changes the user the next authorizations log in
+func (s *Server) SetIdentity(identity Identity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.identity = identity
+}
+
+// Authorize follows a login URL like a browser of a user who approves the login, it returns
+// the state and code the provider redirects back with
+func (s *Server) Authorize(authURL string) (string, string, error) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		return "", "", errors.New("authorization rejected: " + resp.Status)
+	}
+
+	redirect, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+	return redirect.Query().Get("state"), redirect.Query().Get("code"), nil
+}
+
+func (s *Server) discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + jwks.Path,
+	})
+}
+
+func (s *Server) authorize(c *gin.Context) {
+	if c.Query("response_type") != "code" || c.Query("code_challenge_method") != "S256" ||
+		c.Query("code_challenge") == "" || c.Query("state") == "" {
+		c.String(http.StatusBadRequest, "invalid authorization request")
+		return
+	}
+
+	code := randomString()
+
+	s.mu.Lock()
+	s.codes[code] = authorization{
+		identity:      s.identity,
+		clientID:      c.Query("client_id"),
+		redirectURI:   c.Query("redirect_uri"),
+		codeChallenge: c.Query("code_challenge"),
+		nonce:         c.Query("nonce"),
+	}
+	s.mu.Unlock()
+
+	redirect, err := url.Parse(c.Query("redirect_uri"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid redirect_uri")
+		return
+	}
+	query := redirect.Query()
+	query.Set("code", code)
+	query.Set("state", c.Query("state"))
+	redirect.RawQuery = query.Encode()
+
+	c.Redirect(http.StatusFound, redirect.String())
+}
+
+func (s *Server) token(c *gin.Context) {
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		clientID, clientSecret = c.PostForm("client_id"), c.PostForm("client_secret")
+	}
+	if clientID != ClientID || clientSecret != ClientSecret {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+		return
+	}
+
+	// Codes are single use
+	s.mu.Lock()
+	auth, found := s.codes[c.PostForm("code")]
+	delete(s.codes, c.PostForm("code"))
+	modify := s.ModifyIDToken
+	s.mu.Unlock()
+
+	if !found || c.PostForm("grant_type") != "authorization_code" || auth.clientID != clientID ||
+		auth.redirectURI != c.PostForm("redirect_uri") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(c.PostForm("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != auth.codeChallenge {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant", "error_description": "code verifier mismatch"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            auth.identity.Subject,
+		"aud":            clientID,
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          auth.nonce,
+		"email":          auth.identity.Email,
+		"email_verified": auth.identity.EmailVerified,
+		"given_name":     auth.identity.GivenName,
+		"family_name":    auth.identity.FamilyName,
+	}
+	if modify != nil {
+		modify(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(s.key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (s *Server) keySet(c *gin.Context) {
+	key, err := jwks.NewKey(keyID, s.key.Public())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, err := jwks.NewSet([]jwks.Key{key})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, set)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,248 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hthinh24/go-store/internal/pkg/jwks"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+	"golang.org/x/oauth2"
+)
+
+// stateBytes is the entropy of the state and nonce parameters
+const stateBytes = 32
+
+// discoveryPath is where an issuer publishes its metadata (OpenID Connect Discovery 1.0)
+const discoveryPath = "/.well-known/openid-configuration"
+
+// keysRefreshInterval is how long the key set of a provider is cached, unknown kids refetch it
+const keysRefreshInterval = time.Hour
+
+var (
+	ErrMissingIDToken = errors.New("token response has no id_token")
+	ErrNonceMismatch  = errors.New("id token nonce does not match the login")
+	ErrIssuerMismatch = errors.New("id token issuer does not match the provider")
+	ErrInvalidClaims  = errors.New("id token claims are invalid")
+)
+
+// Claims are the ID token claims a login needs
+type Claims struct {
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Picture       string `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+// State is what a started login remembers until the provider redirects back
+type State struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// NewState starts a login with a new state key, nonce and PKCE verifier. Only the key is sent
+// to the provider as the state parameter, the rest stays on the server.
+func NewState(provider string) (string, *State, error) {
+	key, err := randomString()
+	if err != nil {
+		return "", nil, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &State{
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+	}, nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, stateBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider runs the authorization code flow with PKCE against an OpenID Connect provider. The
+// provider metadata is discovered on first use, so an unreachable provider does not stop startup.
+type Provider struct {
+	logger     logger.Logger
+	name       string
+	config     config.OIDCProvider
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	keys     *jwks.Client
+	issuer   string
+	resolved bool
+}
+
+// NewProvider creates a provider, a nil transport uses http.DefaultTransport
+func NewProvider(logger logger.Logger, name string, cfg config.OIDCProvider, transport http.RoundTripper) *Provider {
+	return &Provider{
+		logger: logger,
+		name:   name,
+		config: cfg,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   5 * time.Second,
+		},
+	}
+}
+
+// Name returns the name the provider is configured and stored with
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL returns the provider login page the user is redirected to
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeVerifier string) (string, error) {
+	oauth2Config, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return oauth2Config.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(codeVerifier),
+		oauth2.SetAuthURLParam("nonce", nonce)), nil
+}
+
+// Exchange redeems the authorization code and returns the verified ID token claims
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*Claims, error) {
+	oauth2Config, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauth2Config.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.httpClient), code,
+		oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	idToken, _ := token.Extra("id_token").(string)
+	if idToken == "" {
+		return nil, ErrMissingIDToken
+	}
+
+	return p.verify(idToken, nonce)
+}
+
+// verify checks the signature, issuer, audience, lifetime and nonce of an ID token
+func (p *Provider) verify(idToken string, nonce string) (*Claims, error) {
+	parsed, err := jwt.ParseWithClaims(idToken, &Claims{}, p.keys.Keyfunc(),
+		jwt.WithValidMethods(jwks.ValidMethods()))
+	if err != nil {
+		return nil, fmt.Errorf("verify id token: %w", err)
+	}
+
+	claims, ok := parsed.Claims.(*Claims)
+	if !ok {
+		return nil, jwt.ErrTokenMalformed
+	}
+
+	if !claims.VerifyIssuer(p.issuer, true) {
+		return nil, ErrIssuerMismatch
+	}
+	if !claims.VerifyAudience(p.config.ClientID, true) || claims.Subject == "" {
+		return nil, ErrInvalidClaims
+	}
+	// The nonce binds the token to the login that was started in this browser
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, ErrNonceMismatch
+	}
+
+	return claims, nil
+}
+
+// discover fetches the provider metadata once, a failed fetch is retried on the next call
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resolved {
+		return p.oauth2, nil
+	}
+
+	metadata, err := p.fetchDiscovery(ctx)
+	if err != nil {
+		p.logger.Error("Failed to discover OIDC provider:", p.name, "Error:", err)
+		return nil, fmt.Errorf("discover provider %s: %w", p.name, err)
+	}
+
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Scopes:       p.config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  metadata.AuthorizationEndpoint,
+			TokenURL: metadata.TokenEndpoint,
+		},
+	}
+	p.keys = jwks.NewClient(p.logger, metadata.JWKSURI, keysRefreshInterval, p.httpClient.Transport)
+	p.issuer = metadata.Issuer
+	p.resolved = true
+
+	p.logger.Info("OIDC provider discovered:", p.name)
+	return p.oauth2, nil
+}
+
+func (p *Provider) fetchDiscovery(ctx context.Context) (*discovery, error) {
+	url := strings.TrimSuffix(p.config.Issuer, "/") + discoveryPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var metadata discovery
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, err
+	}
+
+	// The metadata has to belong to the configured issuer, or tokens of another one would pass
+	if metadata.Issuer != p.config.Issuer {
+		return nil, ErrIssuerMismatch
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("provider metadata is missing endpoints")
+	}
+
+	return &metadata, nil
+}
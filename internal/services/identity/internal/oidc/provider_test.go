@@ -0,0 +1,216 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/oidc"
+	"github.com/hthinh24/go-store/services/identity/internal/oidc/oidctest"
+)
+
+// login runs a login against the stub up to the redirect back, returning its state and code
+func login(t *testing.T, stub *oidctest.Server, provider *oidc.Provider) (*oidc.State, string) {
+	t.Helper()
+
+	key, state, err := oidc.NewState(provider.Name())
+	if err != nil {
+		t.Fatalf("NewState: %v", err)
+	}
+
+	authURL, err := provider.AuthCodeURL(context.Background(), key, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	returnedState, code, err := stub.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if returnedState != key {
+		t.Fatalf("state = %q, want %q", returnedState, key)
+	}
+
+	return state, code
+}
+
+func newProvider(stub *oidctest.Server) *oidc.Provider {
+	return oidc.NewProvider(logger.NewAppLogger("development"), "stub", stub.ProviderConfig(), nil)
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	stub := oidctest.NewServer()
+	defer stub.Close()
+
+	provider := newProvider(stub)
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-key", "nonce-value", "verifier")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	query := parsed.Query()
+
+	want := map[string]string{
+		"client_id":             oidctest.ClientID,
+		"redirect_uri":          oidctest.RedirectURL,
+		"response_type":         "code",
+		"state":                 "state-key",
+		"nonce":                 "nonce-value",
+		"code_challenge_method": "S256",
+		"scope":                 "openid email profile",
+	}
+	for param, value := range want {
+		if got := query.Get(param); got != value {
+			t.Errorf("%s = %q, want %q", param, got, value)
+		}
+	}
+	// The verifier itself never leaves the server
+	if query.Get("code_challenge") == "" || query.Get("code_challenge") == "verifier" {
+		t.Errorf("code_challenge = %q, want the S256 challenge", query.Get("code_challenge"))
+	}
+}
+
+func TestExchange(t *testing.T) {
+	stub := oidctest.NewServer()
+	defer stub.Close()
+
+	provider := newProvider(stub)
+	state, code := login(t, stub, provider)
+
+	claims, err := provider.Exchange(context.Background(), code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "stub-subject" || claims.Email != "stub@example.com" || !claims.EmailVerified {
+		t.Errorf("claims = %+v", claims)
+	}
+	if claims.GivenName != "Stub" || claims.FamilyName != "User" {
+		t.Errorf("name = %q %q", claims.GivenName, claims.FamilyName)
+	}
+}
+
+func TestExchangeRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(claims jwt.MapClaims)
+		verifier func(state *oidc.State) string
+		nonce    func(state *oidc.State) string
+		wantErr  error
+	}{
+		{
+			name:    "nonce of another login",
+			nonce:   func(*oidc.State) string { return "another-nonce" },
+			wantErr: oidc.ErrNonceMismatch,
+		},
+		{
+			name:    "missing nonce",
+			modify:  func(claims jwt.MapClaims) { delete(claims, "nonce") },
+			wantErr: oidc.ErrNonceMismatch,
+		},
+		{
+			name:     "wrong code verifier",
+			verifier: func(*oidc.State) string { return "wrong-verifier-wrong-verifier-wrong-verifier" },
+		},
+		{
+			name:    "other audience",
+			modify:  func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+			wantErr: oidc.ErrInvalidClaims,
+		},
+		{
+			name:    "other issuer",
+			modify:  func(claims jwt.MapClaims) { claims["iss"] = "https://issuer.example.com" },
+			wantErr: oidc.ErrIssuerMismatch,
+		},
+		{
+			name:    "missing subject",
+			modify:  func(claims jwt.MapClaims) { delete(claims, "sub") },
+			wantErr: oidc.ErrInvalidClaims,
+		},
+		{
+			name:   "expired",
+			modify: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Minute).Unix() },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := oidctest.NewServer()
+			defer stub.Close()
+			stub.ModifyIDToken = tt.modify
+
+			provider := newProvider(stub)
+			state, code := login(t, stub, provider)
+
+			verifier, nonce := state.CodeVerifier, state.Nonce
+			if tt.verifier != nil {
+				verifier = tt.verifier(state)
+			}
+			if tt.nonce != nil {
+				nonce = tt.nonce(state)
+			}
+
+			claims, err := provider.Exchange(context.Background(), code, verifier, nonce)
+			if err == nil {
+				t.Fatalf("Exchange = %+v, want an error", claims)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Exchange error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExchangeCodeIsSingleUse(t *testing.T) {
+	stub := oidctest.NewServer()
+	defer stub.Close()
+
+	provider := newProvider(stub)
+	state, code := login(t, stub, provider)
+
+	if _, err := provider.Exchange(context.Background(), code, state.CodeVerifier, state.Nonce); err != nil {
+		t.Fatalf("first Exchange: %v", err)
+	}
+	if _, err := provider.Exchange(context.Background(), code, state.CodeVerifier, state.Nonce); err == nil {
+		t.Fatal("second Exchange succeeded, want an error")
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	stub := oidctest.NewServer()
+	defer stub.Close()
+
+	cfg := stub.ProviderConfig()
+	cfg.Issuer = stub.URL + "/"
+	provider := oidc.NewProvider(logger.NewAppLogger("development"), "stub", cfg, nil)
+
+	if _, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); !errors.Is(err, oidc.ErrIssuerMismatch) {
+		t.Errorf("AuthCodeURL error = %v, want %v", err, oidc.ErrIssuerMismatch)
+	}
+}
+
+func TestNewState(t *testing.T) {
+	key, state, err := oidc.NewState("stub")
+	if err != nil {
+		t.Fatalf("NewState: %v", err)
+	}
+
+	other, otherState, err := oidc.NewState("stub")
+	if err != nil {
+		t.Fatalf("NewState: %v", err)
+	}
+
+	if key == other || state.Nonce == otherState.Nonce || state.CodeVerifier == otherState.CodeVerifier {
+		t.Error("NewState returned the same values twice")
+	}
+	if state.Provider != "stub" || state.Nonce == key {
+		t.Errorf("state = %+v", state)
+	}
+}
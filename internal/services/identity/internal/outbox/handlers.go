@@ -0,0 +1,23 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hthinh24/go-store/services/identity/internal/controller/http/client"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+)
+
+// CreateCart creates the cart of a registered user, the cart service answers a repeated
+// registration with the existing cart
+func CreateCart(cartClient client.CartClient) Handler {
+	return func(ctx context.Context, event *entity.OutboxEvent) error {
+		var payload entity.UserRegisteredPayload
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			return fmt.Errorf("invalid %s payload: %w", event.EventType, err)
+		}
+
+		return cartClient.CreateCart(ctx, payload.UserID)
+	}
+}
@@ -0,0 +1,100 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+)
+
+// Handler delivers an event, it must be idempotent as an event can be delivered more than once
+type Handler func(ctx context.Context, event *entity.OutboxEvent) error
+
+// Relay delivers the due events of the outbox and retries failed deliveries with backoff
+type Relay struct {
+	logger     logger.Logger
+	repository identity.OutboxRepository
+	handlers   map[entity.OutboxEventType]Handler
+	config     config.Outbox
+}
+
+func NewRelay(logger logger.Logger, repository identity.OutboxRepository, cfg config.Outbox) *Relay {
+	return &Relay{
+		logger:     logger,
+		repository: repository,
+		handlers:   make(map[entity.OutboxEventType]Handler),
+		config:     cfg,
+	}
+}
+
+// Handle registers the handler of an event type, it must be called before Run
+func (r *Relay) Handle(eventType entity.OutboxEventType, handler Handler) {
+	r.handlers[eventType] = handler
+}
+
+// Run delivers events until the context is canceled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.GetPollInterval())
+	defer ticker.Stop()
+
+	for {
+		r.relayDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayDue delivers batches until no more events are due
+func (r *Relay) relayDue(ctx context.Context) {
+	// Batches are delivered one event after another, the lease has to cover a whole batch
+	lease := r.config.GetDeliveryTimeout() * time.Duration(r.config.BatchSize)
+
+	for ctx.Err() == nil {
+		events, err := r.repository.ClaimDueEvents(r.config.BatchSize, lease)
+		if err != nil {
+			return
+		}
+
+		for i := range *events {
+			r.deliver(ctx, &(*events)[i])
+		}
+		if len(*events) < r.config.BatchSize {
+			return
+		}
+	}
+}
+
+func (r *Relay) deliver(ctx context.Context, event *entity.OutboxEvent) {
+	handler, ok := r.handlers[event.EventType]
+	if !ok {
+		// Nothing consumes the event in this setup, e.g. the cart service is not configured
+		r.logger.Warn("No handler for outbox event type: ", event.EventType, ", event ID: ", event.ID)
+		r.repository.MarkEventProcessed(event.ID)
+		return
+	}
+
+	deliveryCtx, cancel := context.WithTimeout(ctx, r.config.GetDeliveryTimeout())
+	defer cancel()
+
+	if err := handler(deliveryCtx, event); err != nil {
+		backoff := r.config.GetBackoff(event.Attempts)
+		r.logger.Warn("Outbox event delivery failed, event ID: ", event.ID, ", type: ", event.EventType,
+			", attempt: ", event.Attempts, ", retry in: ", backoff, ", error: ", err)
+		r.repository.MarkEventFailed(event.ID, time.Now().Add(backoff), fmt.Sprint(err))
+		return
+	}
+
+	if err := r.repository.MarkEventProcessed(event.ID); err != nil {
+		// The lease runs out and the event is delivered again, handlers are idempotent
+		return
+	}
+	r.logger.Info("Outbox event delivered, event ID: ", event.ID, ", type: ", event.EventType)
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"gorm.io/gorm"
+)
+
+type accountTokenRepository struct {
+	logger logger.Logger
+	db     *gorm.DB
+}
+
+func NewAccountTokenRepository(logger logger.Logger, db *gorm.DB) *accountTokenRepository {
+	return &accountTokenRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (a *accountTokenRepository) CreateAccountToken(token *entity.AccountToken) error {
+	a.logger.Info("Creating account token:", token.Purpose, "for user ID:", token.UserID)
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entity.AccountToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			a.logger.Error("Failed to invalidate account tokens of user ID:", token.UserID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "invalidate account tokens"}
+		}
+
+		if err := tx.Create(token).Error; err != nil {
+			a.logger.Error("Failed to create account token for user ID:", token.UserID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "create account token"}
+		}
+
+		return nil
+	})
+}
+
+func (a *accountTokenRepository) UseAccountToken(tokenHash string, purpose entity.AccountTokenPurpose) (*entity.AccountToken, error) {
+	var token entity.AccountToken
+	if err := a.db.Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identityErrors.ErrInvalidAccountToken{}
+		}
+		a.logger.Error("Failed to find account token, error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find account token"}
+	}
+
+	// Only the first of two concurrent uses may mark the token as used
+	now := time.Now()
+	result := a.db.Model(&entity.AccountToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		a.logger.Error("Failed to use account token ID:", token.ID, "Error:", result.Error)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "use account token"}
+	}
+	if result.RowsAffected == 0 {
+		return nil, identityErrors.ErrInvalidAccountToken{}
+	}
+
+	token.UsedAt = &now
+	return &token, nil
+}
+
+func (a *accountTokenRepository) InvalidateAccountTokens(userID int64, purposes ...entity.AccountTokenPurpose) error {
+	a.logger.Info("Invalidating account tokens:", purposes, "of user ID:", userID)
+
+	err := a.db.Model(&entity.AccountToken{}).
+		Where("user_id = ? AND purpose IN ? AND used_at IS NULL", userID, purposes).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		a.logger.Error("Failed to invalidate account tokens of user ID:", userID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "invalidate account tokens"}
+	}
+
+	return nil
+}
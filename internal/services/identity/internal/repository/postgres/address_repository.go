@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type addressRepository struct {
+	logger logger.Logger
+	db     *gorm.DB
+}
+
+func NewAddressRepository(logger logger.Logger, db *gorm.DB) *addressRepository {
+	return &addressRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (a *addressRepository) FindAddressesByUserID(userID int64) (*[]entity.UserAddress, error) {
+	var addresses []entity.UserAddress
+	if err := a.db.Where("user_id = ?", userID).Order("id ASC").Find(&addresses).Error; err != nil {
+		a.logger.Error("Failed to find addresses of user ID:", userID, "Error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find addresses"}
+	}
+
+	return &addresses, nil
+}
+
+func (a *addressRepository) FindAddressByID(userID int64, id int64) (*entity.UserAddress, error) {
+	var address entity.UserAddress
+	if err := a.db.Where("id = ? AND user_id = ?", id, userID).First(&address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identityErrors.ErrAddressNotFound{}
+		}
+		a.logger.Error("Failed to find address ID:", id, "Error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find address"}
+	}
+
+	return &address, nil
+}
+
+func (a *addressRepository) CreateAddress(address *entity.UserAddress, maxPerUser int) error {
+	a.logger.Info("Creating address for user ID:", address.UserID)
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := a.lockAddressBook(tx, address.UserID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&entity.UserAddress{}).Where("user_id = ?", address.UserID).Count(&count).Error; err != nil {
+			a.logger.Error("Failed to count addresses of user ID:", address.UserID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "count addresses"}
+		}
+		if count >= int64(maxPerUser) {
+			return identityErrors.ErrAddressLimitReached{Limit: maxPerUser}
+		}
+		if count == 0 {
+			address.IsDefaultShipping = true
+			address.IsDefaultBilling = true
+		}
+
+		if err := a.clearDefaults(tx, address); err != nil {
+			return err
+		}
+		if err := tx.Create(address).Error; err != nil {
+			a.logger.Error("Failed to create address for user ID:", address.UserID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "create address"}
+		}
+
+		return nil
+	})
+}
+
+func (a *addressRepository) UpdateAddress(address *entity.UserAddress) error {
+	a.logger.Info("Updating address ID:", address.ID, "of user ID:", address.UserID)
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := a.lockAddressBook(tx, address.UserID); err != nil {
+			return err
+		}
+		if err := a.clearDefaults(tx, address); err != nil {
+			return err
+		}
+
+		result := tx.Model(address).
+			Where("user_id = ?", address.UserID).
+			Select("full_name", "phone", "address_line", "city", "state", "postal_code", "country",
+				"is_default_shipping", "is_default_billing", "updated_at").
+			Updates(address)
+		if result.Error != nil {
+			a.logger.Error("Failed to update address ID:", address.ID, "Error:", result.Error)
+			return identityErrors.ErrDatabaseTransaction{Operation: "update address"}
+		}
+		if result.RowsAffected == 0 {
+			return identityErrors.ErrAddressNotFound{}
+		}
+
+		return nil
+	})
+}
+
+func (a *addressRepository) DeleteAddress(address *entity.UserAddress) error {
+	a.logger.Info("Deleting address ID:", address.ID, "of user ID:", address.UserID)
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := a.lockAddressBook(tx, address.UserID); err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND user_id = ?", address.ID, address.UserID).Delete(&entity.UserAddress{})
+		if result.Error != nil {
+			a.logger.Error("Failed to delete address ID:", address.ID, "Error:", result.Error)
+			return identityErrors.ErrDatabaseTransaction{Operation: "delete address"}
+		}
+		if result.RowsAffected == 0 {
+			return identityErrors.ErrAddressNotFound{}
+		}
+
+		// Checkout relies on a default address as long as the user has any
+		for column, wasDefault := range map[string]bool{
+			"is_default_shipping": address.IsDefaultShipping,
+			"is_default_billing":  address.IsDefaultBilling,
+		} {
+			if !wasDefault {
+				continue
+			}
+			err := tx.Model(&entity.UserAddress{}).
+				Where("id = (?)", tx.Model(&entity.UserAddress{}).Select("MAX(id)").Where("user_id = ?", address.UserID)).
+				Update(column, true).Error
+			if err != nil {
+				a.logger.Error("Failed to move", column, "of user ID:", address.UserID, "Error:", err)
+				return identityErrors.ErrDatabaseTransaction{Operation: "update default address"}
+			}
+		}
+
+		return nil
+	})
+}
+
+// lockAddressBook locks the user row, so concurrent changes cannot exceed the limit or end up
+// with two default addresses
+func (a *addressRepository) lockAddressBook(tx *gorm.DB, userID int64) error {
+	var user entity.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return identityErrors.ErrUserNotFound{}
+		}
+		a.logger.Error("Failed to lock address book of user ID:", userID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "lock address book"}
+	}
+
+	return nil
+}
+
+// clearDefaults takes the default flags set on the address from the other addresses of the user
+func (a *addressRepository) clearDefaults(tx *gorm.DB, address *entity.UserAddress) error {
+	for column, isDefault := range map[string]bool{
+		"is_default_shipping": address.IsDefaultShipping,
+		"is_default_billing":  address.IsDefaultBilling,
+	} {
+		if !isDefault {
+			continue
+		}
+		err := tx.Model(&entity.UserAddress{}).
+			Where("user_id = ? AND id <> ? AND "+column, address.UserID, address.ID).
+			Update(column, false).Error
+		if err != nil {
+			a.logger.Error("Failed to clear", column, "of user ID:", address.UserID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "clear default address"}
+		}
+	}
+
+	return nil
+}
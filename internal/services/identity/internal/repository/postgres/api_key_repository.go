@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	logger logger.Logger
+	db     *gorm.DB
+}
+
+func NewAPIKeyRepository(logger logger.Logger, db *gorm.DB) *apiKeyRepository {
+	return &apiKeyRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (a *apiKeyRepository) CreateAPIKey(key *entity.APIKey) error {
+	a.logger.Info("Creating API key for user ID:", key.UserID)
+
+	if err := a.db.Create(key).Error; err != nil {
+		a.logger.Error("Failed to create API key for user ID:", key.UserID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "create API key"}
+	}
+
+	return nil
+}
+
+func (a *apiKeyRepository) FindAPIKeysByUserID(userID int64) (*[]entity.APIKey, error) {
+	var keys []entity.APIKey
+	if err := a.db.Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error; err != nil {
+		a.logger.Error("Failed to find API keys of user ID:", userID, "Error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find API keys"}
+	}
+
+	return &keys, nil
+}
+
+func (a *apiKeyRepository) FindAPIKeyByID(userID int64, id int64) (*entity.APIKey, error) {
+	var key entity.APIKey
+	if err := a.db.Where("id = ? AND user_id = ?", id, userID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identityErrors.ErrAPIKeyNotFound{}
+		}
+		a.logger.Error("Failed to find API key ID:", id, "Error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find API key"}
+	}
+
+	return &key, nil
+}
+
+func (a *apiKeyRepository) FindAPIKeyByHash(keyHash string) (*entity.APIKey, error) {
+	var key entity.APIKey
+	if err := a.db.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identityErrors.ErrAPIKeyNotFound{}
+		}
+		a.logger.Error("Failed to find API key, error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find API key"}
+	}
+
+	return &key, nil
+}
+
+func (a *apiKeyRepository) RevokeAPIKey(key *entity.APIKey) error {
+	a.logger.Info("Revoking API key ID:", key.ID, "of user ID:", key.UserID)
+
+	now := time.Now()
+	result := a.db.Model(&entity.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", key.ID).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		a.logger.Error("Failed to revoke API key ID:", key.ID, "Error:", result.Error)
+		return identityErrors.ErrDatabaseTransaction{Operation: "revoke API key"}
+	}
+	if result.RowsAffected > 0 {
+		key.RevokedAt = &now
+	}
+
+	return nil
+}
+
+func (a *apiKeyRepository) UpdateAPIKeyLastUsed(id int64, usedAt time.Time) error {
+	err := a.db.Model(&entity.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+	if err != nil {
+		a.logger.Error("Failed to update last use of API key ID:", id, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "update API key last use"}
+	}
+
+	return nil
+}
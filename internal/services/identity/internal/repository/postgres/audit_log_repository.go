@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"gorm.io/gorm"
+)
+
+type auditLogRepository struct {
+	logger logger.Logger
+	db     *gorm.DB
+}
+
+func NewAuditLogRepository(logger logger.Logger, db *gorm.DB) *auditLogRepository {
+	return &auditLogRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (a *auditLogRepository) CreateAuditLog(auditLog *entity.AuditLog) error {
+	a.logger.Info("Creating audit log:", auditLog.Event)
+
+	if err := a.db.Create(auditLog).Error; err != nil {
+		a.logger.Error("Failed to create audit log:", auditLog.Event, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "create audit log"}
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/hthinh24/go-store/services/identity/internal/entity"
 	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type authRepository struct {
@@ -108,6 +109,9 @@ func (a *authRepository) FindPermissionByName(name string) (*entity.Permission,
 
 	var permission entity.Permission
 	if err := a.db.Where("name = ?", name).First(&permission).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identityErrors.ErrPermissionNotFound{Name: name}
+		}
 		a.logger.Error("Failed to find permission by name:", name, "Error:", err)
 		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find permission"}
 	}
@@ -118,7 +122,7 @@ func (a *authRepository) FindPermissionByName(name string) (*entity.Permission,
 func (u *authRepository) AddRoleToUser(userRole *entity.UserRoles) error {
 	u.logger.Info("Adding role to user with ID: %d", userRole.UserID)
 
-	if err := u.db.Create(&userRole).Error; err != nil {
+	if err := u.db.Clauses(clause.OnConflict{DoNothing: true}).Create(userRole).Error; err != nil {
 		u.logger.Error("Error adding role to user with ID %d: %v", userRole.UserID, err)
 		return identityErrors.ErrDatabaseTransaction{Operation: "add role to user"}
 	}
@@ -126,3 +130,157 @@ func (u *authRepository) AddRoleToUser(userRole *entity.UserRoles) error {
 	u.logger.Info("Role added to user with ID %d successfully", userRole.UserID)
 	return nil
 }
+
+func (a *authRepository) UpdateRoleMFARequired(name string, required bool) error {
+	a.logger.Info("Updating MFA requirement of role:", name, "to:", required)
+
+	result := a.db.Model(&entity.Role{}).Where("name = ?", name).Update("mfa_required", required)
+	if result.Error != nil {
+		a.logger.Error("Failed to update MFA requirement of role:", name, "Error:", result.Error)
+		return identityErrors.ErrDatabaseTransaction{Operation: "update role mfa requirement"}
+	}
+	if result.RowsAffected == 0 {
+		return identityErrors.ErrRoleNotFound{Name: name}
+	}
+
+	return nil
+}
+
+func (a *authRepository) FindRoles() (*[]entity.Role, error) {
+	a.logger.Info("Finding all roles")
+
+	var roles []entity.Role
+	if err := a.db.Order("id").Find(&roles).Error; err != nil {
+		a.logger.Error("Failed to find roles, error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find roles"}
+	}
+
+	return &roles, nil
+}
+
+func (a *authRepository) CreateRole(role *entity.Role) error {
+	a.logger.Info("Creating role:", role.Name)
+
+	if err := a.db.Create(role).Error; err != nil {
+		a.logger.Error("Failed to create role:", role.Name, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "create role"}
+	}
+
+	return nil
+}
+
+func (a *authRepository) UpdateRole(role *entity.Role) error {
+	a.logger.Info("Updating role:", role.Name)
+
+	if err := a.db.Model(role).Select("description").Updates(role).Error; err != nil {
+		a.logger.Error("Failed to update role:", role.Name, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "update role"}
+	}
+
+	return nil
+}
+
+func (a *authRepository) DeleteRole(id int64) error {
+	a.logger.Info("Deleting role ID:", id)
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&entity.UserRoles{}).Error; err != nil {
+			a.logger.Error("Failed to remove role ID:", id, "from users, error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "remove role from users"}
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&entity.RolePermissions{}).Error; err != nil {
+			a.logger.Error("Failed to remove permissions of role ID:", id, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "remove role permissions"}
+		}
+		if err := tx.Delete(&entity.Role{}, id).Error; err != nil {
+			a.logger.Error("Failed to delete role ID:", id, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "delete role"}
+		}
+
+		return nil
+	})
+}
+
+func (a *authRepository) FindPermissions() (*[]entity.Permission, error) {
+	a.logger.Info("Finding all permissions")
+
+	var permissions []entity.Permission
+	if err := a.db.Order("id").Find(&permissions).Error; err != nil {
+		a.logger.Error("Failed to find permissions, error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find permissions"}
+	}
+
+	return &permissions, nil
+}
+
+func (a *authRepository) CreatePermission(permission *entity.Permission) error {
+	a.logger.Info("Creating permission:", permission.Name)
+
+	if err := a.db.Create(permission).Error; err != nil {
+		a.logger.Error("Failed to create permission:", permission.Name, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "create permission"}
+	}
+
+	return nil
+}
+
+func (a *authRepository) UpdatePermission(permission *entity.Permission) error {
+	a.logger.Info("Updating permission:", permission.Name)
+
+	if err := a.db.Model(permission).Select("description").Updates(permission).Error; err != nil {
+		a.logger.Error("Failed to update permission:", permission.Name, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "update permission"}
+	}
+
+	return nil
+}
+
+func (a *authRepository) DeletePermission(id int64) error {
+	a.logger.Info("Deleting permission ID:", id)
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("permission_id = ?", id).Delete(&entity.RolePermissions{}).Error; err != nil {
+			a.logger.Error("Failed to remove permission ID:", id, "from roles, error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "remove permission from roles"}
+		}
+		if err := tx.Delete(&entity.Permission{}, id).Error; err != nil {
+			a.logger.Error("Failed to delete permission ID:", id, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "delete permission"}
+		}
+
+		return nil
+	})
+}
+
+func (a *authRepository) RemoveRoleFromUser(userID int64, roleID int64) error {
+	a.logger.Info("Removing role ID:", roleID, "from user ID:", userID)
+
+	if err := a.db.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&entity.UserRoles{}).Error; err != nil {
+		a.logger.Error("Failed to remove role ID:", roleID, "from user ID:", userID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "remove role from user"}
+	}
+
+	return nil
+}
+
+func (a *authRepository) AddPermissionToRole(rolePermission *entity.RolePermissions) error {
+	a.logger.Info("Adding permission ID:", rolePermission.PermissionID, "to role ID:", rolePermission.RoleID)
+
+	if err := a.db.Clauses(clause.OnConflict{DoNothing: true}).Create(rolePermission).Error; err != nil {
+		a.logger.Error("Failed to add permission to role ID:", rolePermission.RoleID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "add permission to role"}
+	}
+
+	return nil
+}
+
+func (a *authRepository) RemovePermissionFromRole(roleID int64, permissionID int64) error {
+	a.logger.Info("Removing permission ID:", permissionID, "from role ID:", roleID)
+
+	if err := a.db.Where("role_id = ? AND permission_id = ?", roleID, permissionID).Delete(&entity.RolePermissions{}).Error; err != nil {
+		a.logger.Error("Failed to remove permission from role ID:", roleID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "remove permission from role"}
+	}
+
+	return nil
+}
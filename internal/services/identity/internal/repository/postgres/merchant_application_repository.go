@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type merchantApplicationRepository struct {
+	logger logger.Logger
+	db     *gorm.DB
+}
+
+func NewMerchantApplicationRepository(logger logger.Logger, db *gorm.DB) *merchantApplicationRepository {
+	return &merchantApplicationRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (m *merchantApplicationRepository) CreateMerchantApplication(application *entity.MerchantApplication, auditLog *entity.AuditLog) error {
+	m.logger.Info("Creating merchant application for user ID:", application.UserID)
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(application).Error; err != nil {
+			m.logger.Error("Failed to create merchant application for user ID:", application.UserID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "create merchant application"}
+		}
+
+		return createApplicationAuditLog(tx, m.logger, application, auditLog)
+	})
+}
+
+func (m *merchantApplicationRepository) FindMerchantApplicationByID(id int64) (*entity.MerchantApplication, error) {
+	var application entity.MerchantApplication
+	if err := m.db.First(&application, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identityErrors.ErrMerchantApplicationNotFound{}
+		}
+		m.logger.Error("Failed to find merchant application ID:", id, "Error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find merchant application"}
+	}
+
+	return &application, nil
+}
+
+func (m *merchantApplicationRepository) FindLatestMerchantApplicationByUserID(userID int64) (*entity.MerchantApplication, error) {
+	var application entity.MerchantApplication
+	if err := m.db.Where("user_id = ?", userID).Order("id DESC").First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identityErrors.ErrMerchantApplicationNotFound{}
+		}
+		m.logger.Error("Failed to find merchant application of user ID:", userID, "Error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find merchant application"}
+	}
+
+	return &application, nil
+}
+
+func (m *merchantApplicationRepository) FindMerchantApplications(status constants.MerchantApplicationStatus, limit int, offset int) (*[]entity.MerchantApplication, int64, error) {
+	query := m.db.Model(&entity.MerchantApplication{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		m.logger.Error("Failed to count merchant applications, Error:", err)
+		return nil, 0, identityErrors.ErrDatabaseTransaction{Operation: "count merchant applications"}
+	}
+
+	// Oldest first, applications are reviewed in the order they came in
+	var applications []entity.MerchantApplication
+	if err := query.Order("id ASC").Limit(limit).Offset(offset).Find(&applications).Error; err != nil {
+		m.logger.Error("Failed to find merchant applications, Error:", err)
+		return nil, 0, identityErrors.ErrDatabaseTransaction{Operation: "find merchant applications"}
+	}
+
+	return &applications, total, nil
+}
+
+func (m *merchantApplicationRepository) UpdateMerchantApplicationStatus(application *entity.MerchantApplication, from constants.MerchantApplicationStatus, auditLog *entity.AuditLog) error {
+	m.logger.Info("Moving merchant application ID:", application.ID, "from:", from, "to:", application.Status)
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateApplicationStatus(tx, m.logger, application, from); err != nil {
+			return err
+		}
+
+		return createApplicationAuditLog(tx, m.logger, application, auditLog)
+	})
+}
+
+func (m *merchantApplicationRepository) ApproveMerchantApplication(application *entity.MerchantApplication, from constants.MerchantApplicationStatus, merchantRoleID int64, auditLog *entity.AuditLog) error {
+	m.logger.Info("Approving merchant application ID:", application.ID)
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateApplicationStatus(tx, m.logger, application, from); err != nil {
+			return err
+		}
+
+		userRole := entity.UserRoles{UserID: application.UserID, RoleID: merchantRoleID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&userRole).Error; err != nil {
+			m.logger.Error("Failed to grant merchant role to user ID:", application.UserID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "grant merchant role"}
+		}
+
+		return createApplicationAuditLog(tx, m.logger, application, auditLog)
+	})
+}
+
+func updateApplicationStatus(db *gorm.DB, logger logger.Logger, application *entity.MerchantApplication, from constants.MerchantApplicationStatus) error {
+	result := db.Model(application).
+		Where("status = ?", from).
+		Select("status", "reviewer_id", "decision_reason", "decided_at", "updated_at").
+		Updates(application)
+	if result.Error != nil {
+		logger.Error("Failed to update merchant application ID:", application.ID, "Error:", result.Error)
+		return identityErrors.ErrDatabaseTransaction{Operation: "update merchant application"}
+	}
+	if result.RowsAffected == 0 {
+		return identityErrors.ErrInvalidApplicationTransition{From: string(from), To: string(application.Status)}
+	}
+
+	return nil
+}
+
+// createApplicationAuditLog writes the audit log of a status change, a failure rolls the change back
+func createApplicationAuditLog(tx *gorm.DB, logger logger.Logger, application *entity.MerchantApplication, auditLog *entity.AuditLog) error {
+	auditLog.Details = application.AuditDetails()
+	if err := tx.Create(auditLog).Error; err != nil {
+		logger.Error("Failed to create audit log:", auditLog.Event, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "create audit log"}
+	}
+
+	return nil
+}
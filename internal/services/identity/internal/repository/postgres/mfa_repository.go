@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mfaRepository struct {
+	logger logger.Logger
+	db     *gorm.DB
+}
+
+func NewMFARepository(logger logger.Logger, db *gorm.DB) *mfaRepository {
+	return &mfaRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (m *mfaRepository) FindUserMFA(userID int64) (*entity.UserMFA, error) {
+	var userMFA entity.UserMFA
+	if err := m.db.Where("user_id = ?", userID).First(&userMFA).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identityErrors.ErrMFANotEnrolled{}
+		}
+		m.logger.Error("Failed to find MFA of user ID:", userID, "Error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find user mfa"}
+	}
+
+	return &userMFA, nil
+}
+
+func (m *mfaRepository) SaveUserMFA(userMFA *entity.UserMFA) error {
+	m.logger.Info("Saving MFA enrollment of user ID:", userMFA.UserID)
+
+	// A confirmed authenticator is never replaced, it has to be disabled first
+	result := m.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "last_used_step", "created_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_mfa.confirmed_at IS NULL"}}},
+	}).Create(userMFA)
+	if result.Error != nil {
+		m.logger.Error("Failed to save MFA enrollment of user ID:", userMFA.UserID, "Error:", result.Error)
+		return identityErrors.ErrDatabaseTransaction{Operation: "save user mfa"}
+	}
+	if result.RowsAffected == 0 {
+		return identityErrors.ErrMFAAlreadyEnabled{}
+	}
+
+	return nil
+}
+
+func (m *mfaRepository) ConfirmUserMFA(userID int64, step int64, recoveryCodeHashes []string) error {
+	m.logger.Info("Confirming MFA of user ID:", userID)
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.UserMFA{}).
+			Where("user_id = ? AND confirmed_at IS NULL AND last_used_step < ?", userID, step).
+			Updates(map[string]interface{}{"confirmed_at": time.Now(), "last_used_step": step})
+		if result.Error != nil {
+			m.logger.Error("Failed to confirm MFA of user ID:", userID, "Error:", result.Error)
+			return identityErrors.ErrDatabaseTransaction{Operation: "confirm user mfa"}
+		}
+		if result.RowsAffected == 0 {
+			return identityErrors.ErrInvalidMFACode{}
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.MFARecoveryCode{}).Error; err != nil {
+			m.logger.Error("Failed to delete recovery codes of user ID:", userID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "delete recovery codes"}
+		}
+
+		codes := make([]entity.MFARecoveryCode, len(recoveryCodeHashes))
+		for i, hash := range recoveryCodeHashes {
+			codes[i] = entity.MFARecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			m.logger.Error("Failed to create recovery codes of user ID:", userID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "create recovery codes"}
+		}
+
+		return nil
+	})
+}
+
+func (m *mfaRepository) UseTOTPStep(userID int64, step int64) error {
+	// Only the first of two concurrent uses of a code moves the step forward
+	result := m.db.Model(&entity.UserMFA{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		m.logger.Error("Failed to use TOTP step of user ID:", userID, "Error:", result.Error)
+		return identityErrors.ErrDatabaseTransaction{Operation: "use totp step"}
+	}
+	if result.RowsAffected == 0 {
+		return identityErrors.ErrInvalidMFACode{}
+	}
+
+	return nil
+}
+
+func (m *mfaRepository) UseRecoveryCode(userID int64, codeHash string) error {
+	result := m.db.Model(&entity.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		m.logger.Error("Failed to use recovery code of user ID:", userID, "Error:", result.Error)
+		return identityErrors.ErrDatabaseTransaction{Operation: "use recovery code"}
+	}
+	if result.RowsAffected == 0 {
+		return identityErrors.ErrInvalidMFACode{}
+	}
+
+	m.logger.Info("Recovery code used by user ID:", userID)
+	return nil
+}
+
+func (m *mfaRepository) DeleteUserMFA(userID int64) error {
+	m.logger.Info("Deleting MFA of user ID:", userID)
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.MFARecoveryCode{}).Error; err != nil {
+			m.logger.Error("Failed to delete recovery codes of user ID:", userID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "delete recovery codes"}
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.UserMFA{}).Error; err != nil {
+			m.logger.Error("Failed to delete MFA of user ID:", userID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "delete user mfa"}
+		}
+
+		return nil
+	})
+}
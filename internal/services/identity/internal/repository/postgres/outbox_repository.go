@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"gorm.io/gorm"
+)
+
+// maxLastErrorLength is the size of the last_error column
+const maxLastErrorLength = 512
+
+type outboxRepository struct {
+	logger logger.Logger
+	db     *gorm.DB
+}
+
+func NewOutboxRepository(logger logger.Logger, db *gorm.DB) *outboxRepository {
+	return &outboxRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (o *outboxRepository) ClaimDueEvents(limit int, lease time.Duration) (*[]entity.OutboxEvent, error) {
+	now := time.Now()
+
+	// SKIP LOCKED lets relays of several instances claim disjoint batches
+	var events []entity.OutboxEvent
+	err := o.db.Raw(`UPDATE outbox_events SET next_attempt_at = ?, attempts = attempts + 1
+		WHERE id IN (SELECT id FROM outbox_events WHERE processed_at IS NULL AND next_attempt_at <= ?
+			ORDER BY next_attempt_at, id LIMIT ? FOR UPDATE SKIP LOCKED)
+		RETURNING *`, now.Add(lease), now, limit).Scan(&events).Error
+	if err != nil {
+		o.logger.Error("Failed to claim outbox events, Error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "claim outbox events"}
+	}
+
+	return &events, nil
+}
+
+func (o *outboxRepository) MarkEventProcessed(id int64) error {
+	err := o.db.Model(&entity.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"processed_at": time.Now(), "last_error": ""}).Error
+	if err != nil {
+		o.logger.Error("Failed to mark outbox event processed, ID:", id, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "mark outbox event processed"}
+	}
+
+	return nil
+}
+
+func (o *outboxRepository) MarkEventFailed(id int64, nextAttemptAt time.Time, lastError string) error {
+	if len(lastError) > maxLastErrorLength {
+		lastError = lastError[:maxLastErrorLength]
+	}
+
+	err := o.db.Model(&entity.OutboxEvent{}).
+		Where("id = ? AND processed_at IS NULL", id).
+		Updates(map[string]interface{}{"next_attempt_at": nextAttemptAt, "last_error": lastError}).Error
+	if err != nil {
+		o.logger.Error("Failed to mark outbox event failed, ID:", id, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "mark outbox event failed"}
+	}
+
+	return nil
+}
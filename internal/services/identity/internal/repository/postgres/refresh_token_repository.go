@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"gorm.io/gorm"
+)
+
+type refreshTokenRepository struct {
+	logger logger.Logger
+	db     *gorm.DB
+}
+
+func NewRefreshTokenRepository(logger logger.Logger, db *gorm.DB) *refreshTokenRepository {
+	return &refreshTokenRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(token *entity.RefreshToken) error {
+	r.logger.Info("Creating refresh token for user ID:", token.UserID)
+
+	if err := r.db.Create(token).Error; err != nil {
+		r.logger.Error("Failed to create refresh token for user ID:", token.UserID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "create refresh token"}
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepository) FindRefreshTokenByHash(tokenHash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identityErrors.ErrInvalidRefreshToken{}
+		}
+		r.logger.Error("Failed to find refresh token, error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find refresh token"}
+	}
+
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) RotateRefreshToken(current *entity.RefreshToken, next *entity.RefreshToken) error {
+	r.logger.Info("Rotating refresh token for user ID:", current.UserID)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Only the first of two concurrent rotations may revoke the token
+		result := tx.Model(&entity.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			r.logger.Error("Failed to revoke refresh token ID:", current.ID, "Error:", result.Error)
+			return identityErrors.ErrDatabaseTransaction{Operation: "revoke refresh token"}
+		}
+		if result.RowsAffected == 0 {
+			return identityErrors.ErrRefreshTokenReused{}
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			r.logger.Error("Failed to create refresh token for user ID:", next.UserID, "Error:", err)
+			return identityErrors.ErrDatabaseTransaction{Operation: "create refresh token"}
+		}
+
+		return nil
+	})
+}
+
+func (r *refreshTokenRepository) RevokeAllUserRefreshTokens(userID int64) error {
+	r.logger.Info("Revoking all refresh tokens of user ID:", userID)
+
+	err := r.db.Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("Failed to revoke refresh tokens of user ID:", userID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "revoke user refresh tokens"}
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeRefreshTokenFamily(familyID string) error {
+	r.logger.Info("Revoking refresh token family:", familyID)
+
+	err := r.db.Model(&entity.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("Failed to revoke refresh token family:", familyID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "revoke refresh token family"}
+	}
+
+	return nil
+}
@@ -1,7 +1,12 @@
 package postgres
 
 import (
+	stdErrors "errors"
+	"strings"
+	"time"
+
 	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity"
 	"github.com/hthinh24/go-store/services/identity/internal/entity"
 	"github.com/hthinh24/go-store/services/identity/internal/errors"
 	"gorm.io/gorm"
@@ -45,19 +50,96 @@ func (u *userRepository) FindUserByEmail(email string) (*entity.User, error) {
 	return &user, nil
 }
 
-func (u *userRepository) FindUsers() (*[]entity.User, error) {
-	// TODO Pagination and filtering can be added later
+func (u *userRepository) FindUserByProvider(providerName string, providerID string) (*entity.User, error) {
+	u.Logger.Info("Fetching user with provider: %s", providerName)
+
+	var user entity.User
+	err := u.DB.Where("provider_name = ? AND provider_id = ?", providerName, providerID).First(&user).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound{}
+		}
+		u.Logger.Error("Error fetching user with provider %s: %v", providerName, err)
+		return nil, errors.ErrDatabaseTransaction{Operation: "find user by provider"}
+	}
+
+	u.Logger.Info("Successfully fetched user with provider: %s", providerName)
+	return &user, nil
+}
+
+// userSortColumns whitelists the columns users can be sorted on
+var userSortColumns = map[string]bool{
+	"id":         true,
+	"email":      true,
+	"first_name": true,
+	"last_name":  true,
+	"status":     true,
+	"created_at": true,
+}
+
+func (u *userRepository) FindUsers(filter identity.UserFilter) (*[]entity.User, int64, error) {
+	u.Logger.Info("Fetching users with filter: ", filter)
+
+	query := u.DB.Model(&entity.User{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Role != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM user_roles JOIN roles ON roles.id = user_roles.role_id "+
+			"WHERE user_roles.user_id = users.id AND roles.name = ?)", filter.Role)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at <= ?", filter.CreatedTo)
+	}
+	if filter.Search != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Search) + "%"
+		query = query.Where("(email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ?)", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		u.Logger.Error("Error counting users: ", err)
+		return nil, 0, errors.ErrDatabaseTransaction{Operation: "count users"}
+	}
 
-	u.Logger.Info("Fetching all users")
+	sortBy := filter.SortBy
+	if !userSortColumns[sortBy] {
+		sortBy = "id"
+	}
+	direction, comparison := "ASC", ">"
+	if filter.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		if sortBy == "id" {
+			query = query.Where("id "+comparison+" ?", filter.After.ID)
+		} else {
+			value, err := cursorValue(sortBy, filter.After.Value)
+			if err != nil {
+				return nil, 0, err
+			}
+			query = query.Where("("+sortBy+", id) "+comparison+" (?, ?)", value, filter.After.ID)
+		}
+	} else {
+		query = query.Offset(filter.Offset)
+	}
+
+	if sortBy != "id" {
+		query = query.Order(sortBy + " " + direction)
+	}
 
 	var users []entity.User
-	if err := u.DB.Find(&users).Error; err != nil {
-		u.Logger.Error("Error fetching users: %v", err)
-		return nil, err
+	if err := query.Order("id " + direction).Limit(filter.Limit).Find(&users).Error; err != nil {
+		u.Logger.Error("Error fetching users: ", err)
+		return nil, 0, errors.ErrDatabaseTransaction{Operation: "find users"}
 	}
 
-	u.Logger.Info("Get users successfully")
-	return &users, nil
+	u.Logger.Info("Get users successfully, count: ", len(users), " of ", total)
+	return &users, total, nil
 }
 
 func (u *userRepository) CreateUser(user *entity.User) error {
@@ -72,6 +154,34 @@ func (u *userRepository) CreateUser(user *entity.User) error {
 	return nil
 }
 
+func (u *userRepository) RegisterUser(user *entity.User, roleID int64) error {
+	u.Logger.Info("Registering user with email: ", user.Email)
+
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			u.Logger.Error("Error creating user with email: ", user.Email, ", error: ", err)
+			return errors.ErrDatabaseTransaction{Operation: "create user"}
+		}
+
+		if err := tx.Create(&entity.UserRoles{UserID: user.ID, RoleID: roleID}).Error; err != nil {
+			u.Logger.Error("Error assigning role to user ID: ", user.ID, ", error: ", err)
+			return errors.ErrDatabaseTransaction{Operation: "assign user role"}
+		}
+
+		event, err := entity.NewUserRegisteredEvent(user)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(event).Error; err != nil {
+			u.Logger.Error("Error recording registration event of user ID: ", user.ID, ", error: ", err)
+			return errors.ErrDatabaseTransaction{Operation: "create outbox event"}
+		}
+
+		u.Logger.Info("User registered successfully with ID: ", user.ID)
+		return nil
+	})
+}
+
 func (u *userRepository) UpdateUserProfile(user *entity.User) error {
 	u.Logger.Info("Updating user profile with ID: %d", user.ID)
 
@@ -97,6 +207,55 @@ func (u *userRepository) UpdateUserPassword(user *entity.User) error {
 	return nil
 }
 
+func (u *userRepository) UpdateUserProvider(user *entity.User) error {
+	u.Logger.Info("Updating provider for user with ID: %d", user.ID)
+
+	err := u.DB.Model(user).Select("provider_name", "provider_id").Updates(user).Error
+	if err != nil {
+		u.Logger.Error("Error updating provider for user with ID %d: %v", user.ID, err)
+		return err
+	}
+
+	u.Logger.Info("Provider for user with ID %d updated successfully", user.ID)
+	return nil
+}
+
+func (u *userRepository) UpdateUserStatus(user *entity.User) error {
+	u.Logger.Info("Updating status for user with ID: %d", user.ID)
+
+	if err := u.DB.Model(user).Select("status").Updates(user).Error; err != nil {
+		u.Logger.Error("Error updating status for user with ID %d: %v", user.ID, err)
+		return err
+	}
+
+	u.Logger.Info("Status for user with ID %d updated successfully", user.ID)
+	return nil
+}
+
+func (u *userRepository) UpdateUserEmailVerified(user *entity.User) error {
+	u.Logger.Info("Updating email verification for user with ID: %d", user.ID)
+
+	if err := u.DB.Model(user).Select("email_verified_at").Updates(user).Error; err != nil {
+		u.Logger.Error("Error updating email verification for user with ID %d: %v", user.ID, err)
+		return err
+	}
+
+	u.Logger.Info("Email verification for user with ID %d updated successfully", user.ID)
+	return nil
+}
+
+func (u *userRepository) UpdateUserEmail(user *entity.User) error {
+	u.Logger.Info("Updating email for user with ID: %d", user.ID)
+
+	if err := u.DB.Model(user).Select("email", "email_verified_at").Updates(user).Error; err != nil {
+		u.Logger.Error("Error updating email for user with ID %d: %v", user.ID, err)
+		return err
+	}
+
+	u.Logger.Info("Email for user with ID %d updated successfully", user.ID)
+	return nil
+}
+
 func (u *userRepository) DeleteUser(id int64) error {
 	u.Logger.Info("Deleting user with ID: %d", id)
 
@@ -108,3 +267,19 @@ func (u *userRepository) DeleteUser(id int64) error {
 	u.Logger.Info("User with ID %d deleted successfully", id)
 	return nil
 }
+
+// likeEscaper makes a search term match literally inside a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// cursorValue converts the cursor value to the type of the sort column
+func cursorValue(sortBy string, value string) (interface{}, error) {
+	if sortBy != "created_at" {
+		return value, nil
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, errors.ErrInvalidUserData{Field: "cursor", Message: "is malformed"}
+	}
+	return createdAt, nil
+}
@@ -0,0 +1,92 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"github.com/hthinh24/go-store/services/identity/internal/mfa"
+	goRedis "github.com/redis/go-redis/v9"
+)
+
+const (
+	mfaChallengeKeyPattern         = "identity:mfa_challenge:%s"
+	mfaChallengeAttemptsKeyPattern = "identity:mfa_challenge:%s:attempts"
+)
+
+type mfaChallengeRepository struct {
+	logger logger.Logger
+	client *goRedis.Client
+}
+
+func NewMFAChallengeRepository(logger logger.Logger, client *goRedis.Client) *mfaChallengeRepository {
+	return &mfaChallengeRepository{
+		logger: logger,
+		client: client,
+	}
+}
+
+func (m *mfaChallengeRepository) SaveChallenge(ctx context.Context, key string, challenge *mfa.Challenge, ttl time.Duration) error {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+
+	if err := m.client.Set(ctx, fmt.Sprintf(mfaChallengeKeyPattern, key), data, ttl).Err(); err != nil {
+		m.logger.WithContext(ctx).Error("Failed to save MFA challenge of user ID:", challenge.UserID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "save mfa challenge"}
+	}
+
+	return nil
+}
+
+func (m *mfaChallengeRepository) FindChallenge(ctx context.Context, key string) (*mfa.Challenge, error) {
+	data, err := m.client.Get(ctx, fmt.Sprintf(mfaChallengeKeyPattern, key)).Bytes()
+	if errors.Is(err, goRedis.Nil) {
+		return nil, identityErrors.ErrInvalidMFAChallenge{}
+	}
+	if err != nil {
+		m.logger.WithContext(ctx).Error("Failed to find MFA challenge, error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "find mfa challenge"}
+	}
+
+	var challenge mfa.Challenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, identityErrors.ErrInvalidMFAChallenge{}
+	}
+
+	return &challenge, nil
+}
+
+func (m *mfaChallengeRepository) RecordFailedAttempt(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	attemptsKey := fmt.Sprintf(mfaChallengeAttemptsKeyPattern, key)
+
+	pipe := m.client.TxPipeline()
+	incr := pipe.Incr(ctx, attemptsKey)
+	// The counter is only useful while the challenge lives
+	pipe.Expire(ctx, attemptsKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		m.logger.WithContext(ctx).Error("Failed to record MFA attempt, error:", err)
+		return 0, identityErrors.ErrDatabaseTransaction{Operation: "record mfa attempt"}
+	}
+
+	return incr.Val(), nil
+}
+
+func (m *mfaChallengeRepository) DeleteChallenge(ctx context.Context, key string) error {
+	deleted, err := m.client.Del(ctx, fmt.Sprintf(mfaChallengeKeyPattern, key)).Result()
+	if err != nil {
+		m.logger.WithContext(ctx).Error("Failed to delete MFA challenge, error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "delete mfa challenge"}
+	}
+	m.client.Del(ctx, fmt.Sprintf(mfaChallengeAttemptsKeyPattern, key))
+
+	if deleted == 0 {
+		return identityErrors.ErrInvalidMFAChallenge{}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"github.com/hthinh24/go-store/services/identity/internal/oidc"
+	goRedis "github.com/redis/go-redis/v9"
+)
+
+const oidcStateKeyPattern = "identity:oidc_state:%s"
+
+type oidcStateRepository struct {
+	logger logger.Logger
+	client *goRedis.Client
+}
+
+func NewOIDCStateRepository(logger logger.Logger, client *goRedis.Client) *oidcStateRepository {
+	return &oidcStateRepository{
+		logger: logger,
+		client: client,
+	}
+}
+
+func (o *oidcStateRepository) SaveState(ctx context.Context, key string, state *oidc.State, ttl time.Duration) error {
+	logger := o.logger.WithContext(ctx)
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err := o.client.Set(ctx, fmt.Sprintf(oidcStateKeyPattern, key), data, ttl).Err(); err != nil {
+		logger.Error("Failed to save login state of provider:", state.Provider, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "save login state"}
+	}
+
+	return nil
+}
+
+func (o *oidcStateRepository) TakeState(ctx context.Context, key string) (*oidc.State, error) {
+	data, err := o.client.GetDel(ctx, fmt.Sprintf(oidcStateKeyPattern, key)).Bytes()
+	if errors.Is(err, goRedis.Nil) {
+		return nil, identityErrors.ErrInvalidOIDCState{}
+	}
+	if err != nil {
+		o.logger.WithContext(ctx).Error("Failed to take login state, error:", err)
+		return nil, identityErrors.ErrDatabaseTransaction{Operation: "take login state"}
+	}
+
+	var state oidc.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, identityErrors.ErrInvalidOIDCState{}
+	}
+
+	return &state, nil
+}
@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	goRedis "github.com/redis/go-redis/v9"
+)
+
+const rolePermissionsKeyPattern = "identity:role_permissions:%d"
+
+type permissionCacheRepository struct {
+	logger logger.Logger
+	client *goRedis.Client
+}
+
+func NewPermissionCacheRepository(logger logger.Logger, client *goRedis.Client) *permissionCacheRepository {
+	return &permissionCacheRepository{
+		logger: logger,
+		client: client,
+	}
+}
+
+func (p *permissionCacheRepository) GetRolePermissions(ctx context.Context, roleID int64) ([]string, bool, error) {
+	data, err := p.client.Get(ctx, fmt.Sprintf(rolePermissionsKeyPattern, roleID)).Bytes()
+	if errors.Is(err, goRedis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		p.logger.WithContext(ctx).Error("Failed to get cached permissions of role ID:", roleID, "Error:", err)
+		return nil, false, identityErrors.ErrDatabaseTransaction{Operation: "get cached role permissions"}
+	}
+
+	var permissions []string
+	if err := json.Unmarshal(data, &permissions); err != nil {
+		return nil, false, nil
+	}
+
+	return permissions, true, nil
+}
+
+func (p *permissionCacheRepository) SetRolePermissions(ctx context.Context, roleID int64, permissions []string, ttl time.Duration) error {
+	data, err := json.Marshal(permissions)
+	if err != nil {
+		return err
+	}
+
+	if err := p.client.Set(ctx, fmt.Sprintf(rolePermissionsKeyPattern, roleID), data, ttl).Err(); err != nil {
+		p.logger.WithContext(ctx).Error("Failed to cache permissions of role ID:", roleID, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "cache role permissions"}
+	}
+
+	return nil
+}
+
+func (p *permissionCacheRepository) InvalidateRoles(ctx context.Context, roleIDs ...int64) error {
+	if len(roleIDs) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(roleIDs))
+	for i, roleID := range roleIDs {
+		keys[i] = fmt.Sprintf(rolePermissionsKeyPattern, roleID)
+	}
+
+	if err := p.client.Del(ctx, keys...).Err(); err != nil {
+		p.logger.WithContext(ctx).Error("Failed to invalidate cached permissions of role IDs:", roleIDs, "Error:", err)
+		return identityErrors.ErrDatabaseTransaction{Operation: "invalidate role permissions"}
+	}
+
+	return nil
+}
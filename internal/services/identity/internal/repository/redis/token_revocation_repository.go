@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	identityErrors "github.com/hthinh24/go-store/services/identity/internal/errors"
+	goRedis "github.com/redis/go-redis/v9"
+)
+
+const (
+	revokedTokenKeyPattern    = "identity:revoked_token:%s"
+	tokenGenerationKeyPattern = "identity:token_generation:%d"
+)
+
+type tokenRevocationRepository struct {
+	logger logger.Logger
+	client *goRedis.Client
+}
+
+func NewTokenRevocationRepository(logger logger.Logger, client *goRedis.Client) *tokenRevocationRepository {
+	return &tokenRevocationRepository{
+		logger: logger,
+		client: client,
+	}
+}
+
+func (t *tokenRevocationRepository) RevokeToken(ctx context.Context, tokenID string, ttl time.Duration) error {
+	logger := t.logger.WithContext(ctx)
+	logger.Info("Revoking access token ID:", tokenID)
+
+	if err := t.client.Set(ctx, fmt.Sprintf(revokedTokenKeyPattern, tokenID), 1, ttl).Err(); err != nil {
+		logger.Error("Failed to revoke access token ID:", tokenID, "Error:", err)
+		return identityErrors.ErrTokenRevocation{Operation: "revoke token"}
+	}
+
+	return nil
+}
+
+func (t *tokenRevocationRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	exists, err := t.client.Exists(ctx, fmt.Sprintf(revokedTokenKeyPattern, tokenID)).Result()
+	if err != nil {
+		t.logger.WithContext(ctx).Error("Failed to check revocation of access token ID:", tokenID, "Error:", err)
+		return false, identityErrors.ErrTokenRevocation{Operation: "check revoked token"}
+	}
+
+	return exists > 0, nil
+}
+
+func (t *tokenRevocationRepository) GetTokenGeneration(ctx context.Context, userID int64) (int64, error) {
+	generation, err := t.client.Get(ctx, fmt.Sprintf(tokenGenerationKeyPattern, userID)).Int64()
+	if errors.Is(err, goRedis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		t.logger.WithContext(ctx).Error("Failed to get token generation of user ID:", userID, "Error:", err)
+		return 0, identityErrors.ErrTokenRevocation{Operation: "get token generation"}
+	}
+
+	return generation, nil
+}
+
+func (t *tokenRevocationRepository) IncrementTokenGeneration(ctx context.Context, userID int64) (int64, error) {
+	logger := t.logger.WithContext(ctx)
+	logger.Info("Incrementing token generation of user ID:", userID)
+
+	// The key has no expiry, a generation restarting at 0 would let a second logout-all reach
+	// the generation of tokens issued after the first one
+	generation, err := t.client.Incr(ctx, fmt.Sprintf(tokenGenerationKeyPattern, userID)).Result()
+	if err != nil {
+		logger.Error("Failed to increment token generation of user ID:", userID, "Error:", err)
+		return 0, identityErrors.ErrTokenRevocation{Operation: "increment token generation"}
+	}
+
+	return generation, nil
+}
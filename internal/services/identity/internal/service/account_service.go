@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/mail"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type accountService struct {
+	logger                 logger.Logger
+	userRepository         identity.UserRepository
+	accountTokenRepository identity.AccountTokenRepository
+	authService            identity.AuthService
+	mailSender             mail.Sender
+	config                 *config.AppConfig
+}
+
+func NewAccountService(logger logger.Logger, userRepository identity.UserRepository,
+	accountTokenRepository identity.AccountTokenRepository, authService identity.AuthService,
+	mailSender mail.Sender, cfg *config.AppConfig) identity.AccountService {
+	return &accountService{
+		logger:                 logger,
+		userRepository:         userRepository,
+		accountTokenRepository: accountTokenRepository,
+		authService:            authService,
+		mailSender:             mailSender,
+		config:                 cfg,
+	}
+}
+
+func (a *accountService) SendVerificationEmail(ctx context.Context, user *entity.User) error {
+	token, err := a.issueToken(user, entity.AccountTokenEmailVerification, a.config.GetVerificationExpiresIn())
+	if err != nil {
+		return err
+	}
+
+	return a.mailSender.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email to activate your account:\n%s\n\nThe link expires in %s.",
+			user.FirstName, tokenURL(a.config.Account.VerificationURL, token), a.config.GetVerificationExpiresIn()),
+	})
+}
+
+func (a *accountService) ResendVerificationEmail(ctx context.Context, request request.EmailRequest) error {
+	logger := a.logger.WithContext(ctx)
+
+	user, err := a.userRepository.FindUserByEmail(request.Email)
+	if err != nil || user.Status != string(constants.UserStatusPending) {
+		logger.Info("Verification email not resent, no pending user with email:", request.Email)
+		return nil
+	}
+
+	if err := a.SendVerificationEmail(ctx, user); err != nil {
+		logger.Error("Error sending verification email to user ID:", user.ID, "Error:", err)
+		return nil
+	}
+
+	logger.Info("Verification email resent to user ID:", user.ID)
+	return nil
+}
+
+func (a *accountService) VerifyEmail(ctx context.Context, request request.VerifyEmailRequest) error {
+	logger := a.logger.WithContext(ctx)
+
+	token, err := a.accountTokenRepository.UseAccountToken(hashOpaqueToken(request.Token), entity.AccountTokenEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.userRepository.FindUserByID(token.UserID)
+	if err != nil {
+		return err
+	}
+
+	switch user.Status {
+	case string(constants.UserStatusActive):
+		return markEmailVerified(a.userRepository, user)
+	case string(constants.UserStatusPending):
+		if err := markEmailVerified(a.userRepository, user); err != nil {
+			return err
+		}
+		user.Status = string(constants.UserStatusActive)
+		if err := a.userRepository.UpdateUserStatus(user); err != nil {
+			return err
+		}
+		logger.Info("Email verified, user activated with ID:", user.ID)
+		return nil
+	default:
+		// Verifying the email never brings back a deactivated or deleted user
+		logger.Warn("Email verification for user that is not pending, user ID:", user.ID)
+		return customErr.ErrUserNotActive{}
+	}
+}
+
+func (a *accountService) RequestEmailChange(ctx context.Context, user *entity.User, newEmail string) error {
+	logger := a.logger.WithContext(ctx)
+
+	if newEmail == user.Email {
+		return nil
+	}
+	if err := a.checkEmailAvailable(newEmail); err != nil {
+		return err
+	}
+
+	token, err := a.issueEmailChangeToken(user, newEmail)
+	if err != nil {
+		return err
+	}
+
+	err = a.mailSender.Send(ctx, mail.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm this email for your account:\n%s\n\nThe link expires in %s. Until then you keep signing in with %s.",
+			user.FirstName, tokenURL(a.config.Account.EmailChangeURL, token), a.config.GetVerificationExpiresIn(), user.Email),
+	})
+	if err != nil {
+		logger.Error("Error sending email change confirmation to user ID:", user.ID, "Error:", err)
+		return err
+	}
+
+	logger.Info("Email change requested for user ID:", user.ID)
+	return nil
+}
+
+func (a *accountService) ConfirmEmailChange(ctx context.Context, request request.VerifyEmailRequest) error {
+	logger := a.logger.WithContext(ctx)
+
+	token, err := a.accountTokenRepository.UseAccountToken(hashOpaqueToken(request.Token), entity.AccountTokenEmailChange)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.userRepository.FindUserByID(token.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Status != string(constants.UserStatusActive) {
+		logger.Warn("Email change for user that is not active, user ID:", user.ID)
+		return customErr.ErrUserNotActive{}
+	}
+	// The email may have been taken since the change was requested
+	if err := a.checkEmailAvailable(token.Email); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.Email = token.Email
+	user.EmailVerifiedAt = &now
+	if err := a.userRepository.UpdateUserEmail(user); err != nil {
+		return err
+	}
+
+	// Links mailed to the old email must not work for the account anymore
+	err = a.accountTokenRepository.InvalidateAccountTokens(user.ID,
+		entity.AccountTokenPasswordReset, entity.AccountTokenEmailVerification, entity.AccountTokenEmailChange)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Email changed for user ID:", user.ID)
+	return nil
+}
+
+func (a *accountService) RequestPasswordReset(ctx context.Context, request request.EmailRequest) error {
+	logger := a.logger.WithContext(ctx)
+
+	user, err := a.userRepository.FindUserByEmail(request.Email)
+	if err != nil || !canResetPassword(user) {
+		logger.Info("Password reset not sent, no user that can reset with email:", request.Email)
+		return nil
+	}
+
+	token, err := a.issueToken(user, entity.AccountTokenPasswordReset, a.config.GetPasswordResetExpiresIn())
+	if err != nil {
+		return err
+	}
+
+	err = a.mailSender.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nChoose a new password here:\n%s\n\nThe link expires in %s. If you did not ask for it, ignore this email.",
+			user.FirstName, tokenURL(a.config.Account.PasswordResetURL, token), a.config.GetPasswordResetExpiresIn()),
+	})
+	if err != nil {
+		logger.Error("Error sending password reset email to user ID:", user.ID, "Error:", err)
+		return nil
+	}
+
+	logger.Info("Password reset email sent to user ID:", user.ID)
+	return nil
+}
+
+func (a *accountService) ResetPassword(ctx context.Context, request request.ResetPasswordRequest) error {
+	logger := a.logger.WithContext(ctx)
+
+	token, err := a.accountTokenRepository.UseAccountToken(hashOpaqueToken(request.Token), entity.AccountTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.userRepository.FindUserByID(token.UserID)
+	if err != nil {
+		return err
+	}
+	if !canResetPassword(user) {
+		logger.Warn("Password reset for user that cannot reset, user ID:", user.ID)
+		return customErr.ErrUserNotActive{}
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Error("Error hashing new password:", err)
+		return err
+	}
+
+	// The mailed token proves the user owns the email, a pending user is verified as well
+	user.Password = string(hashedPassword)
+	if err := a.userRepository.UpdateUserPassword(user); err != nil {
+		return err
+	}
+	if err := markEmailVerified(a.userRepository, user); err != nil {
+		return err
+	}
+	if user.Status == string(constants.UserStatusPending) {
+		user.Status = string(constants.UserStatusActive)
+		if err := a.userRepository.UpdateUserStatus(user); err != nil {
+			return err
+		}
+	}
+
+	// Whoever knew the old password may hold a session, end all of them
+	if err := a.authService.LogoutAll(ctx, user.ID); err != nil {
+		return err
+	}
+
+	logger.Info("Password reset for user ID:", user.ID)
+	return nil
+}
+
+// issueToken stores a new token of the purpose, older tokens of the user stop working
+func (a *accountService) issueToken(user *entity.User, purpose entity.AccountTokenPurpose, expiresIn time.Duration) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = a.accountTokenRepository.CreateAccountToken(&entity.AccountToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashOpaqueToken(token),
+		ExpiresAt: time.Now().Add(expiresIn),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// markEmailVerified records that the user proved to own its current email, an earlier proof is kept
+func markEmailVerified(userRepository identity.UserRepository, user *entity.User) error {
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return userRepository.UpdateUserEmailVerified(user)
+}
+
+// issueEmailChangeToken stores a new email change token carrying the new email
+func (a *accountService) issueEmailChangeToken(user *entity.User, newEmail string) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = a.accountTokenRepository.CreateAccountToken(&entity.AccountToken{
+		UserID:    user.ID,
+		Purpose:   entity.AccountTokenEmailChange,
+		TokenHash: hashOpaqueToken(token),
+		Email:     newEmail,
+		ExpiresAt: time.Now().Add(a.config.GetVerificationExpiresIn()),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (a *accountService) checkEmailAvailable(email string) error {
+	_, err := a.userRepository.FindUserByEmail(email)
+	if err == nil {
+		return customErr.ErrUserAlreadyExists{Email: email}
+	}
+	if errors.Is(err, customErr.ErrUserNotFound{}) {
+		return nil
+	}
+	return err
+}
+
+func canResetPassword(user *entity.User) bool {
+	return user.Status == string(constants.UserStatusActive) || user.Status == string(constants.UserStatusPending)
+}
+
+func tokenURL(base string, token string) string {
+	return base + "?token=" + url.QueryEscape(token)
+}
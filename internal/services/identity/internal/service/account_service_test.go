@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/mail"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+// memoryAccountTokenRepository keeps account tokens in memory
+type memoryAccountTokenRepository struct {
+	tokens []*entity.AccountToken
+}
+
+func (m *memoryAccountTokenRepository) CreateAccountToken(token *entity.AccountToken) error {
+	_ = m.InvalidateAccountTokens(token.UserID, token.Purpose)
+	m.tokens = append(m.tokens, token)
+	return nil
+}
+
+func (m *memoryAccountTokenRepository) UseAccountToken(tokenHash string, purpose entity.AccountTokenPurpose) (*entity.AccountToken, error) {
+	now := time.Now()
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash && token.Purpose == purpose && token.UsedAt == nil && now.Before(token.ExpiresAt) {
+			token.UsedAt = &now
+			return token, nil
+		}
+	}
+	return nil, customErr.ErrInvalidAccountToken{}
+}
+
+func (m *memoryAccountTokenRepository) InvalidateAccountTokens(userID int64, purposes ...entity.AccountTokenPurpose) error {
+	now := time.Now()
+	for _, token := range m.tokens {
+		for _, purpose := range purposes {
+			if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+				token.UsedAt = &now
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memoryUserRepository) UpdateUserEmail(user *entity.User) error {
+	m.users[user.ID] = user
+	return nil
+}
+
+// memoryMailSender records the sent emails
+type memoryMailSender struct {
+	messages []mail.Message
+}
+
+func (m *memoryMailSender) Send(_ context.Context, message mail.Message) error {
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// token returns the token of the link in the last email sent
+func (m *memoryMailSender) token(t *testing.T) string {
+	t.Helper()
+
+	if len(m.messages) == 0 {
+		t.Fatal("no email sent")
+	}
+	body := m.messages[len(m.messages)-1].Body
+	start := strings.Index(body, "?token=")
+	if start < 0 {
+		t.Fatalf("no token link in email: %q", body)
+	}
+	token, err := url.QueryUnescape(strings.Fields(body[start+len("?token="):])[0])
+	if err != nil {
+		t.Fatalf("invalid token link: %v", err)
+	}
+	return token
+}
+
+type accountFixture struct {
+	service identity.AccountService
+	users   *memoryUserRepository
+	tokens  *memoryAccountTokenRepository
+	mails   *memoryMailSender
+	user    *entity.User
+}
+
+func newAccountFixture(t *testing.T) *accountFixture {
+	t.Helper()
+
+	users := &memoryUserRepository{users: make(map[int64]*entity.User)}
+	tokens := &memoryAccountTokenRepository{}
+	mails := &memoryMailSender{}
+	cfg := &config.AppConfig{}
+	cfg.Account.SetDefaults()
+
+	verifiedAt := time.Now().Add(-time.Hour)
+	user := &entity.User{Email: "old@example.com", Status: string(constants.UserStatusActive), EmailVerifiedAt: &verifiedAt}
+	_ = users.CreateUser(user)
+
+	return &accountFixture{
+		service: NewAccountService(logger.NewAppLogger("development"), users, tokens, &stubAuthService{}, mails, cfg),
+		users:   users,
+		tokens:  tokens,
+		mails:   mails,
+		user:    user,
+	}
+}
+
+func TestEmailChangeKeepsOldEmailUntilConfirmed(t *testing.T) {
+	f := newAccountFixture(t)
+	verifiedAt := f.user.EmailVerifiedAt
+
+	if err := f.service.RequestPasswordReset(context.Background(), request.EmailRequest{Email: "old@example.com"}); err != nil {
+		t.Fatalf("RequestPasswordReset: %v", err)
+	}
+	resetToken := f.mails.token(t)
+
+	if err := f.service.RequestEmailChange(context.Background(), f.user, "new@example.com"); err != nil {
+		t.Fatalf("RequestEmailChange: %v", err)
+	}
+	if to := f.mails.messages[len(f.mails.messages)-1].To; to != "new@example.com" {
+		t.Errorf("confirmation sent to %q, want the new email", to)
+	}
+	if f.user.Email != "old@example.com" || f.user.EmailVerifiedAt != verifiedAt {
+		t.Fatalf("user = %+v, want the old email until confirmed", f.user)
+	}
+
+	if err := f.service.ConfirmEmailChange(context.Background(), request.VerifyEmailRequest{Token: f.mails.token(t)}); err != nil {
+		t.Fatalf("ConfirmEmailChange: %v", err)
+	}
+	if f.user.Email != "new@example.com" {
+		t.Errorf("email = %q, want the confirmed one", f.user.Email)
+	}
+	if f.user.EmailVerifiedAt == nil || !f.user.EmailVerifiedAt.After(*verifiedAt) {
+		t.Errorf("email verified at = %v, want the confirmation time", f.user.EmailVerifiedAt)
+	}
+
+	// The reset link mailed to the old email no longer works
+	err := f.service.ResetPassword(context.Background(), request.ResetPasswordRequest{
+		Token: resetToken, NewPassword: "new-password", NewPasswordConfirm: "new-password",
+	})
+	if !errors.Is(err, customErr.ErrInvalidAccountToken{}) {
+		t.Errorf("ResetPassword error = %v, want %v", err, customErr.ErrInvalidAccountToken{})
+	}
+}
+
+func TestEmailChangeRejects(t *testing.T) {
+	f := newAccountFixture(t)
+	_ = f.users.CreateUser(&entity.User{Email: "taken@example.com", Status: string(constants.UserStatusActive)})
+
+	err := f.service.RequestEmailChange(context.Background(), f.user, "taken@example.com")
+	if !errors.Is(err, customErr.ErrUserAlreadyExists{Email: "taken@example.com"}) {
+		t.Errorf("RequestEmailChange error = %v, want %v", err, customErr.ErrUserAlreadyExists{Email: "taken@example.com"})
+	}
+
+	if err := f.service.RequestEmailChange(context.Background(), f.user, "new@example.com"); err != nil {
+		t.Fatalf("RequestEmailChange: %v", err)
+	}
+	token := f.mails.token(t)
+
+	// Another user took the email before the change was confirmed
+	_ = f.users.CreateUser(&entity.User{Email: "new@example.com", Status: string(constants.UserStatusActive)})
+	err = f.service.ConfirmEmailChange(context.Background(), request.VerifyEmailRequest{Token: token})
+	if !errors.Is(err, customErr.ErrUserAlreadyExists{Email: "new@example.com"}) {
+		t.Errorf("ConfirmEmailChange error = %v, want %v", err, customErr.ErrUserAlreadyExists{Email: "new@example.com"})
+	}
+	if f.user.Email != "old@example.com" {
+		t.Errorf("email = %q, want the old one", f.user.Email)
+	}
+
+	err = f.service.ConfirmEmailChange(context.Background(), request.VerifyEmailRequest{Token: token})
+	if !errors.Is(err, customErr.ErrInvalidAccountToken{}) {
+		t.Errorf("reused ConfirmEmailChange error = %v, want %v", err, customErr.ErrInvalidAccountToken{})
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+)
+
+type addressService struct {
+	logger            logger.Logger
+	addressRepository identity.AddressRepository
+	cfg               config.Address
+}
+
+func NewAddressService(logger logger.Logger, addressRepository identity.AddressRepository, cfg config.Address) identity.AddressService {
+	return &addressService{
+		logger:            logger,
+		addressRepository: addressRepository,
+		cfg:               cfg,
+	}
+}
+
+func (a *addressService) GetAddresses(ctx context.Context, userID int64) (*[]response.AddressResponse, error) {
+	addresses, err := a.addressRepository.FindAddressesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	addressResponses := make([]response.AddressResponse, len(*addresses))
+	for i, address := range *addresses {
+		addressResponses[i] = *createAddressResponse(&address)
+	}
+	return &addressResponses, nil
+}
+
+func (a *addressService) GetAddress(ctx context.Context, userID int64, id int64) (*response.AddressResponse, error) {
+	address, err := a.addressRepository.FindAddressByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return createAddressResponse(address), nil
+}
+
+func (a *addressService) CreateAddress(ctx context.Context, userID int64, data request.AddressRequest) (*response.AddressResponse, error) {
+	address := &entity.UserAddress{UserID: userID}
+	applyAddressRequest(address, data)
+
+	if err := a.addressRepository.CreateAddress(address, a.cfg.MaxPerUser); err != nil {
+		return nil, err
+	}
+
+	a.logger.WithContext(ctx).Info("Address ID:", address.ID, "added for user ID:", userID)
+	return createAddressResponse(address), nil
+}
+
+func (a *addressService) UpdateAddress(ctx context.Context, userID int64, id int64, data request.AddressRequest) (*response.AddressResponse, error) {
+	address, err := a.addressRepository.FindAddressByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// A default stays until another address takes it, otherwise a PUT leaving the flags out
+	// would leave the user without a default address
+	wasDefaultShipping, wasDefaultBilling := address.IsDefaultShipping, address.IsDefaultBilling
+	applyAddressRequest(address, data)
+	address.IsDefaultShipping = address.IsDefaultShipping || wasDefaultShipping
+	address.IsDefaultBilling = address.IsDefaultBilling || wasDefaultBilling
+
+	if err := a.addressRepository.UpdateAddress(address); err != nil {
+		return nil, err
+	}
+
+	return createAddressResponse(address), nil
+}
+
+func (a *addressService) DeleteAddress(ctx context.Context, userID int64, id int64) error {
+	address, err := a.addressRepository.FindAddressByID(userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.addressRepository.DeleteAddress(address); err != nil {
+		return err
+	}
+
+	a.logger.WithContext(ctx).Info("Address ID:", id, "removed for user ID:", userID)
+	return nil
+}
+
+func applyAddressRequest(address *entity.UserAddress, data request.AddressRequest) {
+	address.FullName = data.FullName
+	address.Phone = data.Phone
+	address.AddressLine = data.AddressLine
+	address.City = data.City
+	address.State = data.State
+	address.PostalCode = data.PostalCode
+	address.Country = data.Country
+	address.IsDefaultShipping = data.IsDefaultShipping
+	address.IsDefaultBilling = data.IsDefaultBilling
+}
+
+func createAddressResponse(address *entity.UserAddress) *response.AddressResponse {
+	return &response.AddressResponse{
+		ID:                address.ID,
+		FullName:          address.FullName,
+		Phone:             address.Phone,
+		AddressLine:       address.AddressLine,
+		City:              address.City,
+		State:             address.State,
+		PostalCode:        address.PostalCode,
+		Country:           address.Country,
+		IsDefaultShipping: address.IsDefaultShipping,
+		IsDefaultBilling:  address.IsDefaultBilling,
+		CreatedAt:         address.CreatedAt,
+		UpdatedAt:         address.UpdatedAt,
+	}
+}
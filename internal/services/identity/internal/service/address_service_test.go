@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+// memoryAddressRepository keeps addresses in memory and moves default flags like the database
+type memoryAddressRepository struct {
+	addresses map[int64]*entity.UserAddress
+	nextID    int64
+}
+
+func (m *memoryAddressRepository) FindAddressesByUserID(userID int64) (*[]entity.UserAddress, error) {
+	addresses := make([]entity.UserAddress, 0)
+	for id := int64(1); id <= m.nextID; id++ {
+		if address, ok := m.addresses[id]; ok && address.UserID == userID {
+			addresses = append(addresses, *address)
+		}
+	}
+	return &addresses, nil
+}
+
+func (m *memoryAddressRepository) FindAddressByID(userID int64, id int64) (*entity.UserAddress, error) {
+	address, ok := m.addresses[id]
+	if !ok || address.UserID != userID {
+		return nil, customErr.ErrAddressNotFound{}
+	}
+	found := *address
+	return &found, nil
+}
+
+func (m *memoryAddressRepository) CreateAddress(address *entity.UserAddress, _ int) error {
+	addresses, _ := m.FindAddressesByUserID(address.UserID)
+	if len(*addresses) == 0 {
+		address.IsDefaultShipping = true
+		address.IsDefaultBilling = true
+	}
+
+	m.nextID++
+	address.ID = m.nextID
+	return m.UpdateAddress(address)
+}
+
+func (m *memoryAddressRepository) UpdateAddress(address *entity.UserAddress) error {
+	for _, other := range m.addresses {
+		if other.UserID == address.UserID && other.ID != address.ID {
+			other.IsDefaultShipping = other.IsDefaultShipping && !address.IsDefaultShipping
+			other.IsDefaultBilling = other.IsDefaultBilling && !address.IsDefaultBilling
+		}
+	}
+	stored := *address
+	m.addresses[address.ID] = &stored
+	return nil
+}
+
+func (m *memoryAddressRepository) DeleteAddress(address *entity.UserAddress) error {
+	delete(m.addresses, address.ID)
+	return nil
+}
+
+// defaults returns the IDs of the default shipping and billing addresses of the user
+func (m *memoryAddressRepository) defaults(userID int64) (shipping []int64, billing []int64) {
+	addresses, _ := m.FindAddressesByUserID(userID)
+	for _, address := range *addresses {
+		if address.IsDefaultShipping {
+			shipping = append(shipping, address.ID)
+		}
+		if address.IsDefaultBilling {
+			billing = append(billing, address.ID)
+		}
+	}
+	return shipping, billing
+}
+
+func newAddressRequest(city string) request.AddressRequest {
+	return request.AddressRequest{
+		FullName: "Nguyen Van A", Phone: "0901234567", AddressLine: "1 Le Loi", City: city,
+		State: "Ho Chi Minh", PostalCode: "700000", Country: "VN",
+	}
+}
+
+func TestUpdateAddressKeepsDefaults(t *testing.T) {
+	addresses := &memoryAddressRepository{addresses: make(map[int64]*entity.UserAddress)}
+	cfg := config.Address{}
+	cfg.SetDefaults()
+	service := NewAddressService(logger.NewAppLogger("development"), addresses, cfg)
+	ctx := context.Background()
+
+	first, err := service.CreateAddress(ctx, 1, newAddressRequest("Thu Duc"))
+	if err != nil {
+		t.Fatalf("CreateAddress: %v", err)
+	}
+	second, _ := service.CreateAddress(ctx, 1, newAddressRequest("District 1"))
+
+	// A PUT leaving the flags out keeps the only default address
+	updated, err := service.UpdateAddress(ctx, 1, first.ID, newAddressRequest("Thu Duc City"))
+	if err != nil {
+		t.Fatalf("UpdateAddress: %v", err)
+	}
+	if updated.City != "Thu Duc City" || !updated.IsDefaultShipping || !updated.IsDefaultBilling {
+		t.Errorf("updated = %+v, want the new city and the default flags kept", updated)
+	}
+
+	// Setting a flag on another address moves it
+	data := newAddressRequest("District 1")
+	data.IsDefaultBilling = true
+	if _, err := service.UpdateAddress(ctx, 1, second.ID, data); err != nil {
+		t.Fatalf("UpdateAddress: %v", err)
+	}
+	shipping, billing := addresses.defaults(1)
+	if len(shipping) != 1 || shipping[0] != first.ID || len(billing) != 1 || billing[0] != second.ID {
+		t.Errorf("default shipping = %v, billing = %v, want [%d] and [%d]", shipping, billing, first.ID, second.ID)
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+// apiKeyPrefix starts every API key, so keys are told apart from access tokens and can be
+// found by secret scanners
+const apiKeyPrefix = "gsk_"
+
+// apiKeyPrefixLength is how much of a key is kept in clear text to identify it
+const apiKeyPrefixLength = len(apiKeyPrefix) + 8
+
+type apiKeyService struct {
+	logger             logger.Logger
+	apiKeyRepository   identity.APIKeyRepository
+	userRepository     identity.UserRepository
+	auditLogRepository identity.AuditLogRepository
+	authService        identity.AuthService
+}
+
+func NewAPIKeyService(logger logger.Logger, apiKeyRepository identity.APIKeyRepository, userRepository identity.UserRepository,
+	auditLogRepository identity.AuditLogRepository, authService identity.AuthService) identity.APIKeyService {
+	return &apiKeyService{
+		logger:             logger,
+		apiKeyRepository:   apiKeyRepository,
+		userRepository:     userRepository,
+		auditLogRepository: auditLogRepository,
+		authService:        authService,
+	}
+}
+
+func (a *apiKeyService) GetAPIKeys(ctx context.Context, userID int64) (*[]response.APIKeyResponse, error) {
+	keys, err := a.apiKeyRepository.FindAPIKeysByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	keyResponses := make([]response.APIKeyResponse, len(*keys))
+	for i, key := range *keys {
+		keyResponses[i] = *createAPIKeyResponse(&key)
+	}
+	return &keyResponses, nil
+}
+
+func (a *apiKeyService) CreateAPIKey(ctx context.Context, userID int64, callerPermissions []string, data request.CreateAPIKeyRequest) (*response.CreatedAPIKeyResponse, error) {
+	permissions, err := a.authService.FindUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := slices.Clone(data.Scopes)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+	for _, scope := range scopes {
+		if !slices.Contains(permissions, scope) || !slices.Contains(callerPermissions, scope) {
+			return nil, customErr.ErrInvalidUserData{Field: "scopes", Message: fmt.Sprintf("'%s' is not a permission of the user", scope)}
+		}
+	}
+
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + secret
+
+	apiKey := &entity.APIKey{
+		UserID:    userID,
+		Name:      data.Name,
+		Prefix:    key[:apiKeyPrefixLength],
+		KeyHash:   hashOpaqueToken(key),
+		Scopes:    scopes,
+		ExpiresAt: data.ExpiresAt,
+	}
+	if err := a.apiKeyRepository.CreateAPIKey(apiKey); err != nil {
+		return nil, err
+	}
+
+	if err := a.audit(ctx, entity.AuditEventAPIKeyCreated, apiKey); err != nil {
+		return nil, err
+	}
+	return &response.CreatedAPIKeyResponse{
+		APIKeyResponse: *createAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (a *apiKeyService) RevokeAPIKey(ctx context.Context, userID int64, id int64) error {
+	apiKey, err := a.apiKeyRepository.FindAPIKeyByID(userID, id)
+	if err != nil {
+		return err
+	}
+	if apiKey.RevokedAt != nil {
+		return nil
+	}
+
+	if err := a.apiKeyRepository.RevokeAPIKey(apiKey); err != nil {
+		return err
+	}
+
+	return a.audit(ctx, entity.AuditEventAPIKeyRevoked, apiKey)
+}
+
+func (a *apiKeyService) VerifyAPIKey(ctx context.Context, key string) (*response.VerifyResponse, error) {
+	logger := a.logger.WithContext(ctx)
+
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, rest.AuthenticationError{}
+	}
+
+	apiKey, err := a.apiKeyRepository.FindAPIKeyByHash(hashOpaqueToken(key))
+	if err != nil {
+		if errors.Is(err, customErr.ErrAPIKeyNotFound{}) {
+			return nil, rest.AuthenticationError{}
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !apiKey.IsActive(now) {
+		logger.Warn("Revoked or expired API key presented, key ID:", apiKey.ID, ", user ID:", apiKey.UserID)
+		return nil, rest.AuthenticationError{}
+	}
+
+	user, err := a.userRepository.FindUserByID(apiKey.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != string(constants.UserStatusActive) {
+		logger.Warn("API key of inactive user presented, key ID:", apiKey.ID, ", user ID:", apiKey.UserID)
+		return nil, rest.AuthenticationError{}
+	}
+
+	// Scopes never outlive the permissions they were taken from
+	permissions, err := a.authService.FindUserPermissions(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, err
+	}
+	granted := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		if slices.Contains(permissions, scope) {
+			granted = append(granted, scope)
+		}
+	}
+
+	// The last use is informational, failing to record it does not fail the request
+	if err := a.apiKeyRepository.UpdateAPIKeyLastUsed(apiKey.ID, now); err != nil {
+		logger.Warn("Failed to record last use of API key ID:", apiKey.ID, "Error:", err)
+	}
+
+	return &response.VerifyResponse{
+		UserID:      strconv.FormatInt(apiKey.UserID, 10),
+		Roles:       []string{},
+		Permissions: granted,
+	}, nil
+}
+
+func (a *apiKeyService) audit(ctx context.Context, event entity.AuditEvent, apiKey *entity.APIKey) error {
+	a.logger.WithContext(ctx).Info("API key ID:", apiKey.ID, "of user ID:", apiKey.UserID, "event:", event)
+
+	return a.auditLogRepository.CreateAuditLog(&entity.AuditLog{
+		Event:   event,
+		UserID:  &apiKey.UserID,
+		ActorID: &apiKey.UserID,
+		Details: fmt.Sprintf("key: %d, prefix: %s, scopes: %s", apiKey.ID, apiKey.Prefix, strings.Join(apiKey.Scopes, ",")),
+	})
+}
+
+func createAPIKeyResponse(apiKey *entity.APIKey) *response.APIKeyResponse {
+	return &response.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+// memoryAPIKeyRepository keeps API keys in memory
+type memoryAPIKeyRepository struct {
+	keys   map[int64]*entity.APIKey
+	nextID int64
+}
+
+func (m *memoryAPIKeyRepository) CreateAPIKey(key *entity.APIKey) error {
+	m.nextID++
+	key.ID = m.nextID
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *memoryAPIKeyRepository) FindAPIKeysByUserID(userID int64) (*[]entity.APIKey, error) {
+	keys := make([]entity.APIKey, 0)
+	for _, key := range m.keys {
+		if key.UserID == userID {
+			keys = append(keys, *key)
+		}
+	}
+	return &keys, nil
+}
+
+func (m *memoryAPIKeyRepository) FindAPIKeyByID(userID int64, id int64) (*entity.APIKey, error) {
+	key, ok := m.keys[id]
+	if !ok || key.UserID != userID {
+		return nil, customErr.ErrAPIKeyNotFound{}
+	}
+	return key, nil
+}
+
+func (m *memoryAPIKeyRepository) FindAPIKeyByHash(keyHash string) (*entity.APIKey, error) {
+	for _, key := range m.keys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return nil, customErr.ErrAPIKeyNotFound{}
+}
+
+func (m *memoryAPIKeyRepository) RevokeAPIKey(key *entity.APIKey) error {
+	now := time.Now()
+	m.keys[key.ID].RevokedAt = &now
+	return nil
+}
+
+func (m *memoryAPIKeyRepository) UpdateAPIKeyLastUsed(id int64, usedAt time.Time) error {
+	m.keys[id].LastUsedAt = &usedAt
+	return nil
+}
+
+// memoryAuditLogRepository records the audit events written
+type memoryAuditLogRepository struct {
+	events []entity.AuditEvent
+}
+
+func (m *memoryAuditLogRepository) CreateAuditLog(auditLog *entity.AuditLog) error {
+	m.events = append(m.events, auditLog.Event)
+	return nil
+}
+
+// permissionAuthService returns the permissions of the users from a map
+type permissionAuthService struct {
+	identity.AuthService
+	permissions map[int64][]string
+}
+
+func (s *permissionAuthService) FindUserPermissions(_ context.Context, userID int64) ([]string, error) {
+	return s.permissions[userID], nil
+}
+
+type apiKeyFixture struct {
+	service     identity.APIKeyService
+	keys        *memoryAPIKeyRepository
+	users       *memoryUserRepository
+	auditLogs   *memoryAuditLogRepository
+	authService *permissionAuthService
+}
+
+func newAPIKeyFixture(t *testing.T) *apiKeyFixture {
+	t.Helper()
+
+	keys := &memoryAPIKeyRepository{keys: make(map[int64]*entity.APIKey)}
+	users := &memoryUserRepository{users: make(map[int64]*entity.User)}
+	auditLogs := &memoryAuditLogRepository{}
+	authService := &permissionAuthService{permissions: map[int64][]string{
+		1: {"product:read", "product:write", "order:read"},
+	}}
+	_ = users.CreateUser(&entity.User{Email: "user@example.com", Status: string(constants.UserStatusActive)})
+
+	return &apiKeyFixture{
+		service:     NewAPIKeyService(logger.NewAppLogger("development"), keys, users, auditLogs, authService),
+		keys:        keys,
+		users:       users,
+		auditLogs:   auditLogs,
+		authService: authService,
+	}
+}
+
+func (f *apiKeyFixture) create(t *testing.T, scopes []string, expiresAt *time.Time) string {
+	t.Helper()
+
+	created, err := f.service.CreateAPIKey(context.Background(), 1, f.authService.permissions[1],
+		request.CreateAPIKeyRequest{Name: "ci", Scopes: scopes, ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	return created.Key
+}
+
+func TestCreateAPIKeyScopes(t *testing.T) {
+	tests := []struct {
+		name              string
+		scopes            []string
+		callerPermissions []string
+		wantScopes        []string
+		wantErr           bool
+	}{
+		{
+			name:              "subset of the permissions",
+			scopes:            []string{"product:write", "product:read", "product:read"},
+			callerPermissions: []string{"product:read", "product:write", "order:read"},
+			wantScopes:        []string{"product:read", "product:write"},
+		},
+		{
+			name:              "permission the user does not hold",
+			scopes:            []string{"product:read", "user:delete"},
+			callerPermissions: []string{"product:read", "user:delete"},
+			wantErr:           true,
+		},
+		{
+			name:              "permission the caller does not hold",
+			scopes:            []string{"order:read"},
+			callerPermissions: []string{"product:read"},
+			wantErr:           true,
+		},
+		{
+			name:    "caller without permissions",
+			scopes:  []string{"product:read"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAPIKeyFixture(t)
+
+			created, err := f.service.CreateAPIKey(context.Background(), 1, tt.callerPermissions,
+				request.CreateAPIKeyRequest{Name: "ci", Scopes: tt.scopes})
+			if tt.wantErr {
+				if !errors.As(err, &customErr.ErrInvalidUserData{}) {
+					t.Fatalf("CreateAPIKey = %+v, %v, want invalid scopes", created, err)
+				}
+				if len(f.keys.keys) != 0 {
+					t.Errorf("keys = %d, want none created", len(f.keys.keys))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateAPIKey: %v", err)
+			}
+			if !slices.Equal(created.Scopes, tt.wantScopes) {
+				t.Errorf("scopes = %v, want %v", created.Scopes, tt.wantScopes)
+			}
+			if !slices.Equal(f.auditLogs.events, []entity.AuditEvent{entity.AuditEventAPIKeyCreated}) {
+				t.Errorf("audit events = %v", f.auditLogs.events)
+			}
+		})
+	}
+}
+
+func TestCreateAPIKeyStoresOnlyTheHash(t *testing.T) {
+	f := newAPIKeyFixture(t)
+	key := f.create(t, []string{"product:read"}, nil)
+
+	stored := f.keys.keys[1]
+	if stored.KeyHash == key || stored.KeyHash != hashOpaqueToken(key) {
+		t.Errorf("key hash = %q, want the hash of the key", stored.KeyHash)
+	}
+	if stored.Prefix != key[:apiKeyPrefixLength] {
+		t.Errorf("prefix = %q, want %q", stored.Prefix, key[:apiKeyPrefixLength])
+	}
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	f := newAPIKeyFixture(t)
+	key := f.create(t, []string{"product:read", "product:write"}, nil)
+
+	verified, err := f.service.VerifyAPIKey(context.Background(), key)
+	if err != nil {
+		t.Fatalf("VerifyAPIKey: %v", err)
+	}
+	if verified.UserID != "1" || len(verified.Roles) != 0 {
+		t.Errorf("verified = %+v, want user 1 without roles", verified)
+	}
+	if !slices.Equal(verified.Permissions, []string{"product:read", "product:write"}) {
+		t.Errorf("permissions = %v", verified.Permissions)
+	}
+	if f.keys.keys[1].LastUsedAt == nil {
+		t.Error("last use was not recorded")
+	}
+
+	// A scope the user lost is dropped from the key
+	f.authService.permissions[1] = []string{"product:read"}
+	verified, err = f.service.VerifyAPIKey(context.Background(), key)
+	if err != nil {
+		t.Fatalf("VerifyAPIKey: %v", err)
+	}
+	if !slices.Equal(verified.Permissions, []string{"product:read"}) {
+		t.Errorf("permissions = %v, want the scopes the user still holds", verified.Permissions)
+	}
+}
+
+func TestVerifyAPIKeyRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(f *apiKeyFixture, key string) string
+	}{
+		{
+			name: "expired key",
+			setup: func(f *apiKeyFixture, key string) string {
+				expired := time.Now().Add(-time.Minute)
+				f.keys.keys[1].ExpiresAt = &expired
+				return key
+			},
+		},
+		{
+			name: "revoked key",
+			setup: func(f *apiKeyFixture, key string) string {
+				if err := f.service.RevokeAPIKey(context.Background(), 1, 1); err != nil {
+					panic(err)
+				}
+				return key
+			},
+		},
+		{
+			name: "inactive user",
+			setup: func(f *apiKeyFixture, key string) string {
+				f.users.users[1].Status = string(constants.UserStatusInactive)
+				return key
+			},
+		},
+		{
+			name: "unknown key",
+			setup: func(_ *apiKeyFixture, key string) string {
+				return key + "x"
+			},
+		},
+		{
+			name: "access token",
+			setup: func(_ *apiKeyFixture, _ string) string {
+				return "eyJhbGciOiJSUzI1NiJ9.e30.signature"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAPIKeyFixture(t)
+			expiresAt := time.Now().Add(time.Hour)
+			key := tt.setup(f, f.create(t, []string{"product:read"}, &expiresAt))
+
+			verified, err := f.service.VerifyAPIKey(context.Background(), key)
+			if !errors.Is(err, rest.AuthenticationError{}) {
+				t.Errorf("VerifyAPIKey = %+v, %v, want %v", verified, err, rest.AuthenticationError{})
+			}
+		})
+	}
+}
+
+func TestRevokeAPIKeyOfAnotherUser(t *testing.T) {
+	f := newAPIKeyFixture(t)
+	f.create(t, []string{"product:read"}, nil)
+
+	if err := f.service.RevokeAPIKey(context.Background(), 2, 1); !errors.Is(err, customErr.ErrAPIKeyNotFound{}) {
+		t.Errorf("RevokeAPIKey error = %v, want %v", err, customErr.ErrAPIKeyNotFound{})
+	}
+	if f.keys.keys[1].RevokedAt != nil {
+		t.Error("key of another user was revoked")
+	}
+}
@@ -1,6 +1,12 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/hthinh24/go-store/internal/pkg/jwks"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
 	"github.com/hthinh24/go-store/services/identity/internal/middleware"
 	"strconv"
 	"time"
@@ -13,48 +19,182 @@ import (
 	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
 	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
 	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	"github.com/hthinh24/go-store/services/identity/internal/keys"
+	"github.com/hthinh24/go-store/services/identity/internal/mfa"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type authService struct {
-	logger         logger.Logger
-	userRepository identity.UserRepository
-	authRepository identity.AuthRepository
-	config         *config.AppConfig
+	logger                 logger.Logger
+	userRepository         identity.UserRepository
+	authRepository         identity.AuthRepository
+	refreshTokenRepository identity.RefreshTokenRepository
+	revocationRepository   identity.TokenRevocationRepository
+	mfaRepository          identity.MFARepository
+	challengeRepository    identity.MFAChallengeRepository
+	loginAttemptRepository identity.LoginAttemptRepository
+	auditLogRepository     identity.AuditLogRepository
+	permissionCache        identity.PermissionCacheRepository
+	keyManager             *keys.KeyManager
+	config                 *config.AppConfig
 }
 
-func NewAuthService(logger logger.Logger, userRepository identity.UserRepository, authRepository identity.AuthRepository, cfg *config.AppConfig) identity.AuthService {
+func NewAuthService(logger logger.Logger, userRepository identity.UserRepository, authRepository identity.AuthRepository,
+	refreshTokenRepository identity.RefreshTokenRepository, revocationRepository identity.TokenRevocationRepository,
+	mfaRepository identity.MFARepository, challengeRepository identity.MFAChallengeRepository,
+	loginAttemptRepository identity.LoginAttemptRepository, auditLogRepository identity.AuditLogRepository,
+	permissionCache identity.PermissionCacheRepository, keyManager *keys.KeyManager, cfg *config.AppConfig) identity.AuthService {
 	return &authService{
-		logger:         logger,
-		userRepository: userRepository,
-		authRepository: authRepository,
-		config:         cfg,
+		logger:                 logger,
+		userRepository:         userRepository,
+		authRepository:         authRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		revocationRepository:   revocationRepository,
+		mfaRepository:          mfaRepository,
+		challengeRepository:    challengeRepository,
+		loginAttemptRepository: loginAttemptRepository,
+		auditLogRepository:     auditLogRepository,
+		permissionCache:        permissionCache,
+		keyManager:             keyManager,
+		config:                 cfg,
 	}
 }
 
-func (a *authService) Login(request request.AuthRequest) (*response.AuthResponse, error) {
+func (a *authService) Login(ctx context.Context, request request.AuthRequest, clientIP string) (*response.AuthResponse, error) {
+	if err := a.checkLockout(ctx, request.Email, clientIP); err != nil {
+		return &response.AuthResponse{}, err
+	}
+
 	user, err := a.userRepository.FindUserByEmail(request.Email)
+	if err != nil && !errors.Is(err, customErr.ErrUserNotFound{}) {
+		return &response.AuthResponse{}, err
+	}
+
+	// An unknown email is checked against a dummy hash, so it fails exactly like a wrong
+	// password and takes as long. Logins never tell which emails exist.
+	passwordHash := dummyPasswordHash
+	if user != nil {
+		passwordHash = []byte(user.Password)
+	}
+	if bcrypt.CompareHashAndPassword(passwordHash, []byte(request.Password)) != nil || user == nil {
+		a.logger.Error("Invalid credentials for email:", request.Email)
+		return &response.AuthResponse{}, a.recordLoginFailure(ctx, request.Email, clientIP, user)
+	}
+
+	if err := a.loginAttemptRepository.Reset(ctx, accountSubject(request.Email)); err != nil {
+		return &response.AuthResponse{}, err
+	}
+
+	// Checked after the password, so the status of an account is never told to strangers
+	if user.Status != string(constants.UserStatusActive) {
+		a.logger.Warn("Login denied for inactive user with email:", request.Email)
+		return &response.AuthResponse{}, customErr.ErrUserNotActive{}
+	}
+
+	authResponse, err := a.AuthenticateUser(ctx, user)
 	if err != nil {
 		return &response.AuthResponse{}, err
 	}
 
-	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)) != nil {
-		a.logger.Error("Invalid password for user with email:", request.Email)
-		return &response.AuthResponse{}, rest.AuthenticationError{}
+	a.logger.Info("User password checked successfully with email:", request.Email)
+	return authResponse, nil
+}
+
+func (a *authService) AuthenticateUser(ctx context.Context, user *entity.User) (*response.AuthResponse, error) {
+	logger := a.logger.WithContext(ctx)
+
+	userMFA, err := a.mfaRepository.FindUserMFA(user.ID)
+	if err != nil && !errors.Is(err, customErr.ErrMFANotEnrolled{}) {
+		return nil, err
+	}
+	if err == nil && userMFA.IsConfirmed() {
+		logger.Info("MFA challenge issued for user ID:", user.ID)
+		return a.newMFAChallenge(ctx, user.ID, mfa.ChallengeVerify)
+	}
+
+	required, err := isMFARequired(a.authRepository, user.ID)
+	if err != nil {
+		return nil, err
 	}
+	if required {
+		logger.Info("MFA setup required for user ID:", user.ID)
+		return a.newMFAChallenge(ctx, user.ID, mfa.ChallengeSetup)
+	}
+
+	return a.IssueTokens(ctx, user)
+}
 
-	token, err := a.generateToken(user)
+func (a *authService) IssueTokens(ctx context.Context, user *entity.User) (*response.AuthResponse, error) {
+	token, err := a.generateToken(ctx, user)
 	if err != nil {
 		a.logger.Error("Error generating token for user:", user.Email, err)
-		return &response.AuthResponse{}, err
+		return nil, err
 	}
 
-	a.logger.Info("User logged in successfully with email:", request.Email)
-	return a.createAuthResponse(token)
+	// Every login starts a new refresh token family
+	refreshToken, refreshTokenEntity, err := a.newRefreshToken(user.ID, uuid.NewString())
+	if err != nil {
+		a.logger.Error("Error generating refresh token for user:", user.Email, err)
+		return nil, err
+	}
+	if err := a.refreshTokenRepository.CreateRefreshToken(refreshTokenEntity); err != nil {
+		return nil, err
+	}
+
+	return a.createAuthResponse(token, refreshToken)
+}
+
+func (a *authService) Refresh(ctx context.Context, request request.RefreshTokenRequest) (*response.AuthResponse, error) {
+	current, err := a.refreshTokenRepository.FindRefreshTokenByHash(hashOpaqueToken(request.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	// A revoked token is only presented again when a copy leaked, end the whole session
+	if current.RevokedAt != nil {
+		return nil, a.revokeReusedFamily(current)
+	}
+
+	if !current.IsActive(time.Now()) {
+		a.logger.Warn("Expired refresh token presented for user ID:", current.UserID)
+		return nil, customErr.ErrInvalidRefreshToken{}
+	}
+
+	user, err := a.userRepository.FindUserByID(current.UserID)
+	if err != nil {
+		return nil, customErr.ErrInvalidRefreshToken{}
+	}
+	if user.Status != string(constants.UserStatusActive) {
+		a.logger.Warn("Refresh denied for inactive user ID:", user.ID)
+		return nil, customErr.ErrUserNotActive{}
+	}
+
+	// Roles and permissions are read again, changes apply from the next refresh
+	token, err := a.generateToken(ctx, user)
+	if err != nil {
+		a.logger.Error("Error generating token for user:", user.Email, err)
+		return nil, err
+	}
+
+	refreshToken, next, err := a.newRefreshToken(user.ID, current.FamilyID)
+	if err != nil {
+		a.logger.Error("Error generating refresh token for user:", user.Email, err)
+		return nil, err
+	}
+
+	if err := a.refreshTokenRepository.RotateRefreshToken(current, next); err != nil {
+		if errors.Is(err, customErr.ErrRefreshTokenReused{}) {
+			return nil, a.revokeReusedFamily(current)
+		}
+		return nil, err
+	}
+
+	a.logger.Info("Refresh token rotated for user ID:", user.ID)
+	return a.createAuthResponse(token, refreshToken)
 }
 
-func (a *authService) Verify(token string) (*response.VerifyResponse, error) {
-	claims, err := a.validateToken(token)
+func (a *authService) Verify(ctx context.Context, token string) (*response.VerifyResponse, error) {
+	claims, err := a.validateToken(ctx, token)
 	if err != nil {
 		a.logger.Error("Failed to validate JWT token:", err)
 		return nil, rest.AuthenticationError{}
@@ -67,45 +207,143 @@ func (a *authService) Verify(token string) (*response.VerifyResponse, error) {
 	}, nil
 }
 
-func (a *authService) Logout(token string) error {
-	//TODO implement me
-	panic("implement me")
+func (a *authService) FindUserPermissions(ctx context.Context, userID int64) ([]string, error) {
+	roles, err := a.authRepository.FindAllUserRolesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.findPermissions(ctx, *roles)
 }
 
-func (a *authService) createAuthResponse(token string) (*response.AuthResponse, error) {
-	return &response.AuthResponse{Token: token}, nil
+func (a *authService) Logout(ctx context.Context, token string, request request.LogoutRequest) error {
+	logger := a.logger.WithContext(ctx)
+
+	claims, err := a.validateToken(ctx, token)
+	if err != nil {
+		logger.Error("Failed to validate JWT token:", err)
+		return rest.AuthenticationError{}
+	}
+
+	// The token is denylisted until it would have expired anyway
+	if ttl := time.Until(claims.ExpiresAt.Time); claims.ID != "" && ttl > 0 {
+		if err := a.revocationRepository.RevokeToken(ctx, claims.ID, ttl); err != nil {
+			return err
+		}
+	}
+
+	if request.RefreshToken != "" {
+		refreshToken, err := a.refreshTokenRepository.FindRefreshTokenByHash(hashOpaqueToken(request.RefreshToken))
+		if err != nil {
+			return err
+		}
+		// Never let a user end the session of another user
+		if refreshToken.UserID != claims.UserID {
+			logger.Warn("Refresh token of another user presented on logout, user ID:", claims.UserID)
+			return customErr.ErrInvalidRefreshToken{}
+		}
+		if err := a.refreshTokenRepository.RevokeRefreshTokenFamily(refreshToken.FamilyID); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("User logged out, user ID:", claims.UserID)
+	return nil
+}
+
+func (a *authService) LogoutAll(ctx context.Context, userID int64) error {
+	logger := a.logger.WithContext(ctx)
+
+	// Bumping the generation rejects every access token issued so far
+	if _, err := a.revocationRepository.IncrementTokenGeneration(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := a.refreshTokenRepository.RevokeAllUserRefreshTokens(userID); err != nil {
+		return err
+	}
+
+	logger.Info("User logged out of all sessions, user ID:", userID)
+	return nil
+}
+
+func (a *authService) KeySet() (jwks.Set, error) {
+	return a.keyManager.KeySet()
+}
+
+func (a *authService) createAuthResponse(token string, refreshToken string) (*response.AuthResponse, error) {
+	return &response.AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(a.config.GetJWTExpiresIn().Seconds()),
+	}, nil
+}
+
+// newMFAChallenge stores a challenge for the second login step, the token is only stored hashed
+func (a *authService) newMFAChallenge(ctx context.Context, userID int64, purpose mfa.ChallengePurpose) (*response.AuthResponse, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &mfa.Challenge{UserID: userID, Purpose: purpose}
+	if err := a.challengeRepository.SaveChallenge(ctx, hashOpaqueToken(token), challenge, a.config.MFA.GetChallengeTTL()); err != nil {
+		return nil, err
+	}
+
+	return &response.AuthResponse{
+		MFARequired:      purpose == mfa.ChallengeVerify,
+		MFASetupRequired: purpose == mfa.ChallengeSetup,
+		MFAToken:         token,
+	}, nil
+}
+
+// revokeReusedFamily revokes every token rotated from the same login as the reused one
+func (a *authService) revokeReusedFamily(reused *entity.RefreshToken) error {
+	a.logger.Warn("Refresh token reuse detected, revoking token family:", reused.FamilyID, ", user ID:", reused.UserID)
+
+	if err := a.refreshTokenRepository.RevokeRefreshTokenFamily(reused.FamilyID); err != nil {
+		return err
+	}
+	return customErr.ErrRefreshTokenReused{}
+}
+
+// newRefreshToken returns an opaque refresh token and the entity storing its hash
+func (a *authService) newRefreshToken(userID int64, familyID string) (string, *entity.RefreshToken, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &entity.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashOpaqueToken(token),
+		ExpiresAt: time.Now().Add(a.config.GetJWTRefreshExpiresIn()),
+	}, nil
 }
 
-func (a *authService) generateToken(user *entity.User) (string, error) {
+func (a *authService) generateToken(ctx context.Context, user *entity.User) (string, error) {
 	roles, err := a.authRepository.FindAllUserRolesByUserID(user.ID)
 	if err != nil {
 		a.logger.Error("Error fetching user roles for user ID %d: %v", user.ID, err)
 		return "", err
 	}
 
-	//Extract role names and collect all permissions
-	roleIDs := make([]int64, len(*roles))
 	roleNames := make([]string, len(*roles))
 	for i, role := range *roles {
-		roleIDs[i] = role.ID
 		roleNames[i] = role.Name
 	}
 
-	permissionList, err := a.authRepository.FindAllPermissionsByRoleIDs(roleIDs)
+	permissions, err := a.findPermissions(ctx, *roles)
 	if err != nil {
 		return "", err
 	}
 
-	// Create a set to avoid duplicate permissions
-	permissionSet := make(map[string]bool)
-	for _, permission := range *permissionList {
-		permissionSet[permission.Name] = true
-	}
-
-	// Convert permission set to slice
-	permissions := make([]string, 0, len(permissionSet))
-	for permission := range permissionSet {
-		permissions = append(permissions, permission)
+	generation, err := a.revocationRepository.GetTokenGeneration(ctx, user.ID)
+	if err != nil {
+		return "", err
 	}
 
 	// Create JWT claims with configurable expiration
@@ -114,30 +352,78 @@ func (a *authService) generateToken(user *entity.User) (string, error) {
 		Email:       user.Email,
 		Roles:       roleNames,
 		Permissions: permissions,
+		Generation:  generation,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.config.GetJWTExpiresIn())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(a.config.GetJWTSecret()))
+	return a.keyManager.Sign(claims)
+}
+
+// rolePermissionsCacheTTL bounds how long a missed invalidation can last, role changes
+// invalidate the cache themselves
+const rolePermissionsCacheTTL = time.Hour
+
+// findPermissions returns the distinct permission names of the roles, read through the cache
+// of each role so a refresh usually skips the permission queries
+func (a *authService) findPermissions(ctx context.Context, roles []entity.Role) ([]string, error) {
+	logger := a.logger.WithContext(ctx)
+
+	permissionSet := make(map[string]bool)
+	for _, role := range roles {
+		names, cached, err := a.permissionCache.GetRolePermissions(ctx, role.ID)
+		if err != nil {
+			// The cache only saves queries, tokens are issued from the database without it
+			logger.Warn("Permission cache unavailable, error:", err)
+		}
+
+		if !cached {
+			permissionList, err := a.authRepository.FindAllPermissionsByRoleIDs([]int64{role.ID})
+			if err != nil {
+				return nil, err
+			}
+
+			names = make([]string, len(*permissionList))
+			for i, permission := range *permissionList {
+				names[i] = permission.Name
+			}
+			if err := a.permissionCache.SetRolePermissions(ctx, role.ID, names, rolePermissionsCacheTTL); err != nil {
+				logger.Warn("Failed to cache permissions of role ID:", role.ID, "Error:", err)
+			}
+		}
+
+		for _, name := range names {
+			permissionSet[name] = true
+		}
+	}
+
+	permissions := make([]string, 0, len(permissionSet))
+	for permission := range permissionSet {
+		permissions = append(permissions, permission)
+	}
+	return permissions, nil
 }
 
-func (a *authService) validateToken(tokenString string) (*middleware.JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &middleware.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(a.config.GetJWTSecret()), nil
-	})
+func (a *authService) validateToken(ctx context.Context, tokenString string) (*middleware.JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &middleware.JWTClaims{}, a.keyManager.Keyfunc(),
+		jwt.WithValidMethods(jwks.ValidMethods()))
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert claims to our custom JWTClaims type & check validity
 	claims, ok := token.Claims.(*middleware.JWTClaims)
-	if ok {
-		return claims, nil
+	if !ok {
+		return nil, jwt.ErrTokenMalformed
+	}
+
+	if err := middleware.CheckRevocation(ctx, a.revocationRepository, claims); err != nil {
+		return nil, err
 	}
 
-	return nil, jwt.ErrTokenMalformed
+	return claims, nil
 }
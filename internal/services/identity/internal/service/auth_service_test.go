@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pkgconfig "github.com/hthinh24/go-store/internal/pkg/config"
+	jwtConfig "github.com/hthinh24/go-store/internal/pkg/config/jwt"
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"github.com/hthinh24/go-store/services/identity/internal/keys"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// memoryRefreshTokenRepository keeps refresh tokens in memory and hands out copies like the database
+type memoryRefreshTokenRepository struct {
+	tokens []*entity.RefreshToken
+	// beforeRotate runs once inside the next rotation, before the current token is revoked
+	beforeRotate func()
+}
+
+func (m *memoryRefreshTokenRepository) CreateRefreshToken(token *entity.RefreshToken) error {
+	token.ID = int64(len(m.tokens) + 1)
+	stored := *token
+	m.tokens = append(m.tokens, &stored)
+	return nil
+}
+
+func (m *memoryRefreshTokenRepository) FindRefreshTokenByHash(tokenHash string) (*entity.RefreshToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, customErr.ErrInvalidRefreshToken{}
+}
+
+func (m *memoryRefreshTokenRepository) RotateRefreshToken(current *entity.RefreshToken, next *entity.RefreshToken) error {
+	if hook := m.beforeRotate; hook != nil {
+		m.beforeRotate = nil
+		hook()
+	}
+
+	stored := m.tokens[current.ID-1]
+	if stored.RevokedAt != nil {
+		return customErr.ErrRefreshTokenReused{}
+	}
+	now := time.Now()
+	stored.RevokedAt = &now
+	return m.CreateRefreshToken(next)
+}
+
+func (m *memoryRefreshTokenRepository) RevokeRefreshTokenFamily(familyID string) error {
+	now := time.Now()
+	for _, token := range m.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *memoryRefreshTokenRepository) RevokeAllUserRefreshTokens(userID int64) error {
+	now := time.Now()
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// active returns the tokens of the family that can still be exchanged
+func (m *memoryRefreshTokenRepository) active(familyID string) int {
+	count := 0
+	for _, token := range m.tokens {
+		if token.FamilyID == familyID && token.IsActive(time.Now()) {
+			count++
+		}
+	}
+	return count
+}
+
+// roleAuthRepository gives every user the same roles, only the lookups of token issuing are implemented
+type roleAuthRepository struct {
+	identity.AuthRepository
+	roles []entity.Role
+}
+
+func (r *roleAuthRepository) FindAllUserRolesByUserID(_ int64) (*[]entity.Role, error) {
+	return &r.roles, nil
+}
+
+func (r *roleAuthRepository) FindAllPermissionsByRoleIDs(_ []int64) (*[]entity.Permission, error) {
+	return &[]entity.Permission{{Name: "product:read"}}, nil
+}
+
+// noRevocationRepository never revokes tokens
+type noRevocationRepository struct {
+	identity.TokenRevocationRepository
+}
+
+func (noRevocationRepository) GetTokenGeneration(_ context.Context, _ int64) (int64, error) {
+	return 0, nil
+}
+
+// noPermissionCache never caches permissions
+type noPermissionCache struct{}
+
+func (noPermissionCache) GetRolePermissions(_ context.Context, _ int64) ([]string, bool, error) {
+	return nil, false, nil
+}
+
+func (noPermissionCache) SetRolePermissions(_ context.Context, _ int64, _ []string, _ time.Duration) error {
+	return nil
+}
+
+func (noPermissionCache) InvalidateRoles(_ context.Context, _ ...int64) error {
+	return nil
+}
+
+// noMFARepository has no user enrolled
+type noMFARepository struct {
+	identity.MFARepository
+}
+
+func (noMFARepository) FindUserMFA(_ int64) (*entity.UserMFA, error) {
+	return nil, customErr.ErrMFANotEnrolled{}
+}
+
+// memoryLoginAttemptRepository counts failures and lockouts in memory, windows never expire
+type memoryLoginAttemptRepository struct {
+	failures map[string]int64
+	lockouts map[string]time.Duration
+}
+
+func (m *memoryLoginAttemptRepository) GetLockout(_ context.Context, subject string) (time.Duration, error) {
+	return m.lockouts[subject], nil
+}
+
+func (m *memoryLoginAttemptRepository) RecordFailure(_ context.Context, subject string, _ time.Duration) (int64, error) {
+	m.failures[subject]++
+	return m.failures[subject], nil
+}
+
+func (m *memoryLoginAttemptRepository) Lock(_ context.Context, subject string, duration time.Duration) error {
+	m.lockouts[subject] = duration
+	delete(m.failures, subject)
+	return nil
+}
+
+func (m *memoryLoginAttemptRepository) Reset(_ context.Context, subject string) error {
+	delete(m.lockouts, subject)
+	delete(m.failures, subject)
+	return nil
+}
+
+const testPassword = "correct-password"
+
+type authFixture struct {
+	service       identity.AuthService
+	users         *memoryUserRepository
+	refreshTokens *memoryRefreshTokenRepository
+	loginAttempts *memoryLoginAttemptRepository
+	auditLogs     *memoryAuditLogRepository
+	config        *config.AppConfig
+	user          *entity.User
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+
+	cfg := &config.AppConfig{Config: &pkgconfig.Config{JWT: jwtConfig.JWT{
+		Expiration:        "15m",
+		RefreshExpiration: "168h",
+		SigningKey:        jwtConfig.Key{ID: "test", Path: filepath.Join(t.TempDir(), "signing.pem")},
+	}}}
+	cfg.LoginProtection.SetDefaults()
+	cfg.LoginProtection.BaseDelay = "1ms"
+	cfg.LoginProtection.MaxDelay = "4ms"
+
+	keyManager, err := keys.LoadKeyManager(cfg.JWT, true)
+	if err != nil {
+		t.Fatalf("LoadKeyManager: %v", err)
+	}
+
+	password, _ := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	users := &memoryUserRepository{users: make(map[int64]*entity.User)}
+	user := &entity.User{Email: "user@example.com", Password: string(password), Status: string(constants.UserStatusActive)}
+	_ = users.CreateUser(user)
+
+	refreshTokens := &memoryRefreshTokenRepository{}
+	loginAttempts := &memoryLoginAttemptRepository{failures: make(map[string]int64), lockouts: make(map[string]time.Duration)}
+	auditLogs := &memoryAuditLogRepository{}
+	authRepository := &roleAuthRepository{roles: []entity.Role{{ID: 1, Name: "customer"}}}
+
+	return &authFixture{
+		service: NewAuthService(logger.NewAppLogger("development"), users, authRepository, refreshTokens,
+			noRevocationRepository{}, noMFARepository{}, nil, loginAttempts, auditLogs, noPermissionCache{}, keyManager, cfg),
+		users:         users,
+		refreshTokens: refreshTokens,
+		loginAttempts: loginAttempts,
+		auditLogs:     auditLogs,
+		config:        cfg,
+		user:          user,
+	}
+}
+
+// refresh exchanges the refresh token and returns the new one
+func (f *authFixture) refresh(t *testing.T, refreshToken string) string {
+	t.Helper()
+
+	authResponse, err := f.service.Refresh(context.Background(), request.RefreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if authResponse.Token == "" || authResponse.RefreshToken == "" || authResponse.RefreshToken == refreshToken {
+		t.Fatalf("Refresh response = %+v, want new tokens", authResponse)
+	}
+	return authResponse.RefreshToken
+}
+
+func (f *authFixture) issue(t *testing.T) string {
+	t.Helper()
+
+	authResponse, err := f.service.IssueTokens(context.Background(), f.user)
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	return authResponse.RefreshToken
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	f := newAuthFixture(t)
+	first := f.issue(t)
+
+	second := f.refresh(t, first)
+	third := f.refresh(t, second)
+
+	familyID := f.refreshTokens.tokens[0].FamilyID
+	for _, token := range f.refreshTokens.tokens {
+		if token.FamilyID != familyID {
+			t.Errorf("rotated token family = %q, want %q", token.FamilyID, familyID)
+		}
+		if token.TokenHash == first || token.TokenHash == second || token.TokenHash == third {
+			t.Error("refresh token stored in plain text")
+		}
+	}
+	if active := f.refreshTokens.active(familyID); active != 1 {
+		t.Errorf("active tokens = %d, want 1", active)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	f := newAuthFixture(t)
+	other := f.issue(t)
+	first := f.issue(t)
+	second := f.refresh(t, first)
+
+	_, err := f.service.Refresh(context.Background(), request.RefreshTokenRequest{RefreshToken: first})
+	if !errors.Is(err, customErr.ErrRefreshTokenReused{}) {
+		t.Fatalf("reused Refresh error = %v, want %v", err, customErr.ErrRefreshTokenReused{})
+	}
+
+	// The token rotated from the reused one dies with the family, other logins keep working
+	_, err = f.service.Refresh(context.Background(), request.RefreshTokenRequest{RefreshToken: second})
+	if !errors.Is(err, customErr.ErrRefreshTokenReused{}) {
+		t.Errorf("Refresh of the family error = %v, want %v", err, customErr.ErrRefreshTokenReused{})
+	}
+	f.refresh(t, other)
+}
+
+func TestRefreshConcurrentReuseRevokesFamily(t *testing.T) {
+	f := newAuthFixture(t)
+	first := f.issue(t)
+
+	// Another request rotates the same token between the lookup and the rotation
+	var rotated string
+	f.refreshTokens.beforeRotate = func() { rotated = f.refresh(t, first) }
+
+	_, err := f.service.Refresh(context.Background(), request.RefreshTokenRequest{RefreshToken: first})
+	if !errors.Is(err, customErr.ErrRefreshTokenReused{}) {
+		t.Fatalf("concurrent Refresh error = %v, want %v", err, customErr.ErrRefreshTokenReused{})
+	}
+	if active := f.refreshTokens.active(f.refreshTokens.tokens[0].FamilyID); active != 0 {
+		t.Errorf("active tokens = %d, want the family revoked", active)
+	}
+
+	_, err = f.service.Refresh(context.Background(), request.RefreshTokenRequest{RefreshToken: rotated})
+	if !errors.Is(err, customErr.ErrRefreshTokenReused{}) {
+		t.Errorf("Refresh of the winning rotation error = %v, want %v", err, customErr.ErrRefreshTokenReused{})
+	}
+}
+
+func TestRefreshRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(f *authFixture, token string) string
+		want    error
+	}{
+		{
+			name:    "unknown token",
+			prepare: func(_ *authFixture, _ string) string { return "unknown" },
+			want:    customErr.ErrInvalidRefreshToken{},
+		},
+		{
+			name: "expired token",
+			prepare: func(f *authFixture, token string) string {
+				f.refreshTokens.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+				return token
+			},
+			want: customErr.ErrInvalidRefreshToken{},
+		},
+		{
+			name: "inactive user",
+			prepare: func(f *authFixture, token string) string {
+				f.user.Status = string(constants.UserStatusInactive)
+				return token
+			},
+			want: customErr.ErrUserNotActive{},
+		},
+		{
+			name: "deleted user",
+			prepare: func(f *authFixture, token string) string {
+				delete(f.users.users, f.user.ID)
+				return token
+			},
+			want: customErr.ErrInvalidRefreshToken{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			token := tt.prepare(f, f.issue(t))
+
+			_, err := f.service.Refresh(context.Background(), request.RefreshTokenRequest{RefreshToken: token})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Refresh error = %v, want %v", err, tt.want)
+			}
+			if len(f.refreshTokens.tokens) != 1 {
+				t.Errorf("stored tokens = %d, want no rotation", len(f.refreshTokens.tokens))
+			}
+		})
+	}
+}
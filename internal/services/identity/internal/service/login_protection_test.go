@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+const testClientIP = "203.0.113.7"
+
+func (f *authFixture) login(email string, password string, clientIP string) error {
+	_, err := f.service.Login(context.Background(), request.AuthRequest{Email: email, Password: password}, clientIP)
+	return err
+}
+
+func TestLoginLocksAccountOut(t *testing.T) {
+	f := newAuthFixture(t)
+	locked := customErr.ErrTooManyLoginAttempts{RetryAfter: 15 * time.Minute}
+
+	for i := range f.config.LoginProtection.MaxAccountFailures {
+		if err := f.login("user@example.com", "wrong-password", testClientIP); !errors.Is(err, rest.AuthenticationError{}) {
+			t.Fatalf("failure %d error = %v, want %v", i+1, err, rest.AuthenticationError{})
+		}
+	}
+	if !slices.Equal(f.auditLogs.events, []entity.AuditEvent{entity.AuditEventAccountLocked}) {
+		t.Errorf("audit events = %v, want %v", f.auditLogs.events, entity.AuditEventAccountLocked)
+	}
+
+	// The right password does not help while locked out, under another spelling of the email either
+	if err := f.login("user@example.com", testPassword, testClientIP); !errors.Is(err, locked) {
+		t.Errorf("locked out login error = %v, want %v", err, locked)
+	}
+	if err := f.login(" USER@example.com", testPassword, "198.51.100.1"); !errors.Is(err, locked) {
+		t.Errorf("locked out login from another IP error = %v, want %v", err, locked)
+	}
+
+	if err := f.service.UnlockUser(context.Background(), f.user.ID, 99); err != nil {
+		t.Fatalf("UnlockUser: %v", err)
+	}
+	if err := f.login("user@example.com", testPassword, testClientIP); err != nil {
+		t.Errorf("login after unlock: %v", err)
+	}
+}
+
+func TestLoginLocksUnknownEmailOut(t *testing.T) {
+	f := newAuthFixture(t)
+	f.config.LoginProtection.MaxAccountFailures = 2
+
+	for range 2 {
+		if err := f.login("nobody@example.com", "any-password", testClientIP); !errors.Is(err, rest.AuthenticationError{}) {
+			t.Fatalf("login error = %v, want %v", err, rest.AuthenticationError{})
+		}
+	}
+
+	// Unknown emails lock out like existing ones, so lockouts do not tell which emails exist
+	err := f.login("nobody@example.com", "any-password", testClientIP)
+	if !errors.As(err, &customErr.ErrTooManyLoginAttempts{}) {
+		t.Errorf("login error = %v, want %v", err, customErr.ErrTooManyLoginAttempts{})
+	}
+}
+
+func TestLoginSuccessResetsFailures(t *testing.T) {
+	f := newAuthFixture(t)
+	maxFailures := f.config.LoginProtection.MaxAccountFailures
+
+	for range 2 {
+		for range maxFailures - 1 {
+			_ = f.login("user@example.com", "wrong-password", testClientIP)
+		}
+		if err := f.login("user@example.com", testPassword, testClientIP); err != nil {
+			t.Fatalf("login: %v", err)
+		}
+	}
+	if len(f.auditLogs.events) != 0 {
+		t.Errorf("audit events = %v, want no lockout", f.auditLogs.events)
+	}
+}
+
+func TestLoginLocksIPOut(t *testing.T) {
+	f := newAuthFixture(t)
+	f.config.LoginProtection.MaxIPFailures = 3
+
+	for i := range 3 {
+		_ = f.login(fmt.Sprintf("guess%d@example.com", i), "any-password", testClientIP)
+	}
+	if !slices.Equal(f.auditLogs.events, []entity.AuditEvent{entity.AuditEventIPLocked}) {
+		t.Errorf("audit events = %v, want %v", f.auditLogs.events, entity.AuditEventIPLocked)
+	}
+
+	if err := f.login("user@example.com", testPassword, testClientIP); !errors.As(err, &customErr.ErrTooManyLoginAttempts{}) {
+		t.Errorf("login from the locked IP error = %v, want %v", err, customErr.ErrTooManyLoginAttempts{})
+	}
+	if err := f.login("user@example.com", testPassword, "198.51.100.1"); err != nil {
+		t.Errorf("login from another IP: %v", err)
+	}
+}
+
+func TestLoginDelaysRepeatedFailures(t *testing.T) {
+	f := newAuthFixture(t)
+	f.config.LoginProtection.BaseDelay = "1h"
+	f.config.LoginProtection.MaxDelay = "1h"
+	const timeout = 100 * time.Millisecond
+
+	for i := range f.config.LoginProtection.DelayAfter + 1 {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		start := time.Now()
+		_, err := f.service.Login(ctx, request.AuthRequest{Email: "user@example.com", Password: "wrong-password"}, testClientIP)
+		elapsed := time.Since(start)
+		cancel()
+
+		if !errors.Is(err, rest.AuthenticationError{}) {
+			t.Fatalf("failure %d error = %v, want %v", i+1, err, rest.AuthenticationError{})
+		}
+		// The answer is held back until the request gives up once the free failures are used
+		if delayed := elapsed >= timeout; delayed != (i == f.config.LoginProtection.DelayAfter) {
+			t.Errorf("failure %d took %v, delayed = %v", i+1, elapsed, delayed)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+type merchantService struct {
+	logger                        logger.Logger
+	merchantApplicationRepository identity.MerchantApplicationRepository
+	userRepository                identity.UserRepository
+	authRepository                identity.AuthRepository
+}
+
+func NewMerchantService(logger logger.Logger, merchantApplicationRepository identity.MerchantApplicationRepository,
+	userRepository identity.UserRepository, authRepository identity.AuthRepository) identity.MerchantService {
+	return &merchantService{
+		logger:                        logger,
+		merchantApplicationRepository: merchantApplicationRepository,
+		userRepository:                userRepository,
+		authRepository:                authRepository,
+	}
+}
+
+func (m *merchantService) SubmitApplication(ctx context.Context, userID int64, data request.MerchantApplicationRequest) (*response.MerchantApplicationResponse, error) {
+	logger := m.logger.WithContext(ctx)
+
+	user, err := m.userRepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != string(constants.UserStatusActive) {
+		logger.Warn("Merchant application denied for inactive user ID:", userID)
+		return nil, customErr.ErrUserNotActive{}
+	}
+
+	roles, err := m.authRepository.FindAllUserRolesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range *roles {
+		if role.Name == string(constants.RoleMerchant) {
+			return nil, customErr.ErrAlreadyMerchant{}
+		}
+	}
+
+	latest, err := m.merchantApplicationRepository.FindLatestMerchantApplicationByUserID(userID)
+	if err != nil && !errors.Is(err, customErr.ErrMerchantApplicationNotFound{}) {
+		return nil, err
+	}
+	if latest != nil && latest.Status.IsOpen() {
+		return nil, customErr.ErrOpenMerchantApplication{}
+	}
+
+	application := &entity.MerchantApplication{
+		UserID:          userID,
+		BusinessName:    data.BusinessName,
+		TaxID:           data.TaxID,
+		ContactName:     data.ContactName,
+		ContactEmail:    data.ContactEmail,
+		ContactPhone:    data.ContactPhone,
+		BusinessAddress: data.BusinessAddress,
+		Status:          constants.MerchantApplicationSubmitted,
+	}
+	auditLog := newApplicationAuditLog(entity.AuditEventMerchantApplicationSubmitted, userID, application)
+	if err := m.merchantApplicationRepository.CreateMerchantApplication(application, auditLog); err != nil {
+		return nil, err
+	}
+
+	m.logStatus(ctx, application)
+	return createMerchantApplicationResponse(application), nil
+}
+
+func (m *merchantService) GetUserApplication(ctx context.Context, userID int64) (*response.MerchantApplicationResponse, error) {
+	application, err := m.merchantApplicationRepository.FindLatestMerchantApplicationByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return createMerchantApplicationResponse(application), nil
+}
+
+func (m *merchantService) GetApplications(ctx context.Context, data request.ListMerchantApplicationsRequest) (*rest.PageResponse, error) {
+	paging := rest.NewPaging(data.PageSize, data.PageNumber)
+
+	applications, total, err := m.merchantApplicationRepository.FindMerchantApplications(
+		constants.MerchantApplicationStatus(data.Status), paging.PageSize, paging.PageSize*paging.PageNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	applicationResponses := make([]response.MerchantApplicationResponse, len(*applications))
+	for i, application := range *applications {
+		applicationResponses[i] = *createMerchantApplicationResponse(&application)
+	}
+	return rest.NewPageResponse(paging, int(total), applicationResponses), nil
+}
+
+func (m *merchantService) GetApplication(ctx context.Context, id int64) (*response.MerchantApplicationResponse, error) {
+	application, err := m.merchantApplicationRepository.FindMerchantApplicationByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return createMerchantApplicationResponse(application), nil
+}
+
+func (m *merchantService) StartReview(ctx context.Context, id int64, adminID int64) (*response.MerchantApplicationResponse, error) {
+	application, from, err := m.transition(id, constants.MerchantApplicationUnderReview)
+	if err != nil {
+		return nil, err
+	}
+
+	application.ReviewerID = &adminID
+	auditLog := newApplicationAuditLog(entity.AuditEventMerchantApplicationUnderReview, adminID, application)
+	if err := m.merchantApplicationRepository.UpdateMerchantApplicationStatus(application, from, auditLog); err != nil {
+		return nil, err
+	}
+
+	m.logStatus(ctx, application)
+	return createMerchantApplicationResponse(application), nil
+}
+
+func (m *merchantService) DecideApplication(ctx context.Context, id int64, data request.MerchantApplicationDecisionRequest, adminID int64) (*response.MerchantApplicationResponse, error) {
+	status := constants.MerchantApplicationStatus(data.Status)
+	application, from, err := m.transition(id, status)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	application.ReviewerID = &adminID
+	application.DecisionReason = data.Reason
+	application.DecidedAt = &now
+
+	if status == constants.MerchantApplicationApproved {
+		// The role takes effect with the next token refresh of the user
+		role, err := m.authRepository.FindRoleByName(string(constants.RoleMerchant))
+		if err != nil {
+			return nil, err
+		}
+		auditLog := newApplicationAuditLog(entity.AuditEventMerchantApplicationApproved, adminID, application)
+		if err := m.merchantApplicationRepository.ApproveMerchantApplication(application, from, role.ID, auditLog); err != nil {
+			return nil, err
+		}
+	} else {
+		auditLog := newApplicationAuditLog(entity.AuditEventMerchantApplicationRejected, adminID, application)
+		if err := m.merchantApplicationRepository.UpdateMerchantApplicationStatus(application, from, auditLog); err != nil {
+			return nil, err
+		}
+	}
+
+	m.logStatus(ctx, application)
+	return createMerchantApplicationResponse(application), nil
+}
+
+// transition loads the application and moves it to the status, returning the status it came from
+func (m *merchantService) transition(id int64, to constants.MerchantApplicationStatus) (*entity.MerchantApplication, constants.MerchantApplicationStatus, error) {
+	application, err := m.merchantApplicationRepository.FindMerchantApplicationByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	from := application.Status
+	if !from.CanTransition(to) {
+		return nil, "", customErr.ErrInvalidApplicationTransition{From: string(from), To: string(to)}
+	}
+
+	application.Status = to
+	return application, from, nil
+}
+
+// newApplicationAuditLog returns the audit log of a status change, saved with the change itself.
+// actorID is the applicant for submissions and the admin otherwise.
+func newApplicationAuditLog(event entity.AuditEvent, actorID int64, application *entity.MerchantApplication) *entity.AuditLog {
+	return &entity.AuditLog{
+		Event:   event,
+		UserID:  &application.UserID,
+		ActorID: &actorID,
+	}
+}
+
+func (m *merchantService) logStatus(ctx context.Context, application *entity.MerchantApplication) {
+	m.logger.WithContext(ctx).Info("Merchant application ID:", application.ID, "of user ID:", application.UserID, "is now:", application.Status)
+}
+
+func createMerchantApplicationResponse(application *entity.MerchantApplication) *response.MerchantApplicationResponse {
+	return &response.MerchantApplicationResponse{
+		ID:              application.ID,
+		UserID:          application.UserID,
+		BusinessName:    application.BusinessName,
+		TaxID:           application.TaxID,
+		ContactName:     application.ContactName,
+		ContactEmail:    application.ContactEmail,
+		ContactPhone:    application.ContactPhone,
+		BusinessAddress: application.BusinessAddress,
+		Status:          string(application.Status),
+		ReviewerID:      application.ReviewerID,
+		DecisionReason:  application.DecisionReason,
+		DecidedAt:       application.DecidedAt,
+		CreatedAt:       application.CreatedAt,
+		UpdatedAt:       application.UpdatedAt,
+	}
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"github.com/hthinh24/go-store/services/identity/internal/mfa"
+)
+
+type mfaService struct {
+	logger              logger.Logger
+	mfaRepository       identity.MFARepository
+	challengeRepository identity.MFAChallengeRepository
+	userRepository      identity.UserRepository
+	authRepository      identity.AuthRepository
+	auditLogRepository  identity.AuditLogRepository
+	authService         identity.AuthService
+	cipher              *mfa.Cipher
+	config              *config.AppConfig
+}
+
+func NewMFAService(logger logger.Logger, mfaRepository identity.MFARepository, challengeRepository identity.MFAChallengeRepository,
+	userRepository identity.UserRepository, authRepository identity.AuthRepository,
+	auditLogRepository identity.AuditLogRepository, authService identity.AuthService,
+	cipher *mfa.Cipher, cfg *config.AppConfig) identity.MFAService {
+	return &mfaService{
+		logger:              logger,
+		mfaRepository:       mfaRepository,
+		challengeRepository: challengeRepository,
+		userRepository:      userRepository,
+		authRepository:      authRepository,
+		auditLogRepository:  auditLogRepository,
+		authService:         authService,
+		cipher:              cipher,
+		config:              cfg,
+	}
+}
+
+func (m *mfaService) Enroll(ctx context.Context, userID int64) (*response.MFAEnrollmentResponse, error) {
+	logger := m.logger.WithContext(ctx)
+
+	user, err := m.userRepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		logger.Error("Error generating MFA secret:", err)
+		return nil, err
+	}
+	encrypted, err := m.cipher.Encrypt(secret)
+	if err != nil {
+		logger.Error("Error encrypting MFA secret:", err)
+		return nil, err
+	}
+
+	if err := m.mfaRepository.SaveUserMFA(&entity.UserMFA{UserID: user.ID, Secret: encrypted}); err != nil {
+		return nil, err
+	}
+
+	logger.Info("MFA enrollment started for user ID:", user.ID)
+	return &response.MFAEnrollmentResponse{
+		Secret: secret,
+		URI:    mfa.KeyURI(m.config.MFA.Issuer, user.Email, secret),
+	}, nil
+}
+
+func (m *mfaService) ConfirmEnrollment(ctx context.Context, userID int64, request request.MFAConfirmRequest) (*response.MFARecoveryCodesResponse, error) {
+	codes, err := m.confirm(ctx, userID, request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (m *mfaService) Disable(ctx context.Context, userID int64, request request.MFACodeRequest) error {
+	logger := m.logger.WithContext(ctx)
+
+	required, err := isMFARequired(m.authRepository, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		logger.Warn("MFA disable denied, a role requires it for user ID:", userID)
+		return customErr.ErrMFARequired{}
+	}
+
+	userMFA, err := m.findConfirmedMFA(userID)
+	if err != nil {
+		return err
+	}
+	if err := m.checkCode(ctx, userMFA, request); err != nil {
+		return err
+	}
+
+	if err := m.mfaRepository.DeleteUserMFA(userID); err != nil {
+		return err
+	}
+
+	logger.Info("MFA disabled for user ID:", userID)
+	return nil
+}
+
+func (m *mfaService) Verify(ctx context.Context, request request.MFAChallengeRequest) (*response.AuthResponse, error) {
+	key := hashOpaqueToken(request.MFAToken)
+	challenge, err := m.findChallenge(ctx, key, mfa.ChallengeVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	userMFA, err := m.findConfirmedMFA(challenge.UserID)
+	if err != nil {
+		// MFA was disabled since the password was checked, the login has to start over
+		return nil, customErr.ErrInvalidMFAChallenge{}
+	}
+	if err := m.checkCode(ctx, userMFA, request.MFACodeRequest); err != nil {
+		return nil, m.failAttempt(ctx, key, challenge, err)
+	}
+
+	return m.completeLogin(ctx, key, challenge)
+}
+
+func (m *mfaService) StartSetup(ctx context.Context, request request.MFASetupRequest) (*response.MFAEnrollmentResponse, error) {
+	challenge, err := m.findChallenge(ctx, hashOpaqueToken(request.MFAToken), mfa.ChallengeSetup)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Enroll(ctx, challenge.UserID)
+}
+
+func (m *mfaService) CompleteSetup(ctx context.Context, request request.MFASetupConfirmRequest) (*response.MFASetupResponse, error) {
+	key := hashOpaqueToken(request.MFAToken)
+	challenge, err := m.findChallenge(ctx, key, mfa.ChallengeSetup)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := m.confirm(ctx, challenge.UserID, request.Code)
+	if err != nil {
+		return nil, m.failAttempt(ctx, key, challenge, err)
+	}
+
+	authResponse, err := m.completeLogin(ctx, key, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.MFASetupResponse{AuthResponse: *authResponse, RecoveryCodes: codes}, nil
+}
+
+func (m *mfaService) SetRoleMFARequired(ctx context.Context, roleName string, required bool, adminID int64) error {
+	if err := m.authRepository.UpdateRoleMFARequired(roleName, required); err != nil {
+		return err
+	}
+
+	// Users of the role enroll on their next login, running sessions are not ended
+	m.logger.WithContext(ctx).Info("MFA requirement of role:", roleName, "set to:", required)
+	return m.auditLogRepository.CreateAuditLog(&entity.AuditLog{
+		Event:   entity.AuditEventRoleMFAUpdated,
+		ActorID: &adminID,
+		Details: fmt.Sprintf("role: %s, mfa_required: %t", roleName, required),
+	})
+}
+
+// confirm enables MFA with a code of the new authenticator and returns the new recovery codes
+func (m *mfaService) confirm(ctx context.Context, userID int64, code string) ([]string, error) {
+	logger := m.logger.WithContext(ctx)
+
+	userMFA, err := m.mfaRepository.FindUserMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if userMFA.IsConfirmed() {
+		return nil, customErr.ErrMFAAlreadyEnabled{}
+	}
+
+	secret, err := m.cipher.Decrypt(userMFA.Secret)
+	if err != nil {
+		logger.Error("Error decrypting MFA secret of user ID:", userID, "Error:", err)
+		return nil, err
+	}
+	step, ok := mfa.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, customErr.ErrInvalidMFACode{}
+	}
+
+	codes, err := mfa.GenerateRecoveryCodes(m.config.MFA.RecoveryCodes)
+	if err != nil {
+		logger.Error("Error generating recovery codes:", err)
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, recoveryCode := range codes {
+		hashes[i] = hashOpaqueToken(recoveryCode)
+	}
+
+	if err := m.mfaRepository.ConfirmUserMFA(userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	logger.Info("MFA enabled for user ID:", userID)
+	return codes, nil
+}
+
+// checkCode accepts a code of the authenticator or a recovery code, each works once
+func (m *mfaService) checkCode(ctx context.Context, userMFA *entity.UserMFA, request request.MFACodeRequest) error {
+	if request.RecoveryCode != "" {
+		return m.mfaRepository.UseRecoveryCode(userMFA.UserID, hashOpaqueToken(mfa.NormalizeRecoveryCode(request.RecoveryCode)))
+	}
+
+	secret, err := m.cipher.Decrypt(userMFA.Secret)
+	if err != nil {
+		m.logger.WithContext(ctx).Error("Error decrypting MFA secret of user ID:", userMFA.UserID, "Error:", err)
+		return err
+	}
+	step, ok := mfa.Validate(secret, request.Code, time.Now())
+	if !ok {
+		return customErr.ErrInvalidMFACode{}
+	}
+
+	return m.mfaRepository.UseTOTPStep(userMFA.UserID, step)
+}
+
+func (m *mfaService) findConfirmedMFA(userID int64) (*entity.UserMFA, error) {
+	userMFA, err := m.mfaRepository.FindUserMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !userMFA.IsConfirmed() {
+		return nil, customErr.ErrMFANotEnrolled{}
+	}
+
+	return userMFA, nil
+}
+
+func (m *mfaService) findChallenge(ctx context.Context, key string, purpose mfa.ChallengePurpose) (*mfa.Challenge, error) {
+	challenge, err := m.challengeRepository.FindChallenge(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Purpose != purpose {
+		m.logger.WithContext(ctx).Warn("MFA challenge of another purpose presented, user ID:", challenge.UserID)
+		return nil, customErr.ErrInvalidMFAChallenge{}
+	}
+
+	return challenge, nil
+}
+
+// failAttempt counts a wrong code against the challenge and ends it after too many, so codes
+// cannot be guessed within one password check
+func (m *mfaService) failAttempt(ctx context.Context, key string, challenge *mfa.Challenge, err error) error {
+	if !errors.Is(err, customErr.ErrInvalidMFACode{}) {
+		return err
+	}
+	logger := m.logger.WithContext(ctx)
+
+	attempts, recordErr := m.challengeRepository.RecordFailedAttempt(ctx, key, m.config.MFA.GetChallengeTTL())
+	if recordErr != nil {
+		return recordErr
+	}
+	logger.Warn("Invalid MFA code for user ID:", challenge.UserID, ", attempt:", attempts)
+
+	if attempts >= mfa.MaxChallengeAttempts {
+		if deleteErr := m.challengeRepository.DeleteChallenge(ctx, key); deleteErr != nil && !errors.Is(deleteErr, customErr.ErrInvalidMFAChallenge{}) {
+			return deleteErr
+		}
+		logger.Warn("MFA challenge ended after too many attempts, user ID:", challenge.UserID)
+		return customErr.ErrInvalidMFAChallenge{}
+	}
+
+	return err
+}
+
+// completeLogin spends the challenge and starts the session
+func (m *mfaService) completeLogin(ctx context.Context, key string, challenge *mfa.Challenge) (*response.AuthResponse, error) {
+	if err := m.challengeRepository.DeleteChallenge(ctx, key); err != nil {
+		return nil, err
+	}
+
+	user, err := m.userRepository.FindUserByID(challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != string(constants.UserStatusActive) {
+		m.logger.WithContext(ctx).Warn("MFA login denied for inactive user ID:", user.ID)
+		return nil, customErr.ErrUserNotActive{}
+	}
+
+	authResponse, err := m.authService.IssueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.WithContext(ctx).Info("User logged in with MFA, user ID:", user.ID)
+	return authResponse, nil
+}
+
+// isMFARequired tells whether a role of the user requires MFA
+func isMFARequired(authRepository identity.AuthRepository, userID int64) (bool, error) {
+	roles, err := authRepository.FindAllUserRolesByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, role := range *roles {
+		if role.MFARequired {
+			return true, nil
+		}
+	}
+	return false, nil
+}
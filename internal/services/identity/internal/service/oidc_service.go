@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"github.com/hthinh24/go-store/services/identity/internal/oidc"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type oidcService struct {
+	logger          logger.Logger
+	providers       map[string]*oidc.Provider
+	stateRepository identity.OIDCStateRepository
+	userRepository  identity.UserRepository
+	userService     identity.UserService
+	authService     identity.AuthService
+	config          *config.AppConfig
+}
+
+func NewOIDCService(logger logger.Logger, providers map[string]*oidc.Provider, stateRepository identity.OIDCStateRepository,
+	userRepository identity.UserRepository, userService identity.UserService, authService identity.AuthService,
+	cfg *config.AppConfig) identity.OIDCService {
+	return &oidcService{
+		logger:          logger,
+		providers:       providers,
+		stateRepository: stateRepository,
+		userRepository:  userRepository,
+		userService:     userService,
+		authService:     authService,
+		config:          cfg,
+	}
+}
+
+func (o *oidcService) StartLogin(ctx context.Context, providerName string) (string, error) {
+	logger := o.logger.WithContext(ctx)
+
+	provider, ok := o.providers[providerName]
+	if !ok {
+		return "", customErr.ErrOIDCProviderNotFound{Name: providerName}
+	}
+
+	key, state, err := oidc.NewState(provider.Name())
+	if err != nil {
+		logger.Error("Error generating login state:", err)
+		return "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, key, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		return "", err
+	}
+
+	if err := o.stateRepository.SaveState(ctx, key, state, o.config.OIDC.GetStateTTL()); err != nil {
+		return "", err
+	}
+
+	logger.Info("OIDC login started with provider:", provider.Name())
+	return authURL, nil
+}
+
+func (o *oidcService) CompleteLogin(ctx context.Context, providerName string, request request.OIDCCallbackRequest) (*response.AuthResponse, error) {
+	logger := o.logger.WithContext(ctx)
+
+	provider, ok := o.providers[providerName]
+	if !ok {
+		return nil, customErr.ErrOIDCProviderNotFound{Name: providerName}
+	}
+
+	// The state is taken before anything else, so every callback spends it
+	state, err := o.stateRepository.TakeState(ctx, request.State)
+	if err != nil {
+		return nil, err
+	}
+	if state.Provider != provider.Name() {
+		logger.Warn("Login state of another provider presented, provider:", provider.Name())
+		return nil, customErr.ErrInvalidOIDCState{}
+	}
+
+	if request.Error != "" || request.Code == "" {
+		logger.Warn("OIDC login rejected by provider:", provider.Name(), "Error:", request.Error, request.ErrorDescription)
+		return nil, customErr.ErrOIDCAuthentication{}
+	}
+
+	claims, err := provider.Exchange(ctx, request.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		logger.Error("OIDC code exchange failed with provider:", provider.Name(), "Error:", err)
+		return nil, customErr.ErrOIDCAuthentication{}
+	}
+
+	user, err := o.findOrCreateUser(ctx, provider.Name(), claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status != string(constants.UserStatusActive) {
+		logger.Warn("OIDC login denied for inactive user ID:", user.ID)
+		return nil, customErr.ErrUserNotActive{}
+	}
+
+	// The provider is the first factor, MFA of the user still applies
+	authResponse, err := o.authService.AuthenticateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("User logged in with provider:", provider.Name(), ", user ID:", user.ID)
+	return authResponse, nil
+}
+
+// findOrCreateUser returns the user of the provider identity, linking a user of the app with
+// the same email or creating a new one on the first login
+func (o *oidcService) findOrCreateUser(ctx context.Context, providerName string, claims *oidc.Claims) (*entity.User, error) {
+	user, err := o.userRepository.FindUserByProvider(providerName, claims.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, customErr.ErrUserNotFound{}) {
+		return nil, err
+	}
+
+	// Linking and provisioning trust the email, only the provider can vouch for it
+	if claims.Email == "" || !claims.EmailVerified {
+		o.logger.WithContext(ctx).Warn("OIDC login without a verified email, provider:", providerName)
+		return nil, customErr.ErrEmailNotVerified{}
+	}
+
+	user, err = o.userRepository.FindUserByEmail(claims.Email)
+	if err == nil {
+		return o.linkUser(ctx, user, providerName, claims.Subject)
+	}
+	if !errors.Is(err, customErr.ErrUserNotFound{}) {
+		return nil, err
+	}
+
+	return o.provisionUser(ctx, providerName, claims)
+}
+
+func (o *oidcService) linkUser(ctx context.Context, user *entity.User, providerName string, providerID string) (*entity.User, error) {
+	logger := o.logger.WithContext(ctx)
+
+	// A user is linked to a single provider, another provider cannot take the account over
+	if !constants.IsAppProvider(user.ProviderName) {
+		logger.Warn("User ID:", user.ID, "is already linked to provider:", user.ProviderName)
+		return nil, customErr.ErrAccountLinkedToOtherProvider{Email: user.Email}
+	}
+
+	// An active user whose email was never verified may have been registered by someone else,
+	// or changed the email to one it does not own, linking would hand that account the
+	// provider identity. A pending user cannot log in and its password is replaced below.
+	if user.EmailVerifiedAt == nil && user.Status != string(constants.UserStatusPending) {
+		logger.Warn("User ID:", user.ID, "has an unverified email, not linking provider:", providerName)
+		return nil, customErr.ErrAccountEmailNotVerified{Email: user.Email}
+	}
+
+	user.ProviderName = providerName
+	user.ProviderID = providerID
+	if err := o.userRepository.UpdateUserProvider(user); err != nil {
+		return nil, err
+	}
+
+	// The provider verified the email of a pending user, but not that its owner chose the
+	// password, so the password is replaced before the account is activated
+	if user.Status == string(constants.UserStatusPending) {
+		password, err := newOpaqueToken()
+		if err != nil {
+			return nil, err
+		}
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+
+		user.Password = string(hashedPassword)
+		if err := o.userRepository.UpdateUserPassword(user); err != nil {
+			return nil, err
+		}
+		user.Status = string(constants.UserStatusActive)
+		if err := o.userRepository.UpdateUserStatus(user); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := markEmailVerified(o.userRepository, user); err != nil {
+		return nil, err
+	}
+
+	logger.Info("User ID:", user.ID, "linked to provider:", providerName)
+	return user, nil
+}
+
+// provisionUser creates an active user with the default role, its password is random and never
+// revealed so the user logs in through the provider
+func (o *oidcService) provisionUser(ctx context.Context, providerName string, claims *oidc.Claims) (*entity.User, error) {
+	password, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := o.userService.CreateUser(ctx, &request.CreateUserRequest{
+		Email:        claims.Email,
+		Password:     password,
+		ProviderID:   claims.Subject,
+		ProviderName: providerName,
+		LastName:     claims.FamilyName,
+		FirstName:    claims.GivenName,
+		Avatar:       claims.Picture,
+		Gender:       string(constants.GenderOther),
+		Status:       string(constants.UserStatusActive),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := o.userRepository.FindUserByID(created.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := markEmailVerified(o.userRepository, user); err != nil {
+		return nil, err
+	}
+
+	o.logger.WithContext(ctx).Info("User ID:", created.ID, "provisioned from provider:", providerName)
+	return user, nil
+}
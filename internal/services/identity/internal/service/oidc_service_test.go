@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/config"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+	"github.com/hthinh24/go-store/services/identity/internal/oidc"
+	"github.com/hthinh24/go-store/services/identity/internal/oidc/oidctest"
+)
+
+// memoryStateRepository keeps login states in memory, ttl is ignored
+type memoryStateRepository struct {
+	mu     sync.Mutex
+	states map[string]oidc.State
+}
+
+func (m *memoryStateRepository) SaveState(_ context.Context, key string, state *oidc.State, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[key] = *state
+	return nil
+}
+
+func (m *memoryStateRepository) TakeState(_ context.Context, key string) (*oidc.State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.states[key]
+	if !ok {
+		return nil, customErr.ErrInvalidOIDCState{}
+	}
+	delete(m.states, key)
+	return &state, nil
+}
+
+// memoryUserRepository keeps users in memory, only the lookups the login uses are implemented
+type memoryUserRepository struct {
+	identity.UserRepository
+	users  map[int64]*entity.User
+	nextID int64
+}
+
+func (m *memoryUserRepository) FindUserByID(id int64) (*entity.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return nil, customErr.ErrUserNotFound{}
+	}
+	return user, nil
+}
+
+func (m *memoryUserRepository) FindUserByEmail(email string) (*entity.User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, customErr.ErrUserNotFound{}
+}
+
+func (m *memoryUserRepository) FindUserByProvider(providerName string, providerID string) (*entity.User, error) {
+	for _, user := range m.users {
+		if user.ProviderName == providerName && user.ProviderID == providerID {
+			return user, nil
+		}
+	}
+	return nil, customErr.ErrUserNotFound{}
+}
+
+func (m *memoryUserRepository) CreateUser(user *entity.User) error {
+	m.nextID++
+	user.ID = m.nextID
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *memoryUserRepository) UpdateUserProvider(user *entity.User) error {
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *memoryUserRepository) UpdateUserPassword(user *entity.User) error {
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *memoryUserRepository) UpdateUserStatus(user *entity.User) error {
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *memoryUserRepository) UpdateUserEmailVerified(user *entity.User) error {
+	m.users[user.ID] = user
+	return nil
+}
+
+// stubUserService creates users like the user service and records the roles they get
+type stubUserService struct {
+	identity.UserService
+	userRepository *memoryUserRepository
+	roles          map[int64]constants.Role
+}
+
+func (s *stubUserService) CreateUser(_ context.Context, data *request.CreateUserRequest) (*response.UserResponse, error) {
+	user := &entity.User{
+		Email:        data.Email,
+		Password:     data.Password,
+		ProviderID:   data.ProviderID,
+		ProviderName: data.ProviderName,
+		FirstName:    data.FirstName,
+		LastName:     data.LastName,
+		Status:       data.Status,
+	}
+	if err := s.userRepository.CreateUser(user); err != nil {
+		return nil, err
+	}
+	s.roles[user.ID] = constants.RoleUser
+
+	return &response.UserResponse{ID: user.ID, Email: user.Email, Status: user.Status}, nil
+}
+
+// stubAuthService issues a token naming the user it was issued to
+type stubAuthService struct {
+	identity.AuthService
+}
+
+func (s *stubAuthService) AuthenticateUser(_ context.Context, user *entity.User) (*response.AuthResponse, error) {
+	return &response.AuthResponse{Token: "token-" + strconv.FormatInt(user.ID, 10), TokenType: "Bearer"}, nil
+}
+
+type oidcFixture struct {
+	stub        *oidctest.Server
+	service     identity.OIDCService
+	users       *memoryUserRepository
+	userService *stubUserService
+}
+
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	t.Helper()
+
+	stub := oidctest.NewServer()
+	t.Cleanup(stub.Close)
+
+	appLogger := logger.NewAppLogger("development")
+	users := &memoryUserRepository{users: make(map[int64]*entity.User)}
+	userService := &stubUserService{userRepository: users, roles: make(map[int64]constants.Role)}
+	providers := map[string]*oidc.Provider{
+		"stub": oidc.NewProvider(appLogger, "stub", stub.ProviderConfig(), nil),
+	}
+	cfg := &config.AppConfig{OIDC: config.OIDC{StateTTL: 600}}
+
+	return &oidcFixture{
+		stub:        stub,
+		service:     NewOIDCService(appLogger, providers, &memoryStateRepository{states: make(map[string]oidc.State)}, users, userService, &stubAuthService{}, cfg),
+		users:       users,
+		userService: userService,
+	}
+}
+
+// authorize starts a login and lets the stub approve it
+func (f *oidcFixture) authorize(t *testing.T) request.OIDCCallbackRequest {
+	t.Helper()
+
+	authURL, err := f.service.StartLogin(context.Background(), "stub")
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+
+	state, code, err := f.stub.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	return request.OIDCCallbackRequest{State: state, Code: code}
+}
+
+func (f *oidcFixture) login(t *testing.T) (*response.AuthResponse, error) {
+	t.Helper()
+	return f.service.CompleteLogin(context.Background(), "stub", f.authorize(t))
+}
+
+func TestOIDCLoginProvisionsUser(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	authResponse, err := f.login(t)
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if authResponse.Token != "token-1" {
+		t.Errorf("token = %q, want token-1", authResponse.Token)
+	}
+
+	user := f.users.users[1]
+	if user == nil || user.Email != "stub@example.com" || user.ProviderName != "stub" || user.ProviderID != "stub-subject" {
+		t.Fatalf("provisioned user = %+v", user)
+	}
+	if user.Status != string(constants.UserStatusActive) || user.FirstName != "Stub" || user.LastName != "User" {
+		t.Errorf("provisioned user = %+v", user)
+	}
+	if user.Password == "" {
+		t.Error("provisioned user has no password, the password login would accept an empty one")
+	}
+	if user.EmailVerifiedAt == nil {
+		t.Error("email vouched for by the provider is not marked verified")
+	}
+	if f.userService.roles[user.ID] != constants.RoleUser {
+		t.Errorf("role = %q, want %q", f.userService.roles[user.ID], constants.RoleUser)
+	}
+
+	// The next login finds the provisioned user by its provider identity
+	if _, err := f.login(t); err != nil {
+		t.Fatalf("second CompleteLogin: %v", err)
+	}
+	if len(f.users.users) != 1 {
+		t.Errorf("users = %d, want 1", len(f.users.users))
+	}
+}
+
+func TestOIDCLoginLinksUserByVerifiedEmail(t *testing.T) {
+	f := newOIDCFixture(t)
+	verifiedAt := time.Now().Add(-time.Hour)
+	_ = f.users.CreateUser(&entity.User{
+		Email:           "stub@example.com",
+		ProviderName:    "APP",
+		Status:          string(constants.UserStatusActive),
+		EmailVerifiedAt: &verifiedAt,
+	})
+
+	authResponse, err := f.login(t)
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if authResponse.Token != "token-1" {
+		t.Errorf("token = %q, want the token of the existing user", authResponse.Token)
+	}
+
+	user := f.users.users[1]
+	if user.ProviderName != "stub" || user.ProviderID != "stub-subject" {
+		t.Errorf("linked user provider = %q/%q", user.ProviderName, user.ProviderID)
+	}
+	if len(f.users.users) != 1 {
+		t.Errorf("users = %d, want 1", len(f.users.users))
+	}
+}
+
+func TestOIDCLoginLinksPendingUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	_ = f.users.CreateUser(&entity.User{
+		Email:        "stub@example.com",
+		Password:     "password-chosen-by-whoever-signed-up",
+		ProviderName: "app",
+		Status:       string(constants.UserStatusPending),
+	})
+
+	if _, err := f.login(t); err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+
+	user := f.users.users[1]
+	if user.Status != string(constants.UserStatusActive) {
+		t.Errorf("status = %q, want %q", user.Status, constants.UserStatusActive)
+	}
+	if user.Password == "password-chosen-by-whoever-signed-up" || user.Password == "" {
+		t.Errorf("password = %q, want a new random one", user.Password)
+	}
+	if user.EmailVerifiedAt == nil {
+		t.Error("email vouched for by the provider is not marked verified")
+	}
+}
+
+func TestOIDCLoginRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing *entity.User
+		identity oidctest.Identity
+		wantErr  error
+	}{
+		{
+			name:     "unverified email",
+			identity: oidctest.Identity{Subject: "subject", Email: "stub@example.com"},
+			wantErr:  customErr.ErrEmailNotVerified{},
+		},
+		{
+			name:     "unverified email of an existing user",
+			existing: &entity.User{Email: "stub@example.com", ProviderName: "app", Status: string(constants.UserStatusActive)},
+			identity: oidctest.Identity{Subject: "subject", Email: "stub@example.com"},
+			wantErr:  customErr.ErrEmailNotVerified{},
+		},
+		{
+			name:     "active user that never verified the email",
+			existing: &entity.User{Email: "stub@example.com", ProviderName: "app", Status: string(constants.UserStatusActive)},
+			identity: oidctest.Identity{Subject: "subject", Email: "stub@example.com", EmailVerified: true},
+			wantErr:  customErr.ErrAccountEmailNotVerified{Email: "stub@example.com"},
+		},
+		{
+			name:     "missing email",
+			identity: oidctest.Identity{Subject: "subject", EmailVerified: true},
+			wantErr:  customErr.ErrEmailNotVerified{},
+		},
+		{
+			name:     "email of a user linked to another provider",
+			existing: &entity.User{Email: "stub@example.com", ProviderName: "other", ProviderID: "other-subject", Status: string(constants.UserStatusActive)},
+			identity: oidctest.Identity{Subject: "subject", Email: "stub@example.com", EmailVerified: true},
+			wantErr:  customErr.ErrAccountLinkedToOtherProvider{Email: "stub@example.com"},
+		},
+		{
+			name:     "email of a user linked to another identity of the provider",
+			existing: &entity.User{Email: "stub@example.com", ProviderName: "stub", ProviderID: "other-subject", Status: string(constants.UserStatusActive)},
+			identity: oidctest.Identity{Subject: "subject", Email: "stub@example.com", EmailVerified: true},
+			wantErr:  customErr.ErrAccountLinkedToOtherProvider{Email: "stub@example.com"},
+		},
+		{
+			name:     "inactive user",
+			existing: &entity.User{Email: "stub@example.com", ProviderName: "stub", ProviderID: "subject", Status: string(constants.UserStatusInactive)},
+			identity: oidctest.Identity{Subject: "subject", Email: "stub@example.com", EmailVerified: true},
+			wantErr:  customErr.ErrUserNotActive{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOIDCFixture(t)
+			if tt.existing != nil {
+				_ = f.users.CreateUser(tt.existing)
+			}
+			f.stub.SetIdentity(tt.identity)
+
+			authResponse, err := f.login(t)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteLogin = %+v, %v, want %v", authResponse, err, tt.wantErr)
+			}
+			if tt.existing == nil && len(f.users.users) != 0 {
+				t.Errorf("users = %d, want none provisioned", len(f.users.users))
+			}
+		})
+	}
+}
+
+func TestOIDCLoginStateIsSingleUse(t *testing.T) {
+	f := newOIDCFixture(t)
+	callback := f.authorize(t)
+
+	if _, err := f.service.CompleteLogin(context.Background(), "stub", callback); err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if _, err := f.service.CompleteLogin(context.Background(), "stub", callback); !errors.Is(err, customErr.ErrInvalidOIDCState{}) {
+		t.Errorf("replayed CompleteLogin error = %v, want %v", err, customErr.ErrInvalidOIDCState{})
+	}
+}
+
+func TestOIDCLoginRejectsUnknownState(t *testing.T) {
+	f := newOIDCFixture(t)
+	callback := f.authorize(t)
+	callback.State = "forged-state"
+
+	if _, err := f.service.CompleteLogin(context.Background(), "stub", callback); !errors.Is(err, customErr.ErrInvalidOIDCState{}) {
+		t.Errorf("CompleteLogin error = %v, want %v", err, customErr.ErrInvalidOIDCState{})
+	}
+}
+
+// A code of one login completed with the state of another fails on the PKCE verifier and
+// nonce of that other login, so a code injected into a victim's callback is useless
+func TestOIDCLoginRejectsCodeOfAnotherLogin(t *testing.T) {
+	f := newOIDCFixture(t)
+	victim := f.authorize(t)
+	attacker := f.authorize(t)
+
+	injected := request.OIDCCallbackRequest{State: victim.State, Code: attacker.Code}
+	if _, err := f.service.CompleteLogin(context.Background(), "stub", injected); !errors.Is(err, customErr.ErrOIDCAuthentication{}) {
+		t.Errorf("CompleteLogin error = %v, want %v", err, customErr.ErrOIDCAuthentication{})
+	}
+	if len(f.users.users) != 0 {
+		t.Errorf("users = %d, want none provisioned", len(f.users.users))
+	}
+}
+
+func TestOIDCLoginProviderError(t *testing.T) {
+	f := newOIDCFixture(t)
+	callback := f.authorize(t)
+	callback.Code = ""
+	callback.Error = "access_denied"
+
+	if _, err := f.service.CompleteLogin(context.Background(), "stub", callback); !errors.Is(err, customErr.ErrOIDCAuthentication{}) {
+		t.Errorf("CompleteLogin error = %v, want %v", err, customErr.ErrOIDCAuthentication{})
+	}
+}
+
+func TestOIDCUnknownProvider(t *testing.T) {
+	f := newOIDCFixture(t)
+	want := customErr.ErrOIDCProviderNotFound{Name: "unknown"}
+
+	if _, err := f.service.StartLogin(context.Background(), "unknown"); !errors.Is(err, want) {
+		t.Errorf("StartLogin error = %v, want %v", err, want)
+	}
+
+	callback := f.authorize(t)
+	if _, err := f.service.CompleteLogin(context.Background(), "unknown", callback); !errors.Is(err, want) {
+		t.Errorf("CompleteLogin error = %v, want %v", err, want)
+	}
+}
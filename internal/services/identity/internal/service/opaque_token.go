@@ -0,0 +1,26 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// opaqueTokenBytes is the entropy of refresh and account tokens
+const opaqueTokenBytes = 32
+
+// newOpaqueToken returns a random URL safe token
+func newOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashOpaqueToken hashes the token so the database never holds usable credentials
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
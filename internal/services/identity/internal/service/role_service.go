@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	customErr "github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+// builtInRoles are referenced by the service itself, e.g. sign ups get the user role
+var builtInRoles = []constants.Role{constants.RoleAdmin, constants.RoleUser, constants.RoleStaff, constants.RoleMerchant}
+
+type roleService struct {
+	logger             logger.Logger
+	authRepository     identity.AuthRepository
+	userRepository     identity.UserRepository
+	auditLogRepository identity.AuditLogRepository
+	permissionCache    identity.PermissionCacheRepository
+}
+
+func NewRoleService(logger logger.Logger, authRepository identity.AuthRepository, userRepository identity.UserRepository,
+	auditLogRepository identity.AuditLogRepository, permissionCache identity.PermissionCacheRepository) identity.RoleService {
+	return &roleService{
+		logger:             logger,
+		authRepository:     authRepository,
+		userRepository:     userRepository,
+		auditLogRepository: auditLogRepository,
+		permissionCache:    permissionCache,
+	}
+}
+
+func (r *roleService) GetRoles(ctx context.Context) (*[]response.RoleResponse, error) {
+	roles, err := r.authRepository.FindRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.createRoleResponses(*roles)
+}
+
+func (r *roleService) GetRole(ctx context.Context, name string) (*response.RoleResponse, error) {
+	role, err := r.authRepository.FindRoleByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.createRoleResponse(role)
+}
+
+func (r *roleService) CreateRole(ctx context.Context, data request.CreateRoleRequest, adminID int64) (*response.RoleResponse, error) {
+	if _, err := r.authRepository.FindRoleByName(data.Name); err == nil {
+		return nil, customErr.ErrRoleAlreadyExists{Name: data.Name}
+	} else if !errors.Is(err, customErr.ErrRoleNotFound{Name: data.Name}) {
+		return nil, err
+	}
+
+	role := &entity.Role{Name: data.Name, Description: data.Description}
+	if err := r.authRepository.CreateRole(role); err != nil {
+		return nil, err
+	}
+
+	if err := r.audit(ctx, entity.AuditEventRoleCreated, adminID, nil, fmt.Sprintf("role: %s", role.Name)); err != nil {
+		return nil, err
+	}
+	return r.createRoleResponse(role)
+}
+
+func (r *roleService) UpdateRole(ctx context.Context, name string, data request.UpdateRoleRequest, adminID int64) (*response.RoleResponse, error) {
+	role, err := r.authRepository.FindRoleByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Description = data.Description
+	if err := r.authRepository.UpdateRole(role); err != nil {
+		return nil, err
+	}
+
+	if err := r.audit(ctx, entity.AuditEventRoleUpdated, adminID, nil, fmt.Sprintf("role: %s", role.Name)); err != nil {
+		return nil, err
+	}
+	return r.createRoleResponse(role)
+}
+
+func (r *roleService) DeleteRole(ctx context.Context, name string, adminID int64) error {
+	for _, builtIn := range builtInRoles {
+		if name == string(builtIn) {
+			return customErr.ErrProtectedRole{Name: name}
+		}
+	}
+
+	role, err := r.authRepository.FindRoleByName(name)
+	if err != nil {
+		return err
+	}
+
+	if err := r.authRepository.DeleteRole(role.ID); err != nil {
+		return err
+	}
+	if err := r.permissionCache.InvalidateRoles(ctx, role.ID); err != nil {
+		return err
+	}
+
+	return r.audit(ctx, entity.AuditEventRoleDeleted, adminID, nil, fmt.Sprintf("role: %s", role.Name))
+}
+
+func (r *roleService) AddPermissionToRole(ctx context.Context, roleName string, data request.RolePermissionRequest, adminID int64) (*response.RoleResponse, error) {
+	role, permission, err := r.findRoleAndPermission(roleName, data.Permission)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.authRepository.AddPermissionToRole(&entity.RolePermissions{RoleID: role.ID, PermissionID: permission.ID}); err != nil {
+		return nil, err
+	}
+	if err := r.permissionCache.InvalidateRoles(ctx, role.ID); err != nil {
+		return nil, err
+	}
+
+	details := fmt.Sprintf("role: %s, permission: %s", role.Name, permission.Name)
+	if err := r.audit(ctx, entity.AuditEventRolePermissionAdded, adminID, nil, details); err != nil {
+		return nil, err
+	}
+	return r.createRoleResponse(role)
+}
+
+func (r *roleService) RemovePermissionFromRole(ctx context.Context, roleName string, permissionName string, adminID int64) (*response.RoleResponse, error) {
+	role, permission, err := r.findRoleAndPermission(roleName, permissionName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.authRepository.RemovePermissionFromRole(role.ID, permission.ID); err != nil {
+		return nil, err
+	}
+	if err := r.permissionCache.InvalidateRoles(ctx, role.ID); err != nil {
+		return nil, err
+	}
+
+	details := fmt.Sprintf("role: %s, permission: %s", role.Name, permission.Name)
+	if err := r.audit(ctx, entity.AuditEventRolePermissionRemoved, adminID, nil, details); err != nil {
+		return nil, err
+	}
+	return r.createRoleResponse(role)
+}
+
+func (r *roleService) GetPermissions(ctx context.Context) (*[]response.PermissionResponse, error) {
+	permissions, err := r.authRepository.FindPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	permissionResponses := make([]response.PermissionResponse, len(*permissions))
+	for i, permission := range *permissions {
+		permissionResponses[i] = *createPermissionResponse(&permission)
+	}
+	return &permissionResponses, nil
+}
+
+func (r *roleService) CreatePermission(ctx context.Context, data request.CreatePermissionRequest, adminID int64) (*response.PermissionResponse, error) {
+	if _, err := r.authRepository.FindPermissionByName(data.Name); err == nil {
+		return nil, customErr.ErrPermissionAlreadyExists{Name: data.Name}
+	} else if !errors.Is(err, customErr.ErrPermissionNotFound{Name: data.Name}) {
+		return nil, err
+	}
+
+	permission := &entity.Permission{Name: data.Name, Description: data.Description}
+	if err := r.authRepository.CreatePermission(permission); err != nil {
+		return nil, err
+	}
+
+	if err := r.audit(ctx, entity.AuditEventPermissionCreated, adminID, nil, fmt.Sprintf("permission: %s", permission.Name)); err != nil {
+		return nil, err
+	}
+	return createPermissionResponse(permission), nil
+}
+
+func (r *roleService) UpdatePermission(ctx context.Context, name string, data request.UpdatePermissionRequest, adminID int64) (*response.PermissionResponse, error) {
+	permission, err := r.authRepository.FindPermissionByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	permission.Description = data.Description
+	if err := r.authRepository.UpdatePermission(permission); err != nil {
+		return nil, err
+	}
+
+	if err := r.audit(ctx, entity.AuditEventPermissionUpdated, adminID, nil, fmt.Sprintf("permission: %s", permission.Name)); err != nil {
+		return nil, err
+	}
+	return createPermissionResponse(permission), nil
+}
+
+func (r *roleService) DeletePermission(ctx context.Context, name string, adminID int64) error {
+	permission, err := r.authRepository.FindPermissionByName(name)
+	if err != nil {
+		return err
+	}
+
+	// Read before the delete, the permission leaves every role holding it
+	roles, err := r.authRepository.FindRoles()
+	if err != nil {
+		return err
+	}
+
+	if err := r.authRepository.DeletePermission(permission.ID); err != nil {
+		return err
+	}
+
+	roleIDs := make([]int64, len(*roles))
+	for i, role := range *roles {
+		roleIDs[i] = role.ID
+	}
+	if err := r.permissionCache.InvalidateRoles(ctx, roleIDs...); err != nil {
+		return err
+	}
+
+	return r.audit(ctx, entity.AuditEventPermissionDeleted, adminID, nil, fmt.Sprintf("permission: %s", permission.Name))
+}
+
+func (r *roleService) GetUserRoles(ctx context.Context, userID int64) (*[]response.RoleResponse, error) {
+	if _, err := r.userRepository.FindUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	roles, err := r.authRepository.FindAllUserRolesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.createRoleResponses(*roles)
+}
+
+func (r *roleService) AssignRoleToUser(ctx context.Context, userID int64, data request.UserRoleRequest, adminID int64) (*[]response.RoleResponse, error) {
+	user, err := r.userRepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	role, err := r.authRepository.FindRoleByName(data.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.authRepository.AddRoleToUser(&entity.UserRoles{UserID: user.ID, RoleID: role.ID}); err != nil {
+		return nil, err
+	}
+
+	if err := r.audit(ctx, entity.AuditEventUserRoleAssigned, adminID, &user.ID, fmt.Sprintf("role: %s", role.Name)); err != nil {
+		return nil, err
+	}
+	return r.GetUserRoles(ctx, user.ID)
+}
+
+func (r *roleService) RevokeRoleFromUser(ctx context.Context, userID int64, roleName string, adminID int64) (*[]response.RoleResponse, error) {
+	// Admins can only be demoted by another admin, so the last one cannot lock everybody out
+	if userID == adminID && roleName == string(constants.RoleAdmin) {
+		return nil, customErr.ErrInvalidRoleChange{Message: "Admins cannot revoke their own admin role"}
+	}
+
+	user, err := r.userRepository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	role, err := r.authRepository.FindRoleByName(roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.authRepository.RemoveRoleFromUser(user.ID, role.ID); err != nil {
+		return nil, err
+	}
+
+	if err := r.audit(ctx, entity.AuditEventUserRoleRevoked, adminID, &user.ID, fmt.Sprintf("role: %s", role.Name)); err != nil {
+		return nil, err
+	}
+	return r.GetUserRoles(ctx, user.ID)
+}
+
+func (r *roleService) findRoleAndPermission(roleName string, permissionName string) (*entity.Role, *entity.Permission, error) {
+	role, err := r.authRepository.FindRoleByName(roleName)
+	if err != nil {
+		return nil, nil, err
+	}
+	permission, err := r.authRepository.FindPermissionByName(permissionName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return role, permission, nil
+}
+
+// audit records a change made by an admin, userID is set for changes of a user's roles
+func (r *roleService) audit(ctx context.Context, event entity.AuditEvent, adminID int64, userID *int64, details string) error {
+	r.logger.WithContext(ctx).Info("Role change:", event, details, ", by admin ID:", adminID)
+
+	return r.auditLogRepository.CreateAuditLog(&entity.AuditLog{
+		Event:   event,
+		UserID:  userID,
+		ActorID: &adminID,
+		Details: details,
+	})
+}
+
+func (r *roleService) createRoleResponse(role *entity.Role) (*response.RoleResponse, error) {
+	permissions, err := r.authRepository.FindAllPermissionsByRoleIDs([]int64{role.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(*permissions))
+	for i, permission := range *permissions {
+		names[i] = permission.Name
+	}
+
+	return &response.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		MFARequired: role.MFARequired,
+		Permissions: names,
+	}, nil
+}
+
+func (r *roleService) createRoleResponses(roles []entity.Role) (*[]response.RoleResponse, error) {
+	roleResponses := make([]response.RoleResponse, len(roles))
+	for i, role := range roles {
+		roleResponse, err := r.createRoleResponse(&role)
+		if err != nil {
+			return nil, err
+		}
+		roleResponses[i] = *roleResponse
+	}
+
+	return &roleResponses, nil
+}
+
+func createPermissionResponse(permission *entity.Permission) *response.PermissionResponse {
+	return &response.PermissionResponse{
+		ID:          permission.ID,
+		Name:        permission.Name,
+		Description: permission.Description,
+	}
+}
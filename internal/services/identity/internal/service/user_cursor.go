@@ -0,0 +1,57 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/hthinh24/go-store/services/identity"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+	"github.com/hthinh24/go-store/services/identity/internal/errors"
+)
+
+// userCursor is the opaque next_cursor of the user listing, it carries the sort it was made
+// for so it cannot be replayed against another order
+type userCursor struct {
+	SortBy   string `json:"s"`
+	SortDesc bool   `json:"d"`
+	Value    string `json:"v"`
+	ID       int64  `json:"id"`
+}
+
+func encodeUserCursor(user *entity.User, filter identity.UserFilter) string {
+	cursor := userCursor{SortBy: filter.SortBy, SortDesc: filter.SortDesc, ID: user.ID}
+	switch filter.SortBy {
+	case "email":
+		cursor.Value = user.Email
+	case "first_name":
+		cursor.Value = user.FirstName
+	case "last_name":
+		cursor.Value = user.LastName
+	case "status":
+		cursor.Value = user.Status
+	case "created_at":
+		cursor.Value = user.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	// Marshalling a struct of strings and numbers cannot fail
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserCursor(encoded string, filter identity.UserFilter) (*identity.UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.ErrInvalidUserData{Field: "cursor", Message: "is malformed"}
+	}
+
+	var cursor userCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errors.ErrInvalidUserData{Field: "cursor", Message: "is malformed"}
+	}
+	if cursor.SortBy != filter.SortBy || cursor.SortDesc != filter.SortDesc {
+		return nil, errors.ErrInvalidUserData{Field: "cursor", Message: "was made for another sort order"}
+	}
+
+	return &identity.UserCursor{Value: cursor.Value, ID: cursor.ID}, nil
+}
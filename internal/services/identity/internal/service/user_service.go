@@ -2,16 +2,14 @@ package service
 
 import (
 	"context"
-	"github.com/hthinh24/go-store/services/identity/internal/controller/http/client"
-	"time"
 
 	log "github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/rest"
 	"github.com/hthinh24/go-store/services/identity"
 	"github.com/hthinh24/go-store/services/identity/internal/constants"
 	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
 	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
 	"github.com/hthinh24/go-store/services/identity/internal/entity"
-	"github.com/hthinh24/go-store/services/identity/internal/errors"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -19,18 +17,18 @@ type userService struct {
 	logger         log.Logger
 	userRepository identity.UserRepository
 	authRepository identity.AuthRepository
-	cartClient     client.CartClient
+	accountService identity.AccountService
 }
 
 func NewUserService(logger log.Logger,
 	userRepository identity.UserRepository,
 	authRepository identity.AuthRepository,
-	cartClient client.CartClient) identity.UserService {
+	accountService identity.AccountService) identity.UserService {
 	return &userService{
 		logger:         logger,
 		userRepository: userRepository,
 		authRepository: authRepository,
-		cartClient:     cartClient,
+		accountService: accountService,
 	}
 }
 
@@ -47,68 +45,97 @@ func (u *userService) GetUserByID(id int64) (*response.UserResponse, error) {
 	return createUserResponse(user), nil
 }
 
-func (u *userService) GetUsers() (*[]response.UserResponse, error) {
-	u.logger.Info("Get all users")
+func (u *userService) GetUsers(ctx context.Context, data request.ListUsersRequest) (*rest.PageResponse, error) {
+	logger := u.logger.WithContext(ctx)
+	logger.Info("Get users page")
+
+	paging := rest.NewPaging(data.PageSize, data.PageNumber)
+	filter := identity.UserFilter{
+		Status:      data.Status,
+		Role:        data.Role,
+		CreatedFrom: data.CreatedFrom,
+		CreatedTo:   data.CreatedTo,
+		Search:      data.Search,
+		SortBy:      data.SortBy,
+		SortDesc:    data.SortOrder == "desc",
+		// One row more than the page tells whether a next page exists
+		Limit:  paging.PageSize + 1,
+		Offset: paging.PageSize * paging.PageNumber,
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = "id"
+	}
+	if data.Cursor != "" {
+		after, err := decodeUserCursor(data.Cursor, filter)
+		if err != nil {
+			logger.Warn("Invalid user listing cursor:", err)
+			return nil, err
+		}
+		filter.After = after
+	}
 
-	users, err := u.userRepository.FindUsers()
+	users, total, err := u.userRepository.FindUsers(filter)
 	if err != nil {
-		u.logger.Error("Error fetching users: ", err)
+		logger.Error("Error fetching users: ", err)
 		return nil, err
 	}
 
-	u.logger.Info("Get all users successfully")
-	var userResponses []response.UserResponse
-	for _, user := range *users {
-		userResponses = append(userResponses, *createUserResponse(&user))
+	page := *users
+	nextCursor := ""
+	if len(page) > paging.PageSize {
+		page = page[:paging.PageSize]
+		nextCursor = encodeUserCursor(&page[len(page)-1], filter)
 	}
-	return &userResponses, nil
+
+	userResponses := make([]response.UserResponse, len(page))
+	for i, user := range page {
+		userResponses[i] = *createUserResponse(&user)
+	}
+
+	logger.Info("Get users page successfully, total:", total)
+	pageResponse := rest.NewPageResponse(paging, int(total), userResponses)
+	pageResponse.NextCursor = nextCursor
+	return pageResponse, nil
 }
 
-func (u *userService) CreateUser(data *request.CreateUserRequest) (*response.UserResponse, error) {
-	u.logger.Info("Creating new user with email:", data.Email)
+func (u *userService) CreateUser(ctx context.Context, data *request.CreateUserRequest) (*response.UserResponse, error) {
+	logger := u.logger.WithContext(ctx)
+
+	logger.Info("Creating new user with email:", data.Email)
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
 	if err != nil {
-		u.logger.Error("Error hashing password:", err)
+		logger.Error("Error hashing password:", err)
 		return nil, err
 	}
 
 	data.Password = string(hashedPassword)
 	user := u.createUserEntity(data)
-	if err := u.userRepository.CreateUser(user); err != nil {
-		u.logger.Error("Error creating user:", err)
+
+	role, err := u.authRepository.FindRoleByName(string(constants.RoleUser))
+	if err != nil {
+		logger.Error("Error finding user role:", err)
 		return nil, err
 	}
 
-	if err := u.setUserRoleToUser(user); err != nil {
-		u.logger.Error("Error setting user role:", err)
+	// The cart is created by the outbox relay, sign ups do not depend on the cart service
+	if err := u.userRepository.RegisterUser(user, role.ID); err != nil {
+		logger.Error("Error creating user:", err)
 		return nil, err
 	}
 
-	// Create a cart for the new user (only if cart client is properly configured)
-	if u.cartClient != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if err := u.cartClient.CreateCart(ctx, user.ID); err != nil {
-			u.logger.Error("Error creating cart for user:", err)
-			// Don't fail user creation if cart creation fails - just log the error
-			u.logger.Warn("User created successfully but cart creation failed - user can create cart later")
-			// Rollback user creation if needed
-			u.userRepository.DeleteUser(user.ID)
-			return nil, errors.ErrCartCreationFailed{}
-		} else {
-			u.logger.Info("Cart created successfully for user ID:", user.ID)
+	// A failed email does not fail the sign up, the user can ask for a new one
+	if user.Status == string(constants.UserStatusPending) {
+		if err := u.accountService.SendVerificationEmail(ctx, user); err != nil {
+			logger.Error("Error sending verification email to user ID:", user.ID, "Error:", err)
 		}
-	} else {
-		u.logger.Info("Cart client not configured - skipping cart creation for user ID:", user.ID)
 	}
 
-	u.logger.Info("Successfully created user with ID:", user.ID)
+	logger.Info("Successfully created user with ID:", user.ID)
 	return createUserResponse(user), nil
 }
 
-func (u *userService) UpdateUserProfile(id int64, data *request.UpdateUserProfileRequest) (*response.UserResponse, error) {
+func (u *userService) UpdateUserProfile(ctx context.Context, id int64, data *request.UpdateUserProfileRequest) (*response.UserResponse, error) {
 	user, err := u.userRepository.FindUserByID(id)
 	if err != nil {
 		return nil, err
@@ -116,6 +143,14 @@ func (u *userService) UpdateUserProfile(id int64, data *request.UpdateUserProfil
 
 	u.logger.Info("Updating user profile with ID:", id)
 
+	// The email only changes once the new one is confirmed, until then the old one stays
+	if data.Email != nil {
+		if err := u.accountService.RequestEmailChange(ctx, user, *data.Email); err != nil {
+			u.logger.Error("Error requesting email change:", err)
+			return nil, err
+		}
+	}
+
 	updateUserEntity(user, data)
 	if err := u.userRepository.UpdateUserProfile(user); err != nil {
 		u.logger.Error("Error updating user profile:", err)
@@ -156,35 +191,6 @@ func (u *userService) UpdateUserPassword(id int64, data *request.UpdateUserPassw
 	return createUserResponse(user), nil
 }
 
-func (u *userService) UpdateToMerchantAccount(userID int64) error {
-	u.logger.Info("Updating user to merchant account with ID:", userID)
-
-	// TODO - Implement logic to update user to merchant account
-
-	user, err := u.userRepository.FindUserByID(userID)
-	if err != nil {
-		u.logger.Error("Error finding user by ID:", userID, "Error:", err)
-		return err
-	}
-
-	if user.Status != string(constants.UserStatusActive) {
-		u.logger.Error("User is not active, cannot update to merchant account:", userID)
-		return errors.ErrUserNotActive{}
-	}
-
-	role, err := u.authRepository.FindRoleByName(string(constants.RoleMerchant))
-	if err != nil {
-		return err
-	}
-
-	userRoles := createUserRoleEntity(user, role)
-	if err := u.authRepository.AddRoleToUser(userRoles); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (u *userService) DeleteUser(id int64) error {
 	u.logger.Info("Deleting user with ID:", id)
 
@@ -214,17 +220,7 @@ func (u *userService) createUserEntity(user *request.CreateUserRequest) *entity.
 	}
 }
 
-func createUserRoleEntity(user *entity.User, role *entity.Role) *entity.UserRoles {
-	return &entity.UserRoles{
-		UserID: user.ID,
-		RoleID: role.ID,
-	}
-}
-
 func updateUserEntity(user *entity.User, data *request.UpdateUserProfileRequest) {
-	if data.Email != nil {
-		user.Email = *data.Email
-	}
 	if data.LastName != nil {
 		user.LastName = *data.LastName
 	}
@@ -256,22 +252,6 @@ func createUserResponse(user *entity.User) *response.UserResponse {
 		PhoneNumber: user.PhoneNumber,
 		DateOfBirth: user.DateOfBirth,
 		Status:      user.Status,
+		CreatedAt:   user.CreatedAt,
 	}
 }
-
-func (u *userService) setUserRoleToUser(user *entity.User) error {
-	role, err := u.authRepository.FindRoleByName(string(constants.RoleUser))
-	if err != nil {
-		return err
-	}
-
-	userRoles := createUserRoleEntity(user, role)
-
-	if err := u.authRepository.AddRoleToUser(userRoles); err != nil {
-		u.logger.Error("Error assigning role to user:", err)
-		return err
-	}
-
-	u.logger.Info("Successfully assigned role to user with ID:", user.ID)
-	return nil
-}
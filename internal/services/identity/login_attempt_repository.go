@@ -0,0 +1,18 @@
+package identity
+
+import (
+	"context"
+	"time"
+)
+
+// LoginAttemptRepository tracks failed logins of a subject, an account or a client IP
+type LoginAttemptRepository interface {
+	// GetLockout returns how long the subject stays locked out, zero when it is not
+	GetLockout(ctx context.Context, subject string) (time.Duration, error)
+	// RecordFailure counts a failed login and returns the failures within the window
+	RecordFailure(ctx context.Context, subject string, window time.Duration) (int64, error)
+	// Lock locks the subject out and starts its failure count over
+	Lock(ctx context.Context, subject string, duration time.Duration) error
+	// Reset forgets the failures and the lockout of the subject
+	Reset(ctx context.Context, subject string) error
+}
@@ -0,0 +1,22 @@
+package identity
+
+import (
+	"github.com/hthinh24/go-store/services/identity/internal/constants"
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+)
+
+// MerchantApplicationRepository writes every status change together with its audit log, in
+// one transaction. The audit log gets the details of the saved application.
+type MerchantApplicationRepository interface {
+	CreateMerchantApplication(application *entity.MerchantApplication, auditLog *entity.AuditLog) error
+	FindMerchantApplicationByID(id int64) (*entity.MerchantApplication, error)
+	FindLatestMerchantApplicationByUserID(userID int64) (*entity.MerchantApplication, error)
+	// FindMerchantApplications returns a page of applications, all statuses when status is
+	// empty, and how many match in total
+	FindMerchantApplications(status constants.MerchantApplicationStatus, limit int, offset int) (*[]entity.MerchantApplication, int64, error)
+	// UpdateMerchantApplicationStatus saves the new status of the application only while it
+	// is still in the from status, so concurrent reviews cannot both succeed
+	UpdateMerchantApplicationStatus(application *entity.MerchantApplication, from constants.MerchantApplicationStatus, auditLog *entity.AuditLog) error
+	// ApproveMerchantApplication saves the approval and grants the merchant role in one transaction
+	ApproveMerchantApplication(application *entity.MerchantApplication, from constants.MerchantApplicationStatus, merchantRoleID int64, auditLog *entity.AuditLog) error
+}
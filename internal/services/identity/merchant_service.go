@@ -0,0 +1,19 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/hthinh24/go-store/internal/pkg/rest"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+)
+
+// MerchantService runs the merchant onboarding, users apply and admins review and decide
+type MerchantService interface {
+	SubmitApplication(ctx context.Context, userID int64, data request.MerchantApplicationRequest) (*response.MerchantApplicationResponse, error)
+	GetUserApplication(ctx context.Context, userID int64) (*response.MerchantApplicationResponse, error)
+	GetApplications(ctx context.Context, data request.ListMerchantApplicationsRequest) (*rest.PageResponse, error)
+	GetApplication(ctx context.Context, id int64) (*response.MerchantApplicationResponse, error)
+	StartReview(ctx context.Context, id int64, adminID int64) (*response.MerchantApplicationResponse, error)
+	DecideApplication(ctx context.Context, id int64, data request.MerchantApplicationDecisionRequest, adminID int64) (*response.MerchantApplicationResponse, error)
+}
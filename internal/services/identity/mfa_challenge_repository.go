@@ -0,0 +1,19 @@
+package identity
+
+import (
+	"context"
+	"time"
+
+	"github.com/hthinh24/go-store/services/identity/internal/mfa"
+)
+
+type MFAChallengeRepository interface {
+	SaveChallenge(ctx context.Context, key string, challenge *mfa.Challenge, ttl time.Duration) error
+	// FindChallenge fails with ErrInvalidMFAChallenge for unknown or expired keys
+	FindChallenge(ctx context.Context, key string) (*mfa.Challenge, error)
+	// RecordFailedAttempt returns the number of wrong codes presented for the challenge so far
+	RecordFailedAttempt(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// DeleteChallenge fails with ErrInvalidMFAChallenge when the challenge is already gone, so
+	// of two concurrent completions only one succeeds
+	DeleteChallenge(ctx context.Context, key string) error
+}
@@ -0,0 +1,20 @@
+package identity
+
+import "github.com/hthinh24/go-store/services/identity/internal/entity"
+
+type MFARepository interface {
+	// FindUserMFA fails with ErrMFANotEnrolled when the user never started an enrollment
+	FindUserMFA(userID int64) (*entity.UserMFA, error)
+	// SaveUserMFA stores the secret of an enrollment that is not confirmed yet, replacing an
+	// earlier unconfirmed one. It fails with ErrMFAAlreadyEnabled once MFA is enabled.
+	SaveUserMFA(userMFA *entity.UserMFA) error
+	// ConfirmUserMFA enables MFA with the step of the code that confirmed it and replaces the
+	// recovery codes of the user
+	ConfirmUserMFA(userID int64, step int64, recoveryCodeHashes []string) error
+	// UseTOTPStep records the step of an accepted code, it fails with ErrInvalidMFACode when
+	// the step is not newer than the last one so every code works once
+	UseTOTPStep(userID int64, step int64) error
+	// UseRecoveryCode marks an unused recovery code as used, it fails with ErrInvalidMFACode otherwise
+	UseRecoveryCode(userID int64, codeHash string) error
+	DeleteUserMFA(userID int64) error
+}
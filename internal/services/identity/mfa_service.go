@@ -0,0 +1,24 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+)
+
+type MFAService interface {
+	// Enroll creates a new authenticator secret, MFA is enabled once it is confirmed
+	Enroll(ctx context.Context, userID int64) (*response.MFAEnrollmentResponse, error)
+	// ConfirmEnrollment enables MFA and returns the recovery codes
+	ConfirmEnrollment(ctx context.Context, userID int64, request request.MFAConfirmRequest) (*response.MFARecoveryCodesResponse, error)
+	// Disable needs a code, a stolen access token alone cannot turn MFA off
+	Disable(ctx context.Context, userID int64, request request.MFACodeRequest) error
+	// Verify completes a login with a code of the authenticator or a recovery code
+	Verify(ctx context.Context, request request.MFAChallengeRequest) (*response.AuthResponse, error)
+	// StartSetup and CompleteSetup enroll a user whose role requires MFA during login
+	StartSetup(ctx context.Context, request request.MFASetupRequest) (*response.MFAEnrollmentResponse, error)
+	CompleteSetup(ctx context.Context, request request.MFASetupConfirmRequest) (*response.MFASetupResponse, error)
+	// SetRoleMFARequired is audited with the ID of the admin making the change
+	SetRoleMFARequired(ctx context.Context, roleName string, required bool, adminID int64) error
+}
@@ -0,0 +1,16 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+)
+
+type OIDCService interface {
+	// StartLogin returns the provider login page URL the user is redirected to
+	StartLogin(ctx context.Context, providerName string) (string, error)
+	// CompleteLogin handles the provider redirect and logs the user in, linking or creating
+	// the account on the first login
+	CompleteLogin(ctx context.Context, providerName string, request request.OIDCCallbackRequest) (*response.AuthResponse, error)
+}
@@ -0,0 +1,15 @@
+package identity
+
+import (
+	"context"
+	"time"
+
+	"github.com/hthinh24/go-store/services/identity/internal/oidc"
+)
+
+type OIDCStateRepository interface {
+	SaveState(ctx context.Context, key string, state *oidc.State, ttl time.Duration) error
+	// TakeState returns and deletes the state in one step, so a callback can only complete a
+	// login once. Unknown or expired keys fail with ErrInvalidOIDCState.
+	TakeState(ctx context.Context, key string) (*oidc.State, error)
+}
@@ -0,0 +1,16 @@
+package identity
+
+import (
+	"time"
+
+	"github.com/hthinh24/go-store/services/identity/internal/entity"
+)
+
+type OutboxRepository interface {
+	// ClaimDueEvents takes up to limit due events and moves them lease into the future, so
+	// other relays skip them while they are delivered
+	ClaimDueEvents(limit int, lease time.Duration) (*[]entity.OutboxEvent, error)
+	MarkEventProcessed(id int64) error
+	// MarkEventFailed schedules the next delivery attempt of the event
+	MarkEventFailed(id int64, nextAttemptAt time.Time, lastError string) error
+}
@@ -0,0 +1,15 @@
+package identity
+
+import (
+	"context"
+	"time"
+)
+
+// PermissionCacheRepository caches the permission names of each role, access tokens are
+// issued from it on every login and refresh
+type PermissionCacheRepository interface {
+	// GetRolePermissions reports false when the role is not cached
+	GetRolePermissions(ctx context.Context, roleID int64) ([]string, bool, error)
+	SetRolePermissions(ctx context.Context, roleID int64, permissions []string, ttl time.Duration) error
+	InvalidateRoles(ctx context.Context, roleIDs ...int64) error
+}
@@ -0,0 +1,13 @@
+package identity
+
+import "github.com/hthinh24/go-store/services/identity/internal/entity"
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(token *entity.RefreshToken) error
+	FindRefreshTokenByHash(tokenHash string) (*entity.RefreshToken, error)
+	// RotateRefreshToken revokes current and stores next atomically, it fails with
+	// ErrRefreshTokenReused when current was revoked concurrently
+	RotateRefreshToken(current *entity.RefreshToken, next *entity.RefreshToken) error
+	RevokeRefreshTokenFamily(familyID string) error
+	RevokeAllUserRefreshTokens(userID int64) error
+}
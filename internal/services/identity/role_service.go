@@ -0,0 +1,31 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
+	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
+)
+
+// RoleService manages roles, permissions and role grants, every change is audited with the
+// ID of the admin making it. Changes apply to access tokens from their next refresh.
+type RoleService interface {
+	GetRoles(ctx context.Context) (*[]response.RoleResponse, error)
+	GetRole(ctx context.Context, name string) (*response.RoleResponse, error)
+	CreateRole(ctx context.Context, data request.CreateRoleRequest, adminID int64) (*response.RoleResponse, error)
+	UpdateRole(ctx context.Context, name string, data request.UpdateRoleRequest, adminID int64) (*response.RoleResponse, error)
+	// DeleteRole refuses the built in roles
+	DeleteRole(ctx context.Context, name string, adminID int64) error
+	AddPermissionToRole(ctx context.Context, roleName string, data request.RolePermissionRequest, adminID int64) (*response.RoleResponse, error)
+	RemovePermissionFromRole(ctx context.Context, roleName string, permissionName string, adminID int64) (*response.RoleResponse, error)
+
+	GetPermissions(ctx context.Context) (*[]response.PermissionResponse, error)
+	CreatePermission(ctx context.Context, data request.CreatePermissionRequest, adminID int64) (*response.PermissionResponse, error)
+	UpdatePermission(ctx context.Context, name string, data request.UpdatePermissionRequest, adminID int64) (*response.PermissionResponse, error)
+	DeletePermission(ctx context.Context, name string, adminID int64) error
+
+	GetUserRoles(ctx context.Context, userID int64) (*[]response.RoleResponse, error)
+	AssignRoleToUser(ctx context.Context, userID int64, data request.UserRoleRequest, adminID int64) (*[]response.RoleResponse, error)
+	// RevokeRoleFromUser refuses to take the admin role from the admin making the change
+	RevokeRoleFromUser(ctx context.Context, userID int64, roleName string, adminID int64) (*[]response.RoleResponse, error)
+}
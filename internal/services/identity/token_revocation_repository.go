@@ -0,0 +1,17 @@
+package identity
+
+import (
+	"context"
+	"time"
+)
+
+type TokenRevocationRepository interface {
+	// RevokeToken denylists an access token ID until the token would expire anyway
+	RevokeToken(ctx context.Context, tokenID string, ttl time.Duration) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	// GetTokenGeneration returns the generation access tokens of the user must carry at least
+	GetTokenGeneration(ctx context.Context, userID int64) (int64, error)
+	// IncrementTokenGeneration invalidates every access token issued to the user so far, the
+	// generation never expires so it cannot fall back below the one of tokens still valid
+	IncrementTokenGeneration(ctx context.Context, userID int64) (int64, error)
+}
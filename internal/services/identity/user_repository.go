@@ -1,15 +1,50 @@
 package identity
 
 import (
+	"time"
+
 	"github.com/hthinh24/go-store/services/identity/internal/entity"
 )
 
 type UserRepository interface {
 	FindUserByID(id int64) (*entity.User, error)
 	FindUserByEmail(email string) (*entity.User, error)
-	FindUsers() (*[]entity.User, error)
+	FindUserByProvider(providerName string, providerID string) (*entity.User, error)
+	// FindUsers returns a page of the users matching the filter and how many match in total
+	FindUsers(filter UserFilter) (*[]entity.User, int64, error)
 	CreateUser(user *entity.User) error
+	// RegisterUser creates the user with its role and records the UserRegistered outbox
+	// event, all in one transaction
+	RegisterUser(user *entity.User, roleID int64) error
 	UpdateUserProfile(user *entity.User) error
 	UpdateUserPassword(user *entity.User) error
+	UpdateUserProvider(user *entity.User) error
+	UpdateUserStatus(user *entity.User) error
+	UpdateUserEmailVerified(user *entity.User) error
+	// UpdateUserEmail stores a confirmed new email with its verification time
+	UpdateUserEmail(user *entity.User) error
 	DeleteUser(id int64) error
 }
+
+// UserFilter selects and orders users, zero fields do not filter
+type UserFilter struct {
+	Status      string
+	Role        string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Search matches a substring of the email, first name or last name
+	Search string
+	// SortBy must be a whitelisted column, ties are broken by ID
+	SortBy   string
+	SortDesc bool
+	Limit    int
+	Offset   int
+	// After continues the listing behind a row of the previous page, Offset is ignored
+	After *UserCursor
+}
+
+// UserCursor is the position of a row in the sort order, Value is the SortBy column of the row
+type UserCursor struct {
+	Value string
+	ID    int64
+}
@@ -1,16 +1,19 @@
 package identity
 
 import (
+	"context"
+
+	"github.com/hthinh24/go-store/internal/pkg/rest"
 	"github.com/hthinh24/go-store/services/identity/internal/dto/request"
 	"github.com/hthinh24/go-store/services/identity/internal/dto/response"
 )
 
 type UserService interface {
 	GetUserByID(id int64) (*response.UserResponse, error)
-	GetUsers() (*[]response.UserResponse, error)
-	CreateUser(data *request.CreateUserRequest) (*response.UserResponse, error)
-	UpdateUserProfile(id int64, data *request.UpdateUserProfileRequest) (*response.UserResponse, error)
+	GetUsers(ctx context.Context, data request.ListUsersRequest) (*rest.PageResponse, error)
+	CreateUser(ctx context.Context, data *request.CreateUserRequest) (*response.UserResponse, error)
+	// UpdateUserProfile does not change the email, a new email is mailed a confirmation link
+	UpdateUserProfile(ctx context.Context, id int64, data *request.UpdateUserProfileRequest) (*response.UserResponse, error)
 	UpdateUserPassword(id int64, data *request.UpdateUserPasswordRequest) (*response.UserResponse, error)
-	UpdateToMerchantAccount(userID int64) error
 	DeleteUser(id int64) error
 }
@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
+	"github.com/hthinh24/go-store/internal/pkg/health"
+	"github.com/hthinh24/go-store/internal/pkg/metrics"
 	"github.com/hthinh24/go-store/internal/pkg/middleware/auth"
+	"github.com/hthinh24/go-store/internal/pkg/middleware/requestid"
+	"github.com/hthinh24/go-store/internal/pkg/middleware/serviceauth"
+	"github.com/hthinh24/go-store/internal/pkg/tracing"
 	"github.com/hthinh24/go-store/services/product/internal/config"
 	"github.com/hthinh24/go-store/services/product/internal/controller"
 	repository "github.com/hthinh24/go-store/services/product/internal/infra/repository/postgres"
 	"github.com/hthinh24/go-store/services/product/internal/service"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	gormTracing "gorm.io/plugin/opentelemetry/tracing"
 	"log"
 	"time"
 
@@ -30,6 +38,16 @@ func main() {
 	appLogger.Info("Starting Product Service...")
 	appLogger.Info("Environment: %s", cfg.GetEnvironment())
 
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(cfg.ServiceName, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Initialize metrics
+	appMetrics := metrics.New(cfg.ServiceName)
+
 	// Init Redis
 	client := redis.NewClient(&redis.Options{
 		Addr:     cfg.GetRedisAddress(),
@@ -41,15 +59,25 @@ func main() {
 		ReadTimeout:  time.Duration(cfg.Redis.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(cfg.Redis.WriteTimeout) * time.Second,
 	})
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		log.Fatalf("Failed to instrument redis: %v", err)
+	}
 
 	// Initialize database connection
-	db, err := initDatabase(cfg)
+	db, err := initDatabase(cfg, appMetrics)
 	if err != nil {
 		appLogger.Error("Failed to connect to database: %v", err)
 		log.Fatal(err)
 	}
 	appLogger.Info("Database connected successfully")
 
+	// Initialize health checks
+	appHealth, err := initHealth(cfg, db, client)
+	if err != nil {
+		appLogger.Error("Failed to initialize health checks: %v", err)
+		log.Fatal(err)
+	}
+
 	// Initialize repositories
 	productRepository := repository.NewProductRepository(
 		customLog.WithComponent(cfg.GetLogLevel(), "PRODUCT-REPOSITORY"),
@@ -59,6 +87,7 @@ func main() {
 	productService := service.NewProductService(
 		customLog.WithComponent(cfg.GetLogLevel(), "PRODUCT-SERVICE"),
 		client,
+		appMetrics,
 		productRepository)
 
 	// Initialize controllers
@@ -66,8 +95,15 @@ func main() {
 		customLog.WithComponent(cfg.GetLogLevel(), "PRODUCT-CONTROLLER"),
 		productService)
 
+	// Initialize middleware, internal routes only serve signed requests of other services
+	serviceAuthMiddleware, err := serviceauth.NewServiceAuthMiddleware(customLog.WithComponent(cfg.GetLogLevel(), "SERVICE-AUTH-MIDDLEWARE"), cfg.ServiceAuth)
+	if err != nil {
+		appLogger.Error("Failed to initialize service auth: %v", err)
+		log.Fatal(err)
+	}
+
 	// Setup router
-	router := setupRouter(productController, cfg)
+	router := setupRouter(productController, serviceAuthMiddleware, cfg, appMetrics, appHealth)
 
 	// Start server
 	serverAddr := cfg.GetServerAddress()
@@ -78,12 +114,17 @@ func main() {
 	}
 }
 
-func initDatabase(cfg *config.AppConfig) (*gorm.DB, error) {
+func initDatabase(cfg *config.AppConfig, appMetrics *metrics.Metrics) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(cfg.GetDatabaseURL()), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
 
+	// Trace every query, spans are children of the request span when the context is passed
+	if err := db.Use(gormTracing.NewPlugin(gormTracing.WithoutMetrics())); err != nil {
+		return nil, err
+	}
+
 	// Configure connection pool settings
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -95,18 +136,42 @@ func initDatabase(cfg *config.AppConfig) (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(cfg.PG.MaxIdleConns)
 	sqlDB.SetConnMaxLifetime(time.Duration(cfg.PG.ConnMaxLifetime) * time.Second)
 	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.PG.ConnMaxIdleTime) * time.Second)
+	appMetrics.RegisterDB(cfg.PG.Database, sqlDB)
 
 	return db, nil
 }
 
-func setupRouter(productController *controller.ProductController, cfg *config.AppConfig) *gin.Engine {
+func initHealth(cfg *config.AppConfig, db *gorm.DB, redisClient *redis.Client) (*health.Health, error) {
+	appHealth := health.New(cfg.Health)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	appHealth.AddCheck("postgres", health.DBCheck(sqlDB))
+	appHealth.AddCheck("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+
+	if err := appHealth.AddServiceChecks(cfg.Services); err != nil {
+		return nil, err
+	}
+
+	return appHealth, nil
+}
+
+func setupRouter(productController *controller.ProductController, serviceAuthMiddleware *serviceauth.ServiceAuthMiddleware, cfg *config.AppConfig, appMetrics *metrics.Metrics, appHealth *health.Health) *gin.Engine {
 	router := gin.Default()
+	router.Use(requestid.RequestID())
+	router.Use(tracing.Middleware(cfg.ServiceName))
+	router.Use(appMetrics.Middleware())
+
+	appMetrics.Register(router)
 
 	authMiddleware := auth.NewSharedAuthMiddleware(customLog.WithComponent(cfg.GetLogLevel(), "AUTH-MIDDLEWARE"))
 
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "healthy"})
-	})
+	// Liveness and readiness probes
+	appHealth.Register(router)
 
 	api := router.Group("/api")
 	{
@@ -116,7 +181,9 @@ func setupRouter(productController *controller.ProductController, cfg *config.Ap
 			// Public routes
 			products.GET("/:id", productController.GetProductByID())
 			products.GET("/:id/detail", productController.GetProductDetailByID())
-			products.GET("/skus/:id", productController.GetProductSKUByID())
+
+			// Internal routes, the cart service checks prices and stock of SKUs
+			products.GET("/skus/:id", serviceAuthMiddleware.Require("cart"), productController.GetProductSKUByID())
 
 			// Protected routes
 			// TODO - Implement this later
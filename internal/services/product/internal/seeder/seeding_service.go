@@ -205,9 +205,9 @@ func (ss *SeedingService) postProduct(productData *request.CreateProductWithoutS
 }
 
 func (ss *SeedingService) logSeedingResults(result *SeedingResult) {
-	log.Printf("\n" + strings.Repeat("=", 50))
+	log.Print("\n" + strings.Repeat("=", 50))
 	log.Printf("🎯 SEEDING COMPLETED")
-	log.Printf(strings.Repeat("=", 50))
+	log.Print(strings.Repeat("=", 50))
 	log.Printf("📊 Total Requests: %d", result.TotalRequests)
 	log.Printf("✅ Successful Seeds: %d", result.SuccessfulSeeds)
 	log.Printf("❌ Failed Seeds: %d", result.FailedSeeds)
@@ -225,5 +225,5 @@ func (ss *SeedingService) logSeedingResults(result *SeedingResult) {
 			log.Printf("   ... and %d more errors", len(result.Errors)-5)
 		}
 	}
-	log.Printf(strings.Repeat("=", 50))
+	log.Print(strings.Repeat("=", 50))
 }
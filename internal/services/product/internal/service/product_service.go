@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/hthinh24/go-store/internal/pkg/logger"
+	"github.com/hthinh24/go-store/internal/pkg/metrics"
 	"github.com/hthinh24/go-store/services/product"
 	"github.com/hthinh24/go-store/services/product/internal/constants"
 	"github.com/hthinh24/go-store/services/product/internal/dto/repository"
@@ -17,17 +18,22 @@ import (
 	"time"
 )
 
+// productDetailCache is the cache label of product detail lookups
+const productDetailCache = "product_detail"
+
 type productService struct {
 	logger            logger.Logger
 	redis             *redis.Client
+	metrics           *metrics.Metrics
 	productRepository product.ProductRepository
 }
 
 // NewProductService creates a new instance of ProductService
-func NewProductService(logger logger.Logger, redis *redis.Client, productRepository product.ProductRepository) product.ProductService {
+func NewProductService(logger logger.Logger, redis *redis.Client, metrics *metrics.Metrics, productRepository product.ProductRepository) product.ProductService {
 	return &productService{
 		logger:            logger,
 		redis:             redis,
+		metrics:           metrics,
 		productRepository: productRepository,
 	}
 }
@@ -53,11 +59,14 @@ func (p *productService) GetProductDetailByID(id int64) (*response.ProductDetail
 
 	cachedProductDetail, err := p.redis.Get(context.Background(), fmt.Sprintf(constants.KeyProductDetail, id)).Result()
 	if errors.Is(err, redis.Nil) {
+		p.metrics.CacheMiss(productDetailCache)
 		p.logger.Info("Cache miss for product detail, ID: ", id)
 	} else if err != nil {
 		// Log Redis error but don't fail the request - continue with database
+		p.metrics.CacheError(productDetailCache)
 		p.logger.Warn("Redis error (continuing with DB): ID: %d, Error: %v", id, err)
 	} else {
+		p.metrics.CacheHit(productDetailCache)
 		p.logger.Info("Cache hit for product detail, ID: ", id)
 		// Unmarshal cached data
 		var productDetailResponse response.ProductDetailResponse